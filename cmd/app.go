@@ -3,11 +3,15 @@ package main
 import (
 	"github.com/XiovV/blog-api/config"
 	"github.com/XiovV/blog-api/pkg/mailer"
+	"github.com/XiovV/blog-api/pkg/queue"
 	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/XiovV/blog-api/pkg/spam"
 	"github.com/XiovV/blog-api/server"
 	"github.com/casbin/casbin/v2"
+	"github.com/jmoiron/sqlx"
 	"go.uber.org/zap"
 	"log"
+	"os"
 )
 
 func main() {
@@ -21,14 +25,90 @@ func main() {
 		log.Fatalln(err)
 	}
 
-	db, err := repository.NewPostgres(c.PostgresDSN)
+	if c.DBQueryTimeout <= 0 {
+		logger.Error("database query timeout must be positive")
+		return
+	}
+
+	poolOptions := repository.PoolOptions{
+		MaxOpenConns: c.DBMaxOpenConns,
+		MaxIdleConns: c.DBMaxIdleConns,
+		MaxIdleTime:  c.DBMaxIdleTime,
+		MaxLifetime:  c.DBMaxLifetime,
+	}
+
+	db, err := repository.NewPostgres(c.PostgresDSN, poolOptions)
 	if err != nil {
 		logger.Error("couldn't initialise a database connection", zap.Error(err))
 		return
 	}
 
-	userRepository := repository.NewUserRepository(db)
-	postRepository := repository.NewPostRepository(db)
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		err = runMigrateCommand(logger, db, os.Args[2:])
+		if err != nil {
+			logger.Error("couldn't migrate the database", zap.Error(err))
+		}
+		return
+	}
+
+	if c.MigrateOnStartup {
+		err = migrateUp(logger, db)
+		if err != nil {
+			logger.Error("couldn't migrate the database", zap.Error(err))
+			return
+		}
+	}
+
+	var replica *sqlx.DB
+	if c.PostgresReplicaDSN != "" {
+		if c.ReplicaCheckInterval <= 0 {
+			logger.Error("replica check interval must be positive")
+			return
+		}
+
+		replica, err = repository.NewReplica(c.PostgresReplicaDSN, poolOptions)
+		if err != nil {
+			logger.Error("couldn't initialise a replica connection", zap.Error(err))
+			return
+		}
+	}
+
+	database := repository.NewDB(db, replica, c.DBQueryTimeout)
+
+	integrityRepository := repository.NewIntegrityRepository(database)
+
+	if len(os.Args) > 1 && os.Args[1] == "check-integrity" {
+		err = runCheckIntegrityCommand(logger, integrityRepository, os.Args[2:])
+		if err != nil {
+			logger.Error("couldn't check data integrity", zap.Error(err))
+		}
+		return
+	}
+
+	if c.IntegrityCheckOnStartup {
+		err = checkIntegrity(logger, integrityRepository, false)
+		if err != nil {
+			logger.Error("couldn't check data integrity", zap.Error(err))
+		}
+	}
+
+	userRepository := repository.NewUserRepository(database)
+	postRepository := repository.NewPostRepository(database)
+	organizationRepository := repository.NewOrganizationRepository(database)
+	reportRepository := repository.NewReportRepository(database)
+	notificationRepository := repository.NewNotificationRepository(database)
+	auditLogRepository := repository.NewAuditLogRepository(database)
+	jobRepository := repository.NewJobRepository(database)
+	webhookRepository := repository.NewWebhookRepository(database)
+	settingsRepository := repository.NewSettingsRepository(database)
+	historyRepository := repository.NewHistoryRepository(database)
+	statsRepository := repository.NewStatsRepository(database)
+	txManager := repository.NewTxManager(database)
+
+	notifications := repository.NewNotifications(c.PostgresDSN, func(err error) {
+		logger.Warn("notification listener connection problem", zap.Error(err))
+	})
+	defer notifications.Close()
 
 	enforcer, err := casbin.NewEnforcer("rbac/rbac_model.conf", "rbac/rbac_policy.csv")
 	if err != nil {
@@ -36,17 +116,92 @@ func main() {
 		return
 	}
 
-	mail := mailer.New(c.SMTPHost, c.SMTPPort, c.SMTPUsername, c.SMTPPassword, c.SMTPSender)
+	organizationEnforcer, err := casbin.NewEnforcer("rbac/rbac_model.conf", "rbac/organization_policy.csv")
+	if err != nil {
+		logger.Error("couldn't init organization enforcer", zap.Error(err))
+		return
+	}
+
+	var spamChecker spam.Checker = spam.NewHeuristic(c.SpamMaxLinks, c.SpamBlockedWords)
+	if c.AkismetKey != "" {
+		if c.AkismetBlogURL == "" {
+			logger.Error("akismet blog url is required when an akismet key is set")
+			return
+		}
+
+		spamChecker = spam.WithFallback(spam.NewAkismet(c.AkismetKey, c.AkismetBlogURL, c.SpamCheckTimeout), spamChecker, func(err error) {
+			logger.Warn("akismet is unavailable, falling back to the heuristic spam check", zap.Error(err))
+		})
+	}
+
+	mailSender, err := newMailSender(c)
+	if err != nil {
+		logger.Error("couldn't init mail provider", zap.Error(err))
+		return
+	}
+
+	mail := mailer.New(mailSender, c.SMTPSender)
+
+	jobQueue := queue.New(jobRepository, logger, queue.Options{
+		Workers:      c.QueueWorkers,
+		PollInterval: c.QueuePollInterval,
+		MaxAttempts:  c.QueueMaxAttempts,
+		JobTimeout:   c.QueueJobTimeout,
+		StaleAfter:   c.QueueStaleAfter,
+		Retention:    c.QueueRetention,
+	})
 
 	s := server.Server{
-		Config:         c,
-		UserRepository: userRepository,
-		PostRepository: postRepository,
-		Logger:         logger,
-		CasbinEnforcer: enforcer,
-		Mailer:         mail,
+		Config:                 c,
+		UserRepository:         userRepository,
+		PostRepository:         postRepository,
+		OrganizationRepository: organizationRepository,
+		ReportRepository:       reportRepository,
+		NotificationRepository: notificationRepository,
+		AuditLogRepository:     auditLogRepository,
+		JobRepository:          jobRepository,
+		WebhookRepository:      webhookRepository,
+		SettingsRepository:     settingsRepository,
+		HistoryRepository:      historyRepository,
+		StatsRepository:        statsRepository,
+		TxManager:              txManager,
+		Notifications:          notifications,
+		Logger:                 logger,
+		CasbinEnforcer:         enforcer,
+		OrganizationEnforcer:   organizationEnforcer,
+		Mailer:                 mail,
+		SpamChecker:            spamChecker,
+		Queue:                  jobQueue,
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "bootstrap" {
+		err = runBootstrapCommand(&s, os.Args[2:])
+		if err != nil {
+			logger.Error("couldn't bootstrap", zap.Error(err))
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "seed" {
+		err = runSeedCommand(&s, os.Args[2:])
+		if err != nil {
+			logger.Error("couldn't seed the database", zap.Error(err))
+		}
+		return
 	}
 
+	database.SetQueryObserver(s.ObserveQuery)
+
+	go notifications.Run()
+
+	go database.MonitorReplica(c.ReplicaCheckInterval, func(healthy bool, err error) {
+		if healthy {
+			logger.Info("read replica is available, reads are routed to it")
+		} else {
+			logger.Warn("read replica is unavailable, reads fall back to the primary", zap.Error(err))
+		}
+	})
+
 	if err := s.Run(); err != nil {
 		logger.Error("couldn't start server", zap.Error(err))
 	}
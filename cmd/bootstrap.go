@@ -0,0 +1,23 @@
+package main
+
+import (
+	"flag"
+	"github.com/XiovV/blog-api/server"
+)
+
+// runBootstrapCommand runs the bootstrap subcommand: app bootstrap [-admin-username name -admin-email email -admin-password password]
+// The flags default to the BOOTSTRAP_ADMIN_* environment variables, which should be preferred for the password
+// since flags are visible in the process list.
+func runBootstrapCommand(s *server.Server, args []string) error {
+	flags := flag.NewFlagSet("bootstrap", flag.ExitOnError)
+	flags.StringVar(&s.Config.BootstrapAdminUsername, "admin-username", s.Config.BootstrapAdminUsername, "username of the initial admin")
+	flags.StringVar(&s.Config.BootstrapAdminEmail, "admin-email", s.Config.BootstrapAdminEmail, "email of the initial admin")
+	flags.StringVar(&s.Config.BootstrapAdminPassword, "admin-password", s.Config.BootstrapAdminPassword, "password of the initial admin")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	return s.Bootstrap()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"go.uber.org/zap"
+)
+
+// checkIntegrity reports orphaned rows, which are deleted if fix is set.
+func checkIntegrity(logger *zap.Logger, integrityRepository *repository.IntegrityRepository, fix bool) error {
+	reports, err := integrityRepository.CheckOrphans(context.Background(), fix)
+	if err != nil {
+		return err
+	}
+
+	for _, report := range reports {
+		if report.Orphans == 0 {
+			logger.Info("integrity check passed", zap.String("check", report.Name))
+			continue
+		}
+
+		logger.Warn("integrity check found orphaned rows", zap.String("check", report.Name), zap.Int("orphans", report.Orphans), zap.Bool("fixed", report.Fixed))
+	}
+
+	return nil
+}
+
+// runCheckIntegrityCommand runs the check-integrity subcommand: app check-integrity [-fix]
+func runCheckIntegrityCommand(logger *zap.Logger, integrityRepository *repository.IntegrityRepository, args []string) error {
+	flags := flag.NewFlagSet("check-integrity", flag.ExitOnError)
+	fix := flags.Bool("fix", false, "delete orphaned rows instead of only reporting them")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	return checkIntegrity(logger, integrityRepository, *fix)
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/XiovV/blog-api/config"
+	"github.com/XiovV/blog-api/pkg/mailer"
+	"github.com/XiovV/blog-api/server"
+	"strings"
+)
+
+const (
+	mailCaptureFile = "file"
+	mailCaptureSMTP = "smtp"
+)
+
+// newMailSender returns the sender of the provider in MAIL_PROVIDER, SMTP_TIMEOUT is the timeout of every provider.
+// MAIL_CAPTURE replaces the provider in LOCAL and STAGING.
+func newMailSender(c *config.Config) (mailer.Sender, error) {
+	if c.MailCapture != "" {
+		return newMailCapture(c)
+	}
+
+	switch c.MailProvider {
+	case mailer.ProviderSMTP:
+		if c.SMTPHost == "" || c.SMTPPort == 0 {
+			return nil, errors.New("smtp host and port are required by the smtp provider")
+		}
+
+		dkim, err := newDKIM(c)
+		if err != nil {
+			return nil, err
+		}
+
+		return mailer.NewSMTP(c.SMTPHost, c.SMTPPort, c.SMTPUsername, c.SMTPPassword, c.SMTPTimeout, dkim), nil
+	case mailer.ProviderSendGrid:
+		if c.SendGridAPIKey == "" {
+			return nil, errors.New("sendgrid api key is required by the sendgrid provider")
+		}
+
+		return mailer.NewSendGrid(c.SendGridAPIKey, c.SMTPTimeout), nil
+	case mailer.ProviderMailgun:
+		if c.MailgunDomain == "" || c.MailgunAPIKey == "" {
+			return nil, errors.New("mailgun domain and api key are required by the mailgun provider")
+		}
+
+		return mailer.NewMailgun(c.MailgunDomain, c.MailgunAPIKey, c.MailgunBaseURL, c.SMTPTimeout), nil
+	case mailer.ProviderSES:
+		if c.SESRegion == "" || c.SESAccessKeyID == "" || c.SESSecretAccessKey == "" {
+			return nil, errors.New("ses region, access key id and secret access key are required by the ses provider")
+		}
+
+		return mailer.NewSES(c.SESRegion, c.SESAccessKeyID, c.SESSecretAccessKey, c.SMTPTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown mail provider %q, it must be one of smtp, sendgrid, mailgun or ses", c.MailProvider)
+	}
+}
+
+// newMailCapture returns a sender keeping emails away from real inboxes, by writing them to MAIL_CAPTURE_DIR or sending
+// them to a local SMTP server like MailHog or smtp4dev, which doesn't need credentials.
+func newMailCapture(c *config.Config) (mailer.Sender, error) {
+	if c.Environment != server.LOCAL_ENV && c.Environment != server.STAGING_ENV {
+		return nil, errors.New("mail capture can only be enabled in LOCAL and STAGING")
+	}
+
+	switch c.MailCapture {
+	case mailCaptureFile:
+		return mailer.NewFile(c.MailCaptureDir)
+	case mailCaptureSMTP:
+		return mailer.NewSMTP(c.MailCaptureSMTPHost, c.MailCaptureSMTPPort, "", "", c.SMTPTimeout, nil), nil
+	default:
+		return nil, fmt.Errorf("unknown mail capture %q, it must be file or smtp", c.MailCapture)
+	}
+}
+
+// newDKIM returns the DKIM signer of the SMTP provider, or nil if DKIM_PRIVATE_KEY isn't set. The other providers sign
+// messages themselves once the domain is verified with them.
+func newDKIM(c *config.Config) (*mailer.DKIM, error) {
+	if c.DKIMPrivateKey == "" {
+		return nil, nil
+	}
+
+	if c.DKIMDomain == "" || c.DKIMSelector == "" {
+		return nil, errors.New("dkim domain and selector are required when a dkim private key is set")
+	}
+
+	// env files can't hold line breaks, so they may be escaped
+	privateKey := strings.ReplaceAll(c.DKIMPrivateKey, `\n`, "\n")
+
+	return mailer.NewDKIM(c.DKIMDomain, c.DKIMSelector, []byte(privateKey))
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/jmoiron/sqlx"
+	"go.uber.org/zap"
+	"strconv"
+)
+
+// migrateLogger forwards migrate's progress messages to zap.
+type migrateLogger struct {
+	logger *zap.Logger
+}
+
+func (l migrateLogger) Printf(format string, v ...interface{}) {
+	l.logger.Info(fmt.Sprintf(format, v...))
+}
+
+func (l migrateLogger) Verbose() bool {
+	return false
+}
+
+// migrateUp applies every migration which hasn't been applied yet.
+func migrateUp(logger *zap.Logger, db *sqlx.DB) error {
+	m, err := repository.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	m.Log = migrateLogger{logger}
+
+	err = m.Up()
+	if errors.Is(err, migrate.ErrNoChange) {
+		logger.Info("database schema is up to date")
+		return nil
+	}
+
+	return migrationError(err)
+}
+
+// runMigrateCommand runs the migrate subcommand: app migrate up | down <steps> | goto <version> | force <version> | version
+func runMigrateCommand(logger *zap.Logger, db *sqlx.DB, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: app migrate up | down <steps> | goto <version> | force <version> | version")
+	}
+
+	if args[0] == "up" {
+		return migrateUp(logger, db)
+	}
+
+	m, err := repository.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	defer m.Close()
+
+	m.Log = migrateLogger{logger}
+
+	switch args[0] {
+	case "version":
+		version, dirty, err := m.Version()
+		if errors.Is(err, migrate.ErrNilVersion) {
+			logger.Info("no migrations have been applied")
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		logger.Info("database schema version", zap.Uint("version", version), zap.Bool("dirty", dirty))
+		return nil
+
+	case "down", "goto", "force":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: app migrate %s <n>", args[0])
+		}
+
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s expects a non-negative number, got %q", args[0], args[1])
+		}
+
+		switch args[0] {
+		case "down":
+			// rolling back every migration drops all data, so the number of steps is always required
+			err = m.Steps(-n)
+		case "goto":
+			err = m.Migrate(uint(n))
+		case "force":
+			err = m.Force(n)
+		}
+
+		if errors.Is(err, migrate.ErrNoChange) {
+			logger.Info("database schema is already at the requested version")
+			return nil
+		}
+
+		return migrationError(err)
+	}
+
+	return fmt.Errorf("unknown migrate command %q", args[0])
+}
+
+// migrationError explains how to recover from a migration which failed halfway through.
+func migrationError(err error) error {
+	var dirty migrate.ErrDirty
+	if errors.As(err, &dirty) {
+		return fmt.Errorf("migration %d failed halfway through, fix the schema manually and run 'app migrate force <version>': %w", dirty.Version, err)
+	}
+
+	return err
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"github.com/XiovV/blog-api/server"
+)
+
+// runSeedCommand runs the seed subcommand: app seed [-users n -posts n -password password -random-seed n] plus the flags of bootstrap.
+func runSeedCommand(s *server.Server, args []string) error {
+	var options server.SeedOptions
+
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	flags.StringVar(&s.Config.BootstrapAdminUsername, "admin-username", s.Config.BootstrapAdminUsername, "username of the initial admin")
+	flags.StringVar(&s.Config.BootstrapAdminEmail, "admin-email", s.Config.BootstrapAdminEmail, "email of the initial admin")
+	flags.StringVar(&s.Config.BootstrapAdminPassword, "admin-password", s.Config.BootstrapAdminPassword, "password of the initial admin")
+	flags.IntVar(&options.Users, "users", 0, "number of demo users to create")
+	flags.IntVar(&options.PostsPerUser, "posts", 0, "number of posts to create for every demo user")
+	flags.StringVar(&options.Password, "password", "demo-password", "password of the demo users")
+	flags.Int64Var(&options.RandomSeed, "random-seed", 1, "seed of the generated posts")
+
+	err := flags.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	return s.Seed(options)
+}
@@ -2,18 +2,97 @@ package config
 
 import (
 	"github.com/ilyakaznacheev/cleanenv"
+	"time"
 )
 
 type Config struct {
-	PostgresDSN  string `env:"POSTGRES_DSN" env-required:"true"`
-	Port         string `env:"PORT" env-default:"8080"`
-	Environment  string `env:"ENV" env-default:"PRODUCTION"`
-	AESKey       string `env:"AES_KEY" env-required:"true"`
-	SMTPHost     string `env:"SMTP_HOST" env-required:"true"`
-	SMTPPort     int    `env:"SMTP_PORT" env-required:"true"`
-	SMTPUsername string `env:"SMTP_USERNAME" env-required:"true"`
-	SMTPPassword string `env:"SMTP_PASSWORD" env-required:"true"`
-	SMTPSender   string `env:"SMTP_SENDER" env-required:"true"`
+	PostgresDSN                string        `env:"POSTGRES_DSN" env-required:"true"`
+	PostgresReplicaDSN         string        `env:"POSTGRES_REPLICA_DSN"`
+	ReplicaCheckInterval       time.Duration `env:"REPLICA_CHECK_INTERVAL" env-default:"10s"`
+	DBMaxOpenConns             int           `env:"DB_MAX_OPEN_CONNS" env-default:"25"`
+	DBMaxIdleConns             int           `env:"DB_MAX_IDLE_CONNS" env-default:"25"`
+	DBMaxIdleTime              time.Duration `env:"DB_MAX_IDLE_TIME" env-default:"15m"`
+	DBMaxLifetime              time.Duration `env:"DB_MAX_LIFETIME" env-default:"0"`
+	DBQueryTimeout             time.Duration `env:"DB_QUERY_TIMEOUT" env-default:"5s"`
+	Port                       string        `env:"PORT" env-default:"8080"`
+	Environment                string        `env:"ENV" env-default:"PRODUCTION"`
+	ExternalURL                string        `env:"EXTERNAL_URL"`
+	SigningKey                 string        `env:"SIGNING_KEY" env-required:"true"`
+	PreviousSigningKeys        []string      `env:"PREVIOUS_SIGNING_KEYS" env-separator:","`
+	MinClaimsVersion           int           `env:"MIN_CLAIMS_VERSION" env-default:"0"`
+	AESKey                     string        `env:"AES_KEY" env-required:"true"`
+	AESKeyID                   int           `env:"AES_KEY_ID" env-default:"1"`
+	AESPreviousKeys            []string      `env:"AES_PREVIOUS_KEYS" env-separator:","`
+	Argon2Memory               uint32        `env:"ARGON2_MEMORY" env-default:"131072"`
+	Argon2Iterations           uint32        `env:"ARGON2_ITERATIONS" env-default:"10"`
+	Argon2Parallelism          uint8         `env:"ARGON2_PARALLELISM" env-default:"4"`
+	Argon2SaltLength           uint32        `env:"ARGON2_SALT_LENGTH" env-default:"16"`
+	Argon2KeyLength            uint32        `env:"ARGON2_KEY_LENGTH" env-default:"32"`
+	BootstrapAdminUsername     string        `env:"BOOTSTRAP_ADMIN_USERNAME"`
+	BootstrapAdminEmail        string        `env:"BOOTSTRAP_ADMIN_EMAIL"`
+	BootstrapAdminPassword     string        `env:"BOOTSTRAP_ADMIN_PASSWORD"`
+	DefaultRole                string        `env:"DEFAULT_ROLE" env-default:"user"`
+	PromotionRules             []string      `env:"PROMOTION_RULES" env-separator:","`
+	PromotionInterval          time.Duration `env:"PROMOTION_INTERVAL" env-default:"1h"`
+	TokenCleanupInterval       time.Duration `env:"TOKEN_CLEANUP_INTERVAL" env-default:"1h"`
+	VerificationReminderAfter  time.Duration `env:"VERIFICATION_REMINDER_AFTER" env-default:"72h"`
+	UnverifiedAccountRetention time.Duration `env:"UNVERIFIED_ACCOUNT_RETENTION" env-default:"0"`
+	UnverifiedAccountInterval  time.Duration `env:"UNVERIFIED_ACCOUNT_INTERVAL" env-default:"1h"`
+	LoginAttemptRetention      time.Duration `env:"LOGIN_ATTEMPT_RETENTION" env-default:"8760h"`
+	MigrateOnStartup           bool          `env:"MIGRATE_ON_STARTUP" env-default:"true"`
+	IntegrityCheckOnStartup    bool          `env:"INTEGRITY_CHECK_ON_STARTUP" env-default:"false"`
+	DefaultLatencyBudget       time.Duration `env:"DEFAULT_LATENCY_BUDGET" env-default:"1s"`
+	LatencyBudgets             []string      `env:"LATENCY_BUDGETS" env-separator:","`
+	SlowQueryThreshold         time.Duration `env:"SLOW_QUERY_THRESHOLD" env-default:"200ms"`
+	MetricsEnabled             bool          `env:"METRICS_ENABLED" env-default:"false"`
+	CompressionMinSize         int           `env:"COMPRESSION_MIN_SIZE" env-default:"1024"`
+	BodyLogging                bool          `env:"BODY_LOGGING" env-default:"false"`
+	BodyLoggingMaxSize         int           `env:"BODY_LOGGING_MAX_SIZE" env-default:"8192"`
+	RequireVerifiedEmail       bool          `env:"REQUIRE_VERIFIED_EMAIL" env-default:"false"`
+	WidgetCacheMaxAge          time.Duration `env:"WIDGET_CACHE_MAX_AGE" env-default:"5m"`
+	QueueWorkers               int           `env:"QUEUE_WORKERS" env-default:"2"`
+	QueuePollInterval          time.Duration `env:"QUEUE_POLL_INTERVAL" env-default:"1s"`
+	QueueMaxAttempts           int           `env:"QUEUE_MAX_ATTEMPTS" env-default:"5"`
+	QueueJobTimeout            time.Duration `env:"QUEUE_JOB_TIMEOUT" env-default:"5m"`
+	QueueStaleAfter            time.Duration `env:"QUEUE_STALE_AFTER" env-default:"10m"`
+	QueueRetention             time.Duration `env:"QUEUE_RETENTION" env-default:"168h"`
+	WebhookTimeout             time.Duration `env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+	OperatorAlertURL           string        `env:"OPERATOR_ALERT_URL"`
+	AlertCheckInterval         time.Duration `env:"ALERT_CHECK_INTERVAL" env-default:"1m"`
+	AlertRepeatInterval        time.Duration `env:"ALERT_REPEAT_INTERVAL" env-default:"1h"`
+	AlertQueueBacklog          int           `env:"ALERT_QUEUE_BACKLOG" env-default:"1000"`
+	MaintenanceRefreshInterval time.Duration `env:"MAINTENANCE_REFRESH_INTERVAL" env-default:"5s"`
+	AkismetKey                 string        `env:"AKISMET_KEY"`
+	AkismetBlogURL             string        `env:"AKISMET_BLOG_URL"`
+	SpamCheckTimeout           time.Duration `env:"SPAM_CHECK_TIMEOUT" env-default:"5s"`
+	SpamMaxLinks               int           `env:"SPAM_MAX_LINKS" env-default:"10"`
+	SpamBlockedWords           []string      `env:"SPAM_BLOCKED_WORDS" env-separator:","`
+	MentionEmails              bool          `env:"MENTION_EMAILS" env-default:"true"`
+	WelcomeEmails              bool          `env:"WELCOME_EMAILS" env-default:"true"`
+	EmailRateLimit             int           `env:"EMAIL_RATE_LIMIT" env-default:"3"`
+	EmailRateLimitWindow       time.Duration `env:"EMAIL_RATE_LIMIT_WINDOW" env-default:"1h"`
+	MailProvider               string        `env:"MAIL_PROVIDER" env-default:"smtp"`
+	SMTPHost                   string        `env:"SMTP_HOST"`
+	SMTPPort                   int           `env:"SMTP_PORT"`
+	SMTPUsername               string        `env:"SMTP_USERNAME"`
+	SMTPPassword               string        `env:"SMTP_PASSWORD"`
+	SMTPTimeout                time.Duration `env:"SMTP_TIMEOUT" env-default:"10s"`
+	SMTPSender                 string        `env:"SMTP_SENDER" env-required:"true"`
+	DKIMDomain                 string        `env:"DKIM_DOMAIN"`
+	DKIMSelector               string        `env:"DKIM_SELECTOR"`
+	DKIMPrivateKey             string        `env:"DKIM_PRIVATE_KEY"`
+	SendGridAPIKey             string        `env:"SENDGRID_API_KEY"`
+	MailgunDomain              string        `env:"MAILGUN_DOMAIN"`
+	MailgunAPIKey              string        `env:"MAILGUN_API_KEY"`
+	MailgunBaseURL             string        `env:"MAILGUN_BASE_URL" env-default:"https://api.mailgun.net"`
+	SESRegion                  string        `env:"SES_REGION"`
+	SESAccessKeyID             string        `env:"SES_ACCESS_KEY_ID"`
+	SESSecretAccessKey         string        `env:"SES_SECRET_ACCESS_KEY"`
+	MailWebhookToken           string        `env:"MAIL_WEBHOOK_TOKEN"`
+	MailCapture                string        `env:"MAIL_CAPTURE"`
+	MailCaptureDir             string        `env:"MAIL_CAPTURE_DIR" env-default:"mail"`
+	MailCaptureSMTPHost        string        `env:"MAIL_CAPTURE_SMTP_HOST" env-default:"localhost"`
+	MailCaptureSMTPPort        int           `env:"MAIL_CAPTURE_SMTP_PORT" env-default:"1025"`
 }
 
 func New() (*Config, error) {
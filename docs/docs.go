@@ -16,13 +16,14 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/posts/": {
-            "post": {
+        "/admin/appearance": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "accent_color must be a hex color such as #1a2b3c, empty values reset a setting.",
                 "consumes": [
                     "application/json"
                 ],
@@ -30,17 +31,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "post"
+                    "admin"
                 ],
-                "summary": "Creates a post",
+                "summary": "Replaces the appearance settings.",
                 "parameters": [
                     {
-                        "description": "Create post body",
+                        "description": "Update appearance body",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/server.createPostRequest"
+                            "$ref": "#/definitions/server.updateAppearanceRequest"
                         }
                     }
                 ],
@@ -48,7 +49,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.createPostResponse"
+                            "$ref": "#/definitions/server.appearanceResponse"
                         }
                     },
                     "400": {
@@ -58,7 +59,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "The access token is invalid",
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -72,7 +73,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/posts/user/{username}": {
+        "/admin/audit": {
             "get": {
                 "security": [
                     {
@@ -86,17 +87,10 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "post"
+                    "admin"
                 ],
-                "summary": "Gets a post",
+                "summary": "Returns the audit log of privileged actions, newest first.",
                 "parameters": [
-                    {
-                        "type": "string",
-                        "description": "username",
-                        "name": "username",
-                        "in": "path",
-                        "required": true
-                    },
                     {
                         "type": "integer",
                         "description": "page",
@@ -110,13 +104,37 @@ const docTemplate = `{
                         "name": "limit",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only return actions performed by this user",
+                        "name": "actor_id",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return actions of this type, e.g. user.delete",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return actions performed on this type of object, e.g. post",
+                        "name": "target_type",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "only return actions performed on the object with this id",
+                        "name": "target_id",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.getPersonalPostsResponse"
+                            "$ref": "#/definitions/server.listResponse-server_auditLogEntryResponse"
                         }
                     },
                     "400": {
@@ -131,12 +149,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.errorResponse"
                         }
                     },
-                    "404": {
-                        "description": "User has no posts",
-                        "schema": {
-                            "$ref": "#/definitions/server.errorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -146,7 +158,7 @@ const docTemplate = `{
                 }
             }
         },
-        "/posts/{postId}": {
+        "/admin/history/{recordType}/{recordId}": {
             "get": {
                 "security": [
                     {
@@ -160,23 +172,44 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "post"
+                    "admin"
                 ],
-                "summary": "Gets a post",
+                "summary": "Returns every recorded change of a user or post, newest first.",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user or post",
+                        "name": "recordType",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
-                        "description": "post id",
-                        "name": "postId",
+                        "description": "record id",
+                        "name": "recordId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.getPostResponse"
+                            "$ref": "#/definitions/server.listResponse-server_recordChangeResponse"
                         }
                     },
                     "400": {
@@ -186,13 +219,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "The access token is invalid",
-                        "schema": {
-                            "$ref": "#/definitions/server.errorResponse"
-                        }
-                    },
-                    "404": {
-                        "description": "A post with the provided id doesn't exist",
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -204,8 +231,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/admin/history/{recordType}/{recordId}/as-of": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -218,32 +247,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "post"
+                    "admin"
                 ],
-                "summary": "Edits a post",
+                "summary": "Returns a user or post as it was at the provided time, along with who made the last change before it.",
                 "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user or post",
+                        "name": "recordType",
+                        "in": "path",
+                        "required": true
+                    },
                     {
                         "type": "integer",
-                        "description": "post id",
-                        "name": "postId",
+                        "description": "record id",
+                        "name": "recordId",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Edit post body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/server.updatePostRequest"
-                        }
+                        "type": "string",
+                        "description": "RFC 3339 timestamp, e.g. 2022-10-01T12:00:00Z",
+                        "name": "at",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.updatePostResponse"
+                            "$ref": "#/definitions/server.recordChangeResponse"
                         }
                     },
                     "400": {
@@ -259,7 +293,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "User has no posts",
+                        "description": "There is no history of the record at the provided time",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -271,8 +305,10 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/admin/jobs": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -285,21 +321,37 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "post"
+                    "admin"
                 ],
-                "summary": "Deletes a post",
+                "summary": "Returns the jobs in the background job queue, most recently updated first, along with the amount of jobs in each state.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "post id",
-                        "name": "postId",
-                        "in": "path",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return jobs in this state: pending, running, completed or dead",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Post deleted successfully"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_jobResponse"
+                        }
                     },
                     "400": {
                         "description": "Input is invalid",
@@ -313,12 +365,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.errorResponse"
                         }
                     },
-                    "404": {
-                        "description": "A post with the provided id doesn't exist",
-                        "schema": {
-                            "$ref": "#/definitions/server.errorResponse"
-                        }
-                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -328,9 +374,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/login": {
+        "/admin/jobs/{jobId}/retry": {
             "post": {
-                "description": "If the user has 2FA enabled, 302 Found will be returned, in which case POST /users/login/mfa should be used to log the user in.",
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -338,32 +388,39 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Checks if the login credentials are correct and returns the access and refresh tokens.",
+                "summary": "Moves a dead job back into the queue with a fresh set of attempts.",
                 "parameters": [
                     {
-                        "description": "Login user body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/server.loginRequest"
-                        }
+                        "type": "integer",
+                        "description": "job id",
+                        "name": "jobId",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.tokenPair"
+                            "$ref": "#/definitions/server.jobResponse"
                         }
                     },
-                    "302": {
-                        "description": "User has 2FA enabled and needs to call POST /users/login/mfa."
-                    },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "There is no dead job with the provided id",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -377,9 +434,13 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/login/mfa": {
-            "post": {
-                "description": "Checks if the login credentials and totp code are correct and returns the access and refresh tokens. A 400 Bad Request status code and error message will be returned if the user doesn't have 2FA enabled, so only use this if the user has 2FA enabled on their account.",
+        "/admin/maintenance": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -387,29 +448,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
-                ],
-                "summary": "Checks if the login credentials and totp code are correct and returns the access and refresh tokens.",
-                "parameters": [
-                    {
-                        "description": "Login user body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/server.mfaLoginRequest"
-                        }
-                    }
+                    "admin"
                 ],
+                "summary": "Returns the maintenance mode settings.",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.tokenPair"
+                            "$ref": "#/definitions/server.maintenanceResponse"
                         }
                     },
-                    "400": {
-                        "description": "Input is either invalid, or user doesn't have 2FA enabled.",
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -421,10 +471,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/users/login/recovery": {
-            "post": {
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "While it's on, every route except the admin and login routes answers with 503 and a Retry-After header\nof retry_after seconds. If allow_reads is set, GET requests are still served.",
                 "consumes": [
                     "application/json"
                 ],
@@ -432,17 +486,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Checks if the login credentials and recovery code are correct and returns the access and refresh tokens.",
+                "summary": "Turns maintenance mode on or off.",
                 "parameters": [
                     {
-                        "description": "Login user body",
+                        "description": "Update maintenance body",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/server.recoveryLoginRequest"
+                            "$ref": "#/definitions/server.updateMaintenanceRequest"
                         }
                     }
                 ],
@@ -450,11 +504,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.tokenPair"
+                            "$ref": "#/definitions/server.maintenanceResponse"
                         }
                     },
                     "400": {
-                        "description": "Input is either invalid, or the provided recovery code is incorrect.",
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -468,13 +528,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/mfa": {
+        "/admin/mfa/reencrypt": {
             "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "The job runs in the background job queue, once it's completed the previous keys can be removed from AES_PREVIOUS_KEYS.",
                 "consumes": [
                     "application/json"
                 ],
@@ -482,18 +543,18 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Returns the secret used for generating TOTP codes.",
+                "summary": "Re-encrypts every MFA secret with the current AES key.",
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "202": {
+                        "description": "Accepted",
                         "schema": {
-                            "$ref": "#/definitions/server.setupMfaHandlerResponse"
+                            "$ref": "#/definitions/server.messageResponse"
                         }
                     },
                     "403": {
-                        "description": "The access token is invalid",
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -507,13 +568,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/mfa/confirm": {
-            "post": {
+        "/admin/posts/held": {
+            "get": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "Held posts are published with POST /admin/posts/{postId}/approve and rejected by deleting them.",
                 "consumes": [
                     "application/json"
                 ],
@@ -521,25 +583,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Checks if the provided TOTP code is correct and returns an array of recovery codes.",
+                "summary": "Returns the posts held for moderation, oldest first.",
                 "parameters": [
                     {
-                        "description": "Login user body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/server.confirmMfaRequest"
-                        }
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.confirmMfaResponse"
+                            "$ref": "#/definitions/server.listResponse-server_getPostResponse"
                         }
                     },
                     "400": {
@@ -549,7 +616,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "The access token is invalid",
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -563,41 +630,110 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/password-reset": {
-            "put": {
-                "consumes": [
+        "/admin/posts/{postId}/approve": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
                     "application/json"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Resets the user's password.",
+                "summary": "Publishes a post held for moderation.",
                 "parameters": [
                     {
-                        "description": "Reset password body",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
+                        "type": "string",
+                        "description": "post id",
+                        "name": "postId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.resetUserPasswordRequest"
+                            "$ref": "#/definitions/server.getPostResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "There is no held post with the provided id",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
                         }
                     },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/reports": {
+            "get": {
+                "security": [
                     {
-                        "type": "string",
-                        "description": "password reset token",
-                        "name": "token",
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Returns reported posts for review, oldest first, along with the amount of open reports.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return reports in this state: open, resolved or dismissed",
+                        "name": "status",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.messageResponse"
+                            "$ref": "#/definitions/server.listResponse-server_reportResponse"
                         }
                     },
                     "400": {
@@ -607,7 +743,7 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "Password reset token is invalid",
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -619,8 +755,15 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/reports/{reportId}/dismiss": {
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
                 "consumes": [
                     "application/json"
                 ],
@@ -628,17 +771,23 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Creates a password reset token and sends an email with password reset instructions.",
+                "summary": "Dismisses a report which doesn't require any action.",
                 "parameters": [
                     {
-                        "description": "Create password reset token body",
+                        "type": "integer",
+                        "description": "report id",
+                        "name": "reportId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Review body",
                         "name": "request",
                         "in": "body",
-                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/server.createPasswordResetTokenRequest"
+                            "$ref": "#/definitions/server.reviewReportRequest"
                         }
                     }
                 ],
@@ -646,7 +795,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.messageResponse"
+                            "$ref": "#/definitions/server.reportResponse"
                         }
                     },
                     "400": {
@@ -655,6 +804,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/server.errorResponse"
                         }
                     },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "There is no open report with the provided id",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -664,8 +825,8 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/posts": {
-            "get": {
+        "/admin/reports/{reportId}/resolve": {
+            "post": {
                 "security": [
                     {
                         "ApiKeyAuth": []
@@ -678,30 +839,31 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Returns user's posts.",
+                "summary": "Marks a report as resolved after the reported post has been dealt with.",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "page",
-                        "name": "page",
-                        "in": "query",
+                        "description": "report id",
+                        "name": "reportId",
+                        "in": "path",
                         "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "limit",
-                        "name": "limit",
-                        "in": "query",
-                        "required": true
+                        "description": "Review body",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/server.reviewReportRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.getPersonalPostsResponse"
+                            "$ref": "#/definitions/server.reportResponse"
                         }
                     },
                     "400": {
@@ -711,13 +873,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "The access token is invalid",
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
                     },
                     "404": {
-                        "description": "User has no posts",
+                        "description": "There is no open report with the provided id",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -731,8 +893,79 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/register": {
+        "/admin/stats": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Active users are the users who logged in during a bucket. Login attempts are kept for LOGIN_ATTEMPT_RETENTION, so older buckets have no active users or failed logins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Returns the platform's totals and a time series of registrations, active users, published posts and failed logins.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "how many days back the stats go, 30 by default and at most 365",
+                        "name": "days",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "day",
+                            "week",
+                            "month"
+                        ],
+                        "type": "string",
+                        "description": "bucket size of the series, day by default",
+                        "name": "interval",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.getPlatformStatsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/users/bulk": {
             "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Every user is moderated on their own, so the response reports a result per user instead of failing\nthe whole request. The reason is recorded in the audit log entry of every moderated user.",
                 "consumes": [
                     "application/json"
                 ],
@@ -740,17 +973,17 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Registers a user into the platform if the username and email haven't already been taken.",
+                "summary": "Bans users, changes their role or forces them to reset their password.",
                 "parameters": [
                     {
-                        "description": "Register user body",
+                        "description": "Bulk moderation body",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/server.registerRequest"
+                            "$ref": "#/definitions/server.bulkModerationRequest"
                         }
                     }
                 ],
@@ -758,17 +991,17 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.tokenPair"
+                            "$ref": "#/definitions/server.bulkModerationResponse"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Input is invalid",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
                     },
-                    "409": {
-                        "description": "User with this username or email already exists",
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -782,13 +1015,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/token/refresh": {
-            "post": {
+        "/admin/users/{userId}/badge": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "This is unrelated to email verification, see PUT /admin/users/{userId}/verify for that.",
                 "consumes": [
                     "application/json"
                 ],
@@ -796,17 +1030,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Return a fresh pair of tokens.",
+                "summary": "Gives a user the verified badge or takes it away, the badge is shown next to the user's name on their posts.",
                 "parameters": [
                     {
-                        "description": "Refresh token body",
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Verified badge body",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/server.refreshTokenRequest"
+                            "$ref": "#/definitions/server.setVerifiedBadgeRequest"
                         }
                     }
                 ],
@@ -814,7 +1055,7 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/server.tokenPair"
+                            "$ref": "#/definitions/server.messageResponse"
                         }
                     },
                     "400": {
@@ -824,7 +1065,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "The access token or refresh token is invalid.",
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A user with the provided id doesn't exist",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -838,13 +1085,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{userId}": {
-            "delete": {
+        "/admin/users/{userId}/shadowban": {
+            "put": {
                 "security": [
                     {
                         "ApiKeyAuth": []
                     }
                 ],
+                "description": "A shadowbanned user keeps seeing their own posts, while everyone else gets a 404 for them and doesn't\nfind them in any list or widget. Their mentions don't notify anyone. The reason is recorded in the audit log entry.",
                 "consumes": [
                     "application/json"
                 ],
@@ -852,21 +1100,33 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "user"
+                    "admin"
                 ],
-                "summary": "Returns user's posts.",
+                "summary": "Shadowbans a user or lifts the shadowban.",
                 "parameters": [
                     {
-                        "type": "integer",
+                        "type": "string",
                         "description": "user id",
                         "name": "userId",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "description": "Shadowban body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.shadowbanUserRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "User deleted successfully"
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.messageResponse"
+                        }
                     },
                     "400": {
                         "description": "Input is invalid",
@@ -875,7 +1135,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "The access token is invalid",
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A user with the provided id doesn't exist",
                         "schema": {
                             "$ref": "#/definitions/server.errorResponse"
                         }
@@ -888,230 +1154,4443 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "server.confirmMfaRequest": {
-            "type": "object",
+        },
+        "/admin/users/{userId}/verify": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Marks a user's email address as verified without them following the verification link.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A user with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Returns the registered webhooks.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_webhookResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Supported events are post.published and user.registered. Every request carries an X-Webhook-Signature\nheader with the hex encoded HMAC-SHA256 of X-Webhook-Timestamp, a dot and the body, keyed with the webhook's secret.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Registers a webhook endpoint which gets notified about the events it subscribes to.",
+                "parameters": [
+                    {
+                        "description": "Create webhook body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.createWebhookRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.createWebhookResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{webhookId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Deletes a webhook along with its delivery log.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "webhook id",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Webhook deleted successfully"
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A webhook with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/admin/webhooks/{webhookId}/deliveries": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Returns the delivery log of a webhook, newest first.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "webhook id",
+                        "name": "webhookId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return deliveries in this state: pending, succeeded or failed",
+                        "name": "status",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_webhookDeliveryResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A webhook with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/appearance": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "appearance"
+                ],
+                "summary": "Returns the appearance settings frontends should render the blog with.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.appearanceResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/docs": {
+            "get": {
+                "produces": [
+                    "text/html"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Serves the interactive API documentation.",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/docs/openapi.json": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "docs"
+                ],
+                "summary": "Returns the OpenAPI 3 spec of the API.",
+                "responses": {
+                    "200": {
+                        "description": "OK"
+                    }
+                }
+            }
+        },
+        "/health": {
+            "get": {
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Reports that the server is up, along with its version and hostname.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.healthResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/mail/events": {
+            "post": {
+                "description": "Addresses with a permanent bounce or a spam complaint are marked as undeliverable and aren't emailed anymore.\nThe callback URL has to include MAIL_WEBHOOK_TOKEN as the token query parameter. SES notifications are received\nthrough an SNS subscription, which is confirmed automatically.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "mail"
+                ],
+                "summary": "Receives the bounce and complaint callbacks of the email provider in MAIL_PROVIDER.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "MAIL_WEBHOOK_TOKEN",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Events processed successfully"
+                    },
+                    "400": {
+                        "description": "The body is invalid or the provider doesn't report bounces",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Returns the organizations the user is a member of.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_organizationResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Creates an organization, the user creating it becomes its first admin.",
+                "parameters": [
+                    {
+                        "description": "Create organization body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.createOrganizationRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.organizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "An organization with this slug already exists",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organizationId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Returns an organization the user is a member of.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "organization id",
+                        "name": "organizationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.organizationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the user isn't a member of the organization",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "An organization with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Deletes an organization along with its posts.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "organization id",
+                        "name": "organizationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Organization deleted successfully"
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "An organization with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organizationId}/members": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Returns the members of an organization.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "organization id",
+                        "name": "organizationId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_organizationMemberResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the user isn't a member of the organization",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "An organization with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organizationId}/members/{username}": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Adds a user to an organization or changes their role.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "organization id",
+                        "name": "organizationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.setOrganizationMemberRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The organization or user doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The organization's last admin can't be demoted",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Removes a user from an organization, members can remove themselves to leave it.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "organization id",
+                        "name": "organizationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member removed successfully"
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The organization or member doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The organization's last admin can't be removed",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/organizations/{organizationId}/posts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Returns the posts published in an organization.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "organization id",
+                        "name": "organizationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return for each post, e.g. id,title,created_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "updated_at",
+                            "-updated_at"
+                        ],
+                        "type": "string",
+                        "description": "created_at or updated_at, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return posts created after this RFC 3339 timestamp",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return posts created before this RFC 3339 timestamp",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return posts updated after this RFC 3339 timestamp",
+                        "name": "updated_after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_personalPosts"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the user isn't a member of the organization",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "An organization with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "organization"
+                ],
+                "summary": "Publishes a post in an organization.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "organization id",
+                        "name": "organizationId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Create post body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.createPostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.createPostResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "An organization with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "Posts flagged as spam are held for moderation, their status is \"held\" and they're only visible to\ntheir author until a moderator approves them.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "post"
+                ],
+                "summary": "Creates a post",
+                "parameters": [
+                    {
+                        "description": "Create post body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.createPostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.createPostResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/user/{username}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "post"
+                ],
+                "summary": "Gets a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return for each post, e.g. id,title,created_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "updated_at",
+                            "-updated_at"
+                        ],
+                        "type": "string",
+                        "description": "created_at or updated_at, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return posts created after this RFC 3339 timestamp",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return posts created before this RFC 3339 timestamp",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return posts updated after this RFC 3339 timestamp",
+                        "name": "updated_after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_personalPosts"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User has no posts",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postId}": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "post"
+                ],
+                "summary": "Gets a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "post id",
+                        "name": "postId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return, e.g. id,title,created_at",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.getPostResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A post with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "post"
+                ],
+                "summary": "Edits a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "post id",
+                        "name": "postId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Edit post body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.updatePostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.updatePostResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User has no posts",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "post"
+                ],
+                "summary": "Deletes a post",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "post id",
+                        "name": "postId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Post deleted successfully"
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the permissions for performing this action are insufficient",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A post with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postId}/authors": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "post"
+                ],
+                "summary": "Adds a co-author to a post, co-authors may edit the post but not delete it.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "post id",
+                        "name": "postId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Co-author body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.addPostAuthorRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_postAuthorResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid or the post has too many co-authors",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the user doesn't own the post",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The post or user doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postId}/authors/{username}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "post"
+                ],
+                "summary": "Removes a co-author from a post, co-authors can remove themselves.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "post id",
+                        "name": "postId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "username",
+                        "name": "username",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Co-author removed successfully"
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the user is neither the post's owner nor the co-author",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The post doesn't exist or the user isn't its co-author",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/posts/{postId}/report": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "post"
+                ],
+                "summary": "Reports a post to the moderators.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "post id",
+                        "name": "postId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Report body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.reportPostRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.reportResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid or the user can't see the post",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A post with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "The user has already reported the post",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/posts": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Returns the posts of the public token's owner, newest first.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "public token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return for each post, e.g. id,title,created_at",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_personalPosts"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The public token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/public/stats": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "public"
+                ],
+                "summary": "Returns statistics of the public token's owner.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "public token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.getPublicStatsResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The public token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "description": "If the user has 2FA enabled, 302 Found will be returned, in which case POST /users/login/mfa should be used to log the user in.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Checks if the login credentials are correct and returns the access and refresh tokens.",
+                "parameters": [
+                    {
+                        "description": "Login user body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.loginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.tokenPair"
+                        }
+                    },
+                    "302": {
+                        "description": "User has 2FA enabled and needs to call POST /users/login/mfa."
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login/mfa": {
+            "post": {
+                "description": "Checks if the login credentials and totp code are correct and returns the access and refresh tokens. A 400 Bad Request status code and error message will be returned if the user doesn't have 2FA enabled, so only use this if the user has 2FA enabled on their account.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Checks if the login credentials and totp code are correct and returns the access and refresh tokens.",
+                "parameters": [
+                    {
+                        "description": "Login user body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.mfaLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.tokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is either invalid, or user doesn't have 2FA enabled.",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login/recovery": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Checks if the login credentials and recovery code are correct and returns the access and refresh tokens.",
+                "parameters": [
+                    {
+                        "description": "Login user body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.recoveryLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.tokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is either invalid, or the provided recovery code is incorrect.",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Returns the authenticated user's account.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return, e.g. id,username",
+                        "name": "fields",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.userResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/locale": {
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "description": "The locale is one of the languages with a catalog in pkg/i18n, e.g. en, de or es. Users are emailed in the language of the Accept-Language header they registered with until they change it. Emails without a translation are sent in English.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Changes the language the authenticated user is emailed in.",
+                "parameters": [
+                    {
+                        "description": "Locale body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.setLocaleRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid or the language isn't supported",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/notifications": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Returns the user's notifications, newest first, along with the amount of unread ones.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "only return unread notifications",
+                        "name": "unread",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_notificationResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/notifications/read": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Marks all of the user's notifications as read.",
+                "responses": {
+                    "200": {
+                        "description": "Notifications marked as read"
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/me/preferences": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Returns which events notify the user in the app and which by email.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.preferencesResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Changes which events notify the user in the app and which by email.",
+                "parameters": [
+                    {
+                        "description": "Preferences body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.updatePreferencesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.preferencesResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/mfa": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Returns the secret used for generating TOTP codes.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.setupMfaHandlerResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/mfa/confirm": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Checks if the provided TOTP code is correct and returns an array of recovery codes.",
+                "parameters": [
+                    {
+                        "description": "Login user body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.confirmMfaRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.confirmMfaResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/password-reset": {
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Resets the user's password.",
+                "parameters": [
+                    {
+                        "description": "Reset password body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.resetUserPasswordRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "password reset token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Password reset token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Creates a password reset token and sends an email with password reset instructions.",
+                "parameters": [
+                    {
+                        "description": "Create password reset token body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.createPasswordResetTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Too many password reset emails have been sent to the address within EMAIL_RATE_LIMIT_WINDOW",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/posts": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Returns user's posts.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "page",
+                        "name": "page",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "limit",
+                        "name": "limit",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "comma separated fields to return for each post, e.g. id,title,created_at",
+                        "name": "fields",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "created_at",
+                            "-created_at",
+                            "updated_at",
+                            "-updated_at"
+                        ],
+                        "type": "string",
+                        "description": "created_at or updated_at, prefixed with - for descending order",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return posts created after this RFC 3339 timestamp",
+                        "name": "created_after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return posts created before this RFC 3339 timestamp",
+                        "name": "created_before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "only return posts updated after this RFC 3339 timestamp",
+                        "name": "updated_after",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_personalPosts"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "User has no posts",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/register": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Registers a user into the platform if the username and email haven't already been taken.",
+                "parameters": [
+                    {
+                        "description": "Register user body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.registerRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.tokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "User with this username or email already exists",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/token/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Return a fresh pair of tokens.",
+                "parameters": [
+                    {
+                        "description": "Refresh token body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.refreshTokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.tokenPair"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token or refresh token is invalid.",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/tokens": {
+            "get": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Returns the user's public tokens.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.listResponse-server_apiTokenResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Creates a read-only public token which can be used to read the user's posts, e.g. from widgets embedded on other sites.",
+                "parameters": [
+                    {
+                        "description": "Create api token body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.createAPITokenRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/server.createAPITokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/tokens/{tokenId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Revokes a public token.",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "token id",
+                        "name": "tokenId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Token revoked successfully"
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The user doesn't have a token with the provided id",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/verify-email": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Verifies the user's email address with the token from the verification email.",
+                "parameters": [
+                    {
+                        "description": "Verify email body",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/server.verifyEmailRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The verification token is invalid or has expired",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/verify-email/resend": {
+            "post": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Sends a new verification email to the authenticated user.",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.messageResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "The email address is already verified",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "A verification email has been sent too recently, or too many within EMAIL_RATE_LIMIT_WINDOW",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{userId}": {
+            "delete": {
+                "security": [
+                    {
+                        "ApiKeyAuth": []
+                    }
+                ],
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "user"
+                ],
+                "summary": "Returns user's posts.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "user id",
+                        "name": "userId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User deleted successfully"
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The access token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "A user with the provided id doesn't exist",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/widgets/latest-posts": {
+            "get": {
+                "description": "The widget is rendered as HTML if the client accepts text/html or format=html is passed, and as JSON otherwise.",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "widgets"
+                ],
+                "summary": "Returns the newest posts of the public token's owner as an embeddable widget.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "public token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "amount of posts, 5 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or html",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.latestPostsWidgetResponse"
+                        }
+                    },
+                    "304": {
+                        "description": "The widget hasn't changed since it was last fetched"
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The public token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/widgets/posts/{postId}": {
+            "get": {
+                "description": "The widget is rendered as HTML if the client accepts text/html or format=html is passed, and as JSON otherwise.",
+                "produces": [
+                    "application/json",
+                    "text/html"
+                ],
+                "tags": [
+                    "widgets"
+                ],
+                "summary": "Returns a single post of the public token's owner as an embeddable card.",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "post id",
+                        "name": "postId",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "public token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "json or html",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/server.widgetPost"
+                        }
+                    },
+                    "304": {
+                        "description": "The widget hasn't changed since it was last fetched"
+                    },
+                    "400": {
+                        "description": "Input is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "The public token is invalid",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "The token's owner doesn't have a post with the provided id",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/server.errorResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "server.addPostAuthorRequest": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.apiError": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "string"
+                },
+                "fields": {
+                    "description": "Fields holds the messages of a VALIDATION_FAILED error keyed by the invalid field.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        }
+                    }
+                },
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.apiTokenResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.appearanceResponse": {
+            "type": "object",
+            "properties": {
+                "accent_color": {
+                    "type": "string"
+                },
+                "footer_text": {
+                    "type": "string"
+                },
+                "logo_url": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.auditLogEntryResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "type": "string"
+                },
+                "actor_id": {
+                    "type": "integer"
+                },
+                "after": {
+                    "type": "object"
+                },
+                "before": {
+                    "type": "object"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "target_id": {
+                    "type": "integer"
+                },
+                "target_type": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.bulkModerationRequest": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "type": "string",
+                    "enum": [
+                        "ban",
+                        "change_role",
+                        "reset_password"
+                    ]
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role is the role users are moved to, it's only used by change_role.",
+                    "type": "string"
+                },
+                "user_ids": {
+                    "description": "UserIDs are the public ids of the users.",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "server.bulkModerationResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.bulkModerationResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "server.bulkModerationResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "ok",
+                        "failed"
+                    ]
+                },
+                "user_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.confirmMfaRequest": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                },
+                "totp": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.confirmMfaResponse": {
+            "type": "object",
+            "properties": {
+                "recovery_codes": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                }
+            }
+        },
+        "server.createAPITokenRequest": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.createAPITokenResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_used_at": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "token": {
+                    "description": "the token is only returned once, when it's created",
+                    "type": "string"
+                }
+            }
+        },
+        "server.createOrganizationRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.createPasswordResetTokenRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.createPostRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "canonical_url": {
+                    "description": "CanonicalURL is the original location of a post cross-posted from another platform.",
+                    "type": "string"
+                },
+                "license": {
+                    "description": "License is empty if the post's license is unspecified.",
+                    "type": "string",
+                    "enum": [
+                        "all-rights-reserved",
+                        "CC-BY-4.0",
+                        "CC-BY-SA-4.0",
+                        "CC-BY-ND-4.0",
+                        "CC-BY-NC-4.0",
+                        "CC-BY-NC-SA-4.0",
+                        "CC-BY-NC-ND-4.0",
+                        "CC0-1.0"
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.createPostResponse": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "canonical_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "license": {
+                    "type": "string"
+                },
+                "links": {
+                    "$ref": "#/definitions/server.postLinks"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "published",
+                        "held"
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.createWebhookRequest": {
+            "type": "object",
+            "required": [
+                "url"
+            ],
+            "properties": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.createWebhookResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "secret": {
+                    "description": "the secret is only returned once, when the webhook is created",
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.errorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "description": "Error response model",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/server.apiError"
+                        }
+                    ]
+                }
+            }
+        },
+        "server.getPlatformStatsResponse": {
+            "type": "object",
+            "properties": {
+                "interval": {
+                    "type": "string"
+                },
+                "series": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.statsBucketResponse"
+                    }
+                },
+                "since": {
+                    "type": "string"
+                },
+                "totals": {
+                    "description": "Totals.Users and Totals.Posts are all-time totals, the other counts only cover the time since Since.",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/server.statsTotalsResponse"
+                        }
+                    ]
+                }
+            }
+        },
+        "server.getPostResponse": {
+            "type": "object",
+            "properties": {
+                "authors": {
+                    "description": "Authors lists the post's owner followed by its co-authors, it's only returned for a single post.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.postAuthorResponse"
+                    }
+                },
+                "body": {
+                    "type": "string"
+                },
+                "canonical_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "license": {
+                    "type": "string"
+                },
+                "links": {
+                    "$ref": "#/definitions/server.postLinks"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "published",
+                        "held"
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.getPublicStatsResponse": {
+            "type": "object",
+            "properties": {
+                "posts": {
+                    "type": "integer"
+                }
+            }
+        },
+        "server.healthResponse": {
+            "type": "object",
+            "properties": {
+                "host": {
+                    "type": "string"
+                },
+                "version": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.jobResponse": {
+            "type": "object",
+            "properties": {
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "max_attempts": {
+                    "type": "integer"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "run_at": {
+                    "type": "string"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.latestPostsWidgetResponse": {
+            "type": "object",
+            "properties": {
+                "posts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.widgetPost"
+                    }
+                }
+            }
+        },
+        "server.listLinks": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "type": "string"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "self": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.listMeta": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "Count is the number of items in data.",
+                    "type": "integer"
+                },
+                "counts": {
+                    "description": "Counts holds totals some lists report next to the page, e.g. the amount of unread notifications.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "page": {
+                    "description": "Page and Limit are left out of lists which aren't paginated.",
+                    "type": "integer"
+                }
+            }
+        },
+        "server.listResponse-server_apiTokenResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.apiTokenResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_auditLogEntryResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.auditLogEntryResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_getPostResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.getPostResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_jobResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.jobResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_notificationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.notificationResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_organizationMemberResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.organizationMemberResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_organizationResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.organizationResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_personalPosts": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.personalPosts"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_postAuthorResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.postAuthorResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_recordChangeResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.recordChangeResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_reportResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.reportResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_webhookDeliveryResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.webhookDeliveryResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.listResponse-server_webhookResponse": {
+            "type": "object",
+            "properties": {
+                "data": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/server.webhookResponse"
+                    }
+                },
+                "links": {
+                    "$ref": "#/definitions/server.listLinks"
+                },
+                "meta": {
+                    "$ref": "#/definitions/server.listMeta"
+                }
+            }
+        },
+        "server.loginRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.maintenanceResponse": {
+            "type": "object",
+            "properties": {
+                "allow_reads": {
+                    "type": "boolean"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "message": {
+                    "type": "string"
+                },
+                "retry_after": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.messageResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "description": "Message response model",
+                    "type": "string"
+                }
+            }
+        },
+        "server.mfaLoginRequest": {
+            "type": "object",
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "totp": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.notificationResponse": {
+            "type": "object",
+            "properties": {
+                "actor": {
+                    "description": "Actor is the username of the user who caused the notification, it's null once they have been deleted.",
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "post_id": {
+                    "type": "string"
+                },
+                "post_title": {
+                    "type": "string"
+                },
+                "read_at": {
+                    "type": "string"
+                },
+                "type": {
+                    "type": "string",
+                    "enum": [
+                        "mention"
+                    ]
+                }
+            }
+        },
+        "server.organizationMemberResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "editor",
+                        "reader"
+                    ]
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.organizationResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "role": {
+                    "description": "Role is the requesting user's role in the organization.",
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "editor",
+                        "reader"
+                    ]
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.personalPosts": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "canonical_url": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "license": {
+                    "type": "string"
+                },
+                "links": {
+                    "$ref": "#/definitions/server.postLinks"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "published",
+                        "held"
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.postAuthorResponse": {
+            "type": "object",
+            "properties": {
+                "co_author": {
+                    "description": "CoAuthor is false for the post's owner.",
+                    "type": "boolean"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "user_id": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "server.postLinks": {
+            "type": "object",
             "properties": {
-                "secret": {
+                "author": {
                     "type": "string"
                 },
-                "totp": {
+                "self": {
                     "type": "string"
                 }
             }
         },
-        "server.confirmMfaResponse": {
+        "server.preferencesResponse": {
             "type": "object",
             "properties": {
-                "recovery_codes": {
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    }
+                "mention_email": {
+                    "type": "boolean"
+                },
+                "mention_in_app": {
+                    "type": "boolean"
+                },
+                "security_email": {
+                    "description": "SecurityEmail is whether the user is emailed about logins from new devices and changes to their account.",
+                    "type": "boolean"
+                },
+                "updated_at": {
+                    "type": "string"
                 }
             }
         },
-        "server.createPasswordResetTokenRequest": {
+        "server.recordChangeResponse": {
             "type": "object",
             "properties": {
-                "email": {
+                "actor_id": {
+                    "type": "integer"
+                },
+                "changed_at": {
                     "type": "string"
+                },
+                "deleted": {
+                    "type": "boolean"
+                },
+                "record": {
+                    "type": "object"
                 }
             }
         },
-        "server.createPostRequest": {
+        "server.recoveryLoginRequest": {
             "type": "object",
             "properties": {
-                "body": {
+                "password": {
                     "type": "string"
                 },
-                "title": {
+                "recovery_code": {
+                    "type": "string"
+                },
+                "username": {
                     "type": "string"
                 }
             }
         },
-        "server.createPostResponse": {
+        "server.refreshTokenRequest": {
             "type": "object",
             "properties": {
-                "body": {
+                "refresh_token": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.registerRequest": {
+            "type": "object",
+            "required": [
+                "email"
+            ],
+            "properties": {
+                "email": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.reportPostRequest": {
+            "type": "object",
+            "required": [
+                "reason"
+            ],
+            "properties": {
+                "details": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string",
+                    "enum": [
+                        "spam",
+                        "harassment",
+                        "hate",
+                        "violence",
+                        "sexual",
+                        "misinformation",
+                        "other"
+                    ]
+                }
+            }
+        },
+        "server.reportResponse": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "details": {
+                    "description": "Details are shown to moderators only.",
                     "type": "string"
                 },
                 "id": {
                     "type": "integer"
                 },
-                "title": {
+                "post_id": {
+                    "description": "PostID and PostTitle are null once the post has been deleted.",
+                    "type": "string"
+                },
+                "post_title": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                },
+                "reporter_username": {
+                    "type": "string"
+                },
+                "review_note": {
+                    "type": "string"
+                },
+                "reviewed_at": {
                     "type": "string"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "open",
+                        "resolved",
+                        "dismissed"
+                    ]
                 }
             }
         },
-        "server.errorResponse": {
+        "server.resetUserPasswordRequest": {
             "type": "object",
             "properties": {
-                "error": {
-                    "description": "Error response model",
+                "password": {
                     "type": "string"
                 }
             }
         },
-        "server.getPersonalPostsResponse": {
+        "server.reviewReportRequest": {
             "type": "object",
             "properties": {
-                "posts": {
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/server.personalPosts"
-                    }
+                "note": {
+                    "type": "string"
                 }
             }
         },
-        "server.getPostResponse": {
+        "server.setLocaleRequest": {
             "type": "object",
             "properties": {
-                "body": {
+                "locale": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.setOrganizationMemberRequest": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "editor",
+                        "reader"
+                    ]
+                }
+            }
+        },
+        "server.setVerifiedBadgeRequest": {
+            "type": "object",
+            "required": [
+                "verified"
+            ],
+            "properties": {
+                "verified": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "server.setupMfaHandlerResponse": {
+            "type": "object",
+            "properties": {
+                "secret": {
+                    "type": "string"
+                }
+            }
+        },
+        "server.shadowbanUserRequest": {
+            "type": "object",
+            "required": [
+                "shadowbanned"
+            ],
+            "properties": {
+                "reason": {
                     "type": "string"
                 },
-                "id": {
+                "shadowbanned": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "server.statsBucketResponse": {
+            "type": "object",
+            "properties": {
+                "active_users": {
                     "type": "integer"
                 },
-                "title": {
+                "login_failures": {
+                    "type": "integer"
+                },
+                "posts_published": {
+                    "type": "integer"
+                },
+                "registrations": {
+                    "type": "integer"
+                },
+                "start": {
                     "type": "string"
                 }
             }
         },
-        "server.loginRequest": {
+        "server.statsTotalsResponse": {
             "type": "object",
             "properties": {
-                "password": {
-                    "type": "string"
+                "active_users": {
+                    "type": "integer"
                 },
-                "username": {
-                    "type": "string"
+                "login_failures": {
+                    "type": "integer"
+                },
+                "posts": {
+                    "type": "integer"
+                },
+                "posts_published": {
+                    "type": "integer"
+                },
+                "registrations": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "integer"
                 }
             }
         },
-        "server.messageResponse": {
+        "server.tokenPair": {
             "type": "object",
             "properties": {
-                "message": {
-                    "description": "Message response model",
+                "access_token": {
+                    "description": "user's access token",
+                    "type": "string"
+                },
+                "refresh_token": {
+                    "description": "user's refresh token",
                     "type": "string"
                 }
             }
         },
-        "server.mfaLoginRequest": {
+        "server.updateAppearanceRequest": {
             "type": "object",
             "properties": {
-                "password": {
+                "accent_color": {
                     "type": "string"
                 },
-                "totp": {
+                "footer_text": {
                     "type": "string"
                 },
-                "username": {
+                "logo_url": {
                     "type": "string"
                 }
             }
         },
-        "server.personalPosts": {
+        "server.updateMaintenanceRequest": {
             "type": "object",
             "properties": {
-                "body": {
+                "allow_reads": {
+                    "type": "boolean"
+                },
+                "enabled": {
+                    "type": "boolean"
+                },
+                "message": {
                     "type": "string"
                 },
-                "id": {
+                "retry_after": {
                     "type": "integer"
+                }
+            }
+        },
+        "server.updatePostRequest": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string"
+                },
+                "canonical_url": {
+                    "type": "string"
+                },
+                "license": {
+                    "type": "string",
+                    "enum": [
+                        "all-rights-reserved",
+                        "CC-BY-4.0",
+                        "CC-BY-SA-4.0",
+                        "CC-BY-ND-4.0",
+                        "CC-BY-NC-4.0",
+                        "CC-BY-NC-SA-4.0",
+                        "CC-BY-NC-ND-4.0",
+                        "CC0-1.0"
+                    ]
                 },
                 "title": {
                     "type": "string"
                 }
             }
         },
-        "server.recoveryLoginRequest": {
+        "server.updatePostResponse": {
             "type": "object",
             "properties": {
-                "password": {
+                "body": {
                     "type": "string"
                 },
-                "recovery_code": {
+                "canonical_url": {
                     "type": "string"
                 },
-                "username": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "license": {
+                    "type": "string"
+                },
+                "links": {
+                    "$ref": "#/definitions/server.postLinks"
+                },
+                "status": {
+                    "type": "string",
+                    "enum": [
+                        "published",
+                        "held"
+                    ]
+                },
+                "title": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 }
             }
         },
-        "server.refreshTokenRequest": {
+        "server.updatePreferencesRequest": {
             "type": "object",
             "properties": {
-                "refresh_token": {
-                    "type": "string"
+                "mention_email": {
+                    "type": "boolean"
+                },
+                "mention_in_app": {
+                    "type": "boolean"
+                },
+                "security_email": {
+                    "type": "boolean"
                 }
             }
         },
-        "server.registerRequest": {
+        "server.userResponse": {
             "type": "object",
             "properties": {
+                "created_at": {
+                    "type": "string"
+                },
                 "email": {
                     "type": "string"
                 },
-                "password": {
+                "email_verified": {
+                    "type": "boolean"
+                },
+                "email_verified_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "locale": {
+                    "description": "Locale is the language the user is emailed in.",
+                    "type": "string"
+                },
+                "mfa_enabled": {
+                    "type": "boolean"
+                },
+                "role": {
+                    "type": "string"
+                },
+                "updated_at": {
                     "type": "string"
                 },
                 "username": {
                     "type": "string"
-                }
-            }
-        },
-        "server.resetUserPasswordRequest": {
-            "type": "object",
-            "properties": {
-                "password": {
+                },
+                "verification_sent_at": {
                     "type": "string"
+                },
+                "verified": {
+                    "type": "boolean"
                 }
             }
         },
-        "server.setupMfaHandlerResponse": {
+        "server.verifyEmailRequest": {
             "type": "object",
             "properties": {
-                "secret": {
+                "token": {
                     "type": "string"
                 }
             }
         },
-        "server.tokenPair": {
+        "server.webhookDeliveryResponse": {
             "type": "object",
             "properties": {
-                "access_token": {
-                    "description": "user's access token",
+                "attempts": {
+                    "type": "integer"
+                },
+                "created_at": {
                     "type": "string"
                 },
-                "refresh_token": {
-                    "description": "user's refresh token",
+                "delivered_at": {
+                    "type": "string"
+                },
+                "event": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "last_error": {
+                    "type": "string"
+                },
+                "payload": {
+                    "type": "object"
+                },
+                "response_status": {
+                    "type": "integer"
+                },
+                "status": {
                     "type": "string"
                 }
             }
         },
-        "server.updatePostRequest": {
+        "server.webhookResponse": {
             "type": "object",
             "properties": {
-                "body": {
+                "created_at": {
                     "type": "string"
                 },
-                "title": {
+                "events": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "url": {
                     "type": "string"
                 }
             }
         },
-        "server.updatePostResponse": {
+        "server.widgetPost": {
             "type": "object",
             "properties": {
-                "body": {
+                "excerpt": {
                     "type": "string"
                 },
                 "id": {
-                    "type": "integer"
+                    "type": "string"
                 },
                 "title": {
                     "type": "string"
@@ -0,0 +1,113 @@
+// Command gen converts the Swagger 2.0 spec generated by swag into the OpenAPI 3 spec in openapi.json and checks
+// it documents exactly the routes of the router. It's run by go generate in the docs directory.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/XiovV/blog-api/config"
+	"github.com/XiovV/blog-api/server"
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const basePath = "/v1"
+
+// undocumentedRoutes are served outside of the API and aren't part of the spec.
+var undocumentedRoutes = map[string]bool{
+	"GET /metrics": true,
+}
+
+var pathParam = regexp.MustCompile(`:([A-Za-z]+)`)
+
+func main() {
+	err := run()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	data, err := os.ReadFile("swagger.json")
+	if err != nil {
+		return err
+	}
+
+	var doc2 openapi2.T
+	err = json.Unmarshal(data, &doc2)
+	if err != nil {
+		return fmt.Errorf("couldn't parse swagger.json: %w", err)
+	}
+
+	doc3, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return fmt.Errorf("couldn't convert the spec: %w", err)
+	}
+
+	// relative, so the docs call the API they're served by
+	doc3.Servers = openapi3.Servers{{URL: basePath}}
+
+	err = checkRoutes(doc3)
+	if err != nil {
+		return err
+	}
+
+	out, err := json.MarshalIndent(doc3, "", "    ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile("openapi.json", append(out, '\n'), 0644)
+}
+
+// checkRoutes compares the documented operations with the routes of the router.
+func checkRoutes(doc *openapi3.T) error {
+	gin.SetMode(gin.ReleaseMode)
+
+	s := &server.Server{Config: &config.Config{MetricsEnabled: true}}
+
+	routes := map[string]bool{}
+	for _, route := range s.Router().Routes() {
+		key := route.Method + " " + route.Path
+		if undocumentedRoutes[key] {
+			continue
+		}
+
+		path := pathParam.ReplaceAllString(strings.TrimPrefix(route.Path, basePath), "{$1}")
+		routes[route.Method+" "+path] = true
+	}
+
+	documented := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method := range item.Operations() {
+			documented[method+" "+path] = true
+		}
+	}
+
+	var problems []string
+	for route := range routes {
+		if !documented[route] {
+			problems = append(problems, "undocumented route: "+route)
+		}
+	}
+
+	for operation := range documented {
+		if !routes[operation] {
+			problems = append(problems, "documented route doesn't exist: "+operation)
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("the spec is out of sync with the router:\n%s", strings.Join(problems, "\n"))
+	}
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package docs
+
+import _ "embed"
+
+// The swag annotations are compiled into the Swagger 2.0 spec in swagger.json, which gen converts into the
+// OpenAPI 3 spec served at /v1/docs. gen fails if a route of the router isn't documented or a documented route
+// doesn't exist.
+//go:generate swag init -d .. -g server/server.go -o .
+//go:generate go run ./gen
+
+// OpenAPI is the OpenAPI 3 spec of the API.
+//
+//go:embed openapi.json
+var OpenAPI []byte
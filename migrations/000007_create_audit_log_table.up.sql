@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS audit_log(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    actor_id BIGINT,
+    action text NOT NULL,
+    target_type text NOT NULL,
+    target_id BIGINT NOT NULL,
+    before JSONB,
+    after JSONB,
+    request_id text NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_created_at_idx ON audit_log(created_at);
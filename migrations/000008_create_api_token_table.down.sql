@@ -0,0 +1 @@
+DROP TABLE IF EXISTS api_token;
\ No newline at end of file
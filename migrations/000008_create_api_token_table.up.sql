@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS api_token(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    user_id BIGINT NOT NULL,
+    name text NOT NULL,
+    token_hash text UNIQUE NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+            REFERENCES "user"(id)
+            ON DELETE CASCADE
+);
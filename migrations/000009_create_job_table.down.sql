@@ -0,0 +1 @@
+DROP TABLE IF EXISTS job;
\ No newline at end of file
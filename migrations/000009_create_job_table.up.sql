@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS job(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    type text NOT NULL,
+    payload JSONB NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL,
+    last_error text NOT NULL DEFAULT '',
+    run_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    locked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS job_pending_idx ON job(run_at) WHERE status = 'pending';
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS webhook(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    url text NOT NULL,
+    secret text NOT NULL,
+    events text[] NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS webhook_delivery(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    webhook_id BIGINT NOT NULL,
+    event text NOT NULL,
+    payload JSONB NOT NULL,
+    status text NOT NULL DEFAULT 'pending',
+    attempts INT NOT NULL DEFAULT 0,
+    response_status INT,
+    last_error text NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+    CONSTRAINT fk_webhook
+        FOREIGN KEY(webhook_id)
+            REFERENCES webhook(id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS webhook_delivery_webhook_id_idx ON webhook_delivery(webhook_id, id DESC);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS appearance;
\ No newline at end of file
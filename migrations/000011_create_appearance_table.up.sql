@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS appearance(
+    id INT PRIMARY KEY NOT NULL DEFAULT 1 CHECK (id = 1),
+    accent_color text NOT NULL DEFAULT '',
+    logo_url text NOT NULL DEFAULT '',
+    footer_text text NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO appearance (id) VALUES (1) ON CONFLICT DO NOTHING;
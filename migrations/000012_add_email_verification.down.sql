@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS email_verification_token;
+ALTER TABLE "user" DROP COLUMN IF EXISTS verification_sent_at;
+ALTER TABLE "user" DROP COLUMN IF EXISTS email_verified_at;
\ No newline at end of file
@@ -0,0 +1,16 @@
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMPTZ;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS verification_sent_at TIMESTAMPTZ;
+
+-- accounts created before verification existed are treated as verified
+UPDATE "user" SET email_verified_at = NOW() WHERE email_verified_at IS NULL;
+
+CREATE TABLE IF NOT EXISTS email_verification_token(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    user_id BIGINT NOT NULL,
+    token_hash text UNIQUE NOT NULL,
+    expiry BIGINT NOT NULL,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+            REFERENCES "user"(id)
+            ON DELETE CASCADE
+);
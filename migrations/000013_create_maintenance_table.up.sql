@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS maintenance(
+    id INT PRIMARY KEY NOT NULL DEFAULT 1 CHECK (id = 1),
+    enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    allow_reads BOOLEAN NOT NULL DEFAULT FALSE,
+    message text NOT NULL DEFAULT '',
+    retry_after INT NOT NULL DEFAULT 300,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+INSERT INTO maintenance (id) VALUES (1) ON CONFLICT DO NOTHING;
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS record_history;
\ No newline at end of file
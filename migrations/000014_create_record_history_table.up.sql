@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS record_history(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    record_type text NOT NULL,
+    record_id BIGINT NOT NULL,
+    actor_id BIGINT,
+    snapshot JSONB,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS record_history_record_idx ON record_history(record_type, record_id, changed_at DESC);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS post_user_id_created_at_idx;
+ALTER TABLE post DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE post DROP COLUMN IF EXISTS created_at;
+ALTER TABLE "user" DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE "user" DROP COLUMN IF EXISTS created_at;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE post ADD COLUMN IF NOT EXISTS search_vector TSVECTOR;
+
+UPDATE post SET search_vector = setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', body), 'B');
+
+CREATE INDEX IF NOT EXISTS post_search_vector_idx ON post USING GIN(search_vector);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS password_reset_token_expiry_idx;
+DROP INDEX IF EXISTS token_blacklist_expires_at_idx;
+ALTER TABLE token_blacklist DROP COLUMN IF EXISTS expires_at;
\ No newline at end of file
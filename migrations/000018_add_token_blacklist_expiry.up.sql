@@ -0,0 +1,9 @@
+ALTER TABLE token_blacklist ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ;
+
+UPDATE token_blacklist SET expires_at = NOW() + INTERVAL '17532 hours' WHERE expires_at IS NULL;
+
+ALTER TABLE token_blacklist ALTER COLUMN expires_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS token_blacklist_expires_at_idx ON token_blacklist(expires_at);
+
+CREATE INDEX IF NOT EXISTS password_reset_token_expiry_idx ON password_reset_token(expiry);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS post_public_id_idx;
+DROP INDEX IF EXISTS user_public_id_idx;
+ALTER TABLE post DROP COLUMN IF EXISTS public_id;
+ALTER TABLE "user" DROP COLUMN IF EXISTS public_id;
\ No newline at end of file
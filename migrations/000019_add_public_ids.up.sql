@@ -0,0 +1,5 @@
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE post ADD COLUMN IF NOT EXISTS public_id UUID NOT NULL DEFAULT gen_random_uuid();
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_public_id_idx ON "user"(public_id);
+CREATE UNIQUE INDEX IF NOT EXISTS post_public_id_idx ON post(public_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS post_organization_id_created_at_idx;
+ALTER TABLE post DROP COLUMN IF EXISTS organization_id;
+DROP TABLE IF EXISTS organization_member;
+DROP TABLE IF EXISTS organization;
\ No newline at end of file
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS organization(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    public_id UUID NOT NULL UNIQUE DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    slug TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS organization_member(
+    organization_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    role TEXT NOT NULL CHECK (role IN ('admin', 'editor', 'reader')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id),
+    CONSTRAINT fk_organization
+        FOREIGN KEY(organization_id)
+            REFERENCES organization(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+            REFERENCES "user"(id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS organization_member_user_id_idx ON organization_member(user_id);
+
+ALTER TABLE post ADD COLUMN IF NOT EXISTS organization_id BIGINT REFERENCES organization(id) ON DELETE CASCADE;
+
+CREATE INDEX IF NOT EXISTS post_organization_id_created_at_idx ON post(organization_id, created_at);
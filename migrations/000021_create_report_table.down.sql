@@ -0,0 +1 @@
+DROP TABLE IF EXISTS report;
\ No newline at end of file
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS report(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    post_id BIGINT,
+    reporter_id BIGINT,
+    reason TEXT NOT NULL CHECK (reason IN ('spam', 'harassment', 'hate', 'violence', 'sexual', 'misinformation', 'other')),
+    details TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved', 'dismissed')),
+    reviewer_id BIGINT,
+    review_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    reviewed_at TIMESTAMPTZ,
+    CONSTRAINT fk_post
+        FOREIGN KEY(post_id)
+            REFERENCES post(id)
+            ON DELETE SET NULL,
+    CONSTRAINT fk_reporter
+        FOREIGN KEY(reporter_id)
+            REFERENCES "user"(id)
+            ON DELETE SET NULL,
+    CONSTRAINT fk_reviewer
+        FOREIGN KEY(reviewer_id)
+            REFERENCES "user"(id)
+            ON DELETE SET NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS report_open_post_reporter_idx ON report(post_id, reporter_id) WHERE status = 'open';
+CREATE INDEX IF NOT EXISTS report_status_created_at_idx ON report(status, created_at);
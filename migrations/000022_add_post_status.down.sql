@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS post_held_created_at_idx;
+ALTER TABLE post DROP COLUMN IF EXISTS status;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE post ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'published' CHECK (status IN ('published', 'held'));
+
+CREATE INDEX IF NOT EXISTS post_held_created_at_idx ON post(created_at) WHERE status = 'held';
@@ -0,0 +1,40 @@
+CREATE TABLE IF NOT EXISTS mention(
+    post_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(post_id, user_id),
+    CONSTRAINT fk_post
+        FOREIGN KEY(post_id)
+            REFERENCES post(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+            REFERENCES "user"(id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS mention_user_id_idx ON mention(user_id);
+
+CREATE TABLE IF NOT EXISTS notification(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    user_id BIGINT NOT NULL,
+    type TEXT NOT NULL,
+    actor_id BIGINT,
+    post_id BIGINT,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+            REFERENCES "user"(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_actor
+        FOREIGN KEY(actor_id)
+            REFERENCES "user"(id)
+            ON DELETE SET NULL,
+    CONSTRAINT fk_post
+        FOREIGN KEY(post_id)
+            REFERENCES post(id)
+            ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS notification_user_id_created_at_idx ON notification(user_id, created_at);
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS notification_preference(
+    user_id BIGINT PRIMARY KEY NOT NULL,
+    mention_in_app BOOLEAN NOT NULL DEFAULT TRUE,
+    mention_email BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+            REFERENCES "user"(id)
+            ON DELETE CASCADE
+);
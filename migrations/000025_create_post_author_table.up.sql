@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS post_author(
+    post_id BIGINT NOT NULL,
+    user_id BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY(post_id, user_id),
+    CONSTRAINT fk_post
+        FOREIGN KEY(post_id)
+            REFERENCES post(id)
+            ON DELETE CASCADE,
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+            REFERENCES "user"(id)
+            ON DELETE CASCADE
+);
@@ -0,0 +1,2 @@
+ALTER TABLE post DROP COLUMN IF EXISTS license;
+ALTER TABLE post DROP COLUMN IF EXISTS canonical_url;
\ No newline at end of file
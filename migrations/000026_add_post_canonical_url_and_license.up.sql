@@ -0,0 +1,2 @@
+ALTER TABLE post ADD COLUMN IF NOT EXISTS canonical_url TEXT NOT NULL DEFAULT '';
+ALTER TABLE post ADD COLUMN IF NOT EXISTS license TEXT NOT NULL DEFAULT '';
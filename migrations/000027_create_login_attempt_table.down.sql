@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS post_created_at_idx;
+DROP INDEX IF EXISTS user_created_at_idx;
+DROP TABLE IF EXISTS login_attempt;
\ No newline at end of file
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS login_attempt(
+    id BIGSERIAL PRIMARY KEY NOT NULL,
+    user_id BIGINT,
+    succeeded BOOLEAN NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CONSTRAINT fk_user
+        FOREIGN KEY(user_id)
+            REFERENCES "user"(id)
+            ON DELETE SET NULL
+);
+
+CREATE INDEX IF NOT EXISTS login_attempt_created_at_idx ON login_attempt(created_at);
+
+CREATE INDEX IF NOT EXISTS user_created_at_idx ON "user"(created_at);
+CREATE INDEX IF NOT EXISTS post_created_at_idx ON post(created_at);
@@ -0,0 +1 @@
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS verified BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS user_shadowbanned_idx;
+ALTER TABLE "user" DROP COLUMN IF EXISTS shadowbanned;
\ No newline at end of file
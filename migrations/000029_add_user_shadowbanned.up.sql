@@ -0,0 +1,4 @@
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS shadowbanned BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- post lists exclude the posts of shadowbanned users, who are few
+CREATE INDEX IF NOT EXISTS user_shadowbanned_idx ON "user"(id) WHERE shadowbanned;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS login_attempt_user_id_idx;
+ALTER TABLE notification_preference DROP COLUMN IF EXISTS security_email;
+ALTER TABLE login_attempt DROP COLUMN IF EXISTS user_agent;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE login_attempt ADD COLUMN IF NOT EXISTS user_agent TEXT NOT NULL DEFAULT '';
+ALTER TABLE notification_preference ADD COLUMN IF NOT EXISTS security_email BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- new device checks look up the successful logins of a single user
+CREATE INDEX IF NOT EXISTS login_attempt_user_id_idx ON login_attempt(user_id) WHERE succeeded;
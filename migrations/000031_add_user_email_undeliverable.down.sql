@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS user_lower_email_idx;
+ALTER TABLE "user" DROP COLUMN IF EXISTS email_undeliverable_reason;
+ALTER TABLE "user" DROP COLUMN IF EXISTS email_undeliverable_at;
\ No newline at end of file
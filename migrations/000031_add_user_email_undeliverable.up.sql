@@ -0,0 +1,5 @@
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS email_undeliverable_at TIMESTAMPTZ;
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS email_undeliverable_reason TEXT;
+
+-- bounces and complaints only carry the address
+CREATE INDEX IF NOT EXISTS user_lower_email_idx ON "user"(LOWER(email));
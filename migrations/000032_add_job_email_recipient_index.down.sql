@@ -0,0 +1 @@
+DROP INDEX IF EXISTS job_email_recipient_idx;
\ No newline at end of file
@@ -0,0 +1,2 @@
+-- the email rate limit counts the emails queued for an address
+CREATE INDEX IF NOT EXISTS job_email_recipient_idx ON job(LOWER(payload->>'recipient'), created_at) WHERE type = 'email.send';
@@ -0,0 +1,2 @@
+-- the language the user is emailed in, set from Accept-Language when they register
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en';
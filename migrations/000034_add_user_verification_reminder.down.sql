@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS user_unverified_created_at_idx;
+ALTER TABLE "user" DROP COLUMN IF EXISTS verification_reminder_sent_at;
\ No newline at end of file
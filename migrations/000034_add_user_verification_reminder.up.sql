@@ -0,0 +1,4 @@
+ALTER TABLE "user" ADD COLUMN IF NOT EXISTS verification_reminder_sent_at TIMESTAMPTZ;
+
+-- the reminder and cleanup jobs only look at unverified users
+CREATE INDEX IF NOT EXISTS user_unverified_created_at_idx ON "user"(created_at) WHERE email_verified_at IS NULL;
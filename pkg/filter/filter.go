@@ -0,0 +1,216 @@
+// Package filter parses filter expressions in query strings, e.g.
+//
+//	?filter[status]=published&filter[created_at][gte]=2024-01-01
+//
+// and translates them into SQL conditions. Only the fields of a Schema can be filtered and their columns come from
+// the schema, the values are always passed as query arguments.
+package filter
+
+import (
+	"fmt"
+	"github.com/lib/pq"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Operator string
+
+const (
+	Eq  Operator = "eq"
+	Ne  Operator = "ne"
+	Gt  Operator = "gt"
+	Gte Operator = "gte"
+	Lt  Operator = "lt"
+	Lte Operator = "lte"
+	// In matches any of the comma separated values, it's only supported for String and Int fields.
+	In Operator = "in"
+)
+
+var sqlOperators = map[Operator]string{
+	Eq:  "=",
+	Ne:  "<>",
+	Gt:  ">",
+	Gte: ">=",
+	Lt:  "<",
+	Lte: "<=",
+}
+
+type Type int
+
+const (
+	String Type = iota
+	Int
+	Bool
+	// Time accepts RFC 3339 timestamps and dates like 2024-01-01, which mean midnight UTC.
+	Time
+)
+
+// Field is a filterable field, Operators lists the operators it allows.
+type Field struct {
+	Column    string
+	Type      Type
+	Operators []Operator
+}
+
+// Schema maps the names clients filter by to their fields.
+type Schema map[string]Field
+
+// Condition is a single parsed filter, Value has the field's type, or is a slice of it for In.
+type Condition struct {
+	Column   string
+	Operator Operator
+	Value    any
+}
+
+var filterParam = regexp.MustCompile(`^filter\[([a-z_]+)\](?:\[([a-z]+)\])?$`)
+
+// Parse reads the filter parameters of the query, an expression without an operator, like filter[status]=published,
+// uses Eq. Other parameters are ignored. The conditions are sorted by field and operator, so
+// the same filters always produce the same SQL.
+func Parse(query url.Values, schema Schema) ([]Condition, error) {
+	var conditions []Condition
+
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if key != "filter" && !strings.HasPrefix(key, "filter[") {
+			continue
+		}
+
+		match := filterParam.FindStringSubmatch(key)
+		if match == nil {
+			return nil, fmt.Errorf("%s isn't a valid filter, filters look like filter[field] or filter[field][operator]", key)
+		}
+
+		name, operator := match[1], Operator(match[2])
+		if operator == "" {
+			operator = Eq
+		}
+
+		field, ok := schema[name]
+		if !ok {
+			return nil, fmt.Errorf("can't filter by %s, filterable fields are: %s", name, strings.Join(schema.names(), ", "))
+		}
+
+		if !field.allows(operator) {
+			return nil, fmt.Errorf("%s can't be filtered with %s", name, operator)
+		}
+
+		value, err := field.parse(operator, query.Get(key))
+		if err != nil {
+			return nil, fmt.Errorf("filter[%s] %w", name, err)
+		}
+
+		conditions = append(conditions, Condition{Column: field.Column, Operator: operator, Value: value})
+	}
+
+	return conditions, nil
+}
+
+// SQL joins the conditions with AND, each one prefixed with AND so it can be appended to an existing WHERE clause.
+// The placeholders are numbered from first, the returned arguments belong to them in order.
+func SQL(conditions []Condition, first int) (string, []any) {
+	var b strings.Builder
+	var args []any
+
+	for _, condition := range conditions {
+		placeholder := first + len(args)
+
+		if condition.Operator == In {
+			fmt.Fprintf(&b, " AND %s = ANY($%d)", condition.Column, placeholder)
+			args = append(args, pq.Array(condition.Value))
+			continue
+		}
+
+		fmt.Fprintf(&b, " AND %s %s $%d", condition.Column, sqlOperators[condition.Operator], placeholder)
+		args = append(args, condition.Value)
+	}
+
+	return b.String(), args
+}
+
+func (s Schema) names() []string {
+	names := make([]string, 0, len(s))
+	for name := range s {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (f Field) allows(operator Operator) bool {
+	for _, o := range f.Operators {
+		if o == operator {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f Field) parse(operator Operator, value string) (any, error) {
+	if operator != In {
+		return f.parseValue(value)
+	}
+
+	switch f.Type {
+	case String:
+		return strings.Split(value, ","), nil
+	case Int:
+		var values []int64
+		for _, v := range strings.Split(value, ",") {
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("must be a comma separated list of integers")
+			}
+
+			values = append(values, n)
+		}
+
+		return values, nil
+	default:
+		panic(fmt.Sprintf("filter: %s isn't supported for column %s", In, f.Column))
+	}
+}
+
+func (f Field) parseValue(value string) (any, error) {
+	switch f.Type {
+	case Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be an integer")
+		}
+
+		return n, nil
+	case Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("must be true or false")
+		}
+
+		return b, nil
+	case Time:
+		t, err := time.Parse(time.RFC3339, value)
+		if err == nil {
+			return t, nil
+		}
+
+		t, err = time.Parse("2006-01-02", value)
+		if err != nil {
+			return nil, fmt.Errorf("must be an RFC 3339 timestamp or a date like 2024-01-01")
+		}
+
+		return t, nil
+	default:
+		return value, nil
+	}
+}
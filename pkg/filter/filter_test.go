@@ -0,0 +1,183 @@
+package filter
+
+import (
+	"github.com/lib/pq"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSchema = Schema{
+	"status":     {Column: "status", Type: String, Operators: []Operator{Eq, Ne, In}},
+	"views":      {Column: "views", Type: Int, Operators: []Operator{Eq, Ne, Gt, Gte, Lt, Lte, In}},
+	"featured":   {Column: "featured", Type: Bool, Operators: []Operator{Eq}},
+	"created_at": {Column: "created_at", Type: Time, Operators: []Operator{Gt, Gte, Lt, Lte}},
+}
+
+func TestParse(t *testing.T) {
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	timestamp := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query string
+		want  []Condition
+	}{
+		{"no filters", "page=1&limit=10", nil},
+		{"eq without operator", "filter[status]=published", []Condition{{"status", Eq, "published"}}},
+		{"eq", "filter[status][eq]=published", []Condition{{"status", Eq, "published"}}},
+		{"ne", "filter[status][ne]=held", []Condition{{"status", Ne, "held"}}},
+		{"gt", "filter[views][gt]=10", []Condition{{"views", Gt, 10}}},
+		{"gte", "filter[views][gte]=10", []Condition{{"views", Gte, 10}}},
+		{"lt", "filter[views][lt]=10", []Condition{{"views", Lt, 10}}},
+		{"lte", "filter[views][lte]=10", []Condition{{"views", Lte, 10}}},
+		{"in strings", "filter[status][in]=published,held", []Condition{{"status", In, []string{"published", "held"}}}},
+		{"in integers", "filter[views][in]=1,2,3", []Condition{{"views", In, []int64{1, 2, 3}}}},
+		{"bool", "filter[featured]=true", []Condition{{"featured", Eq, true}}},
+		{"date", "filter[created_at][gte]=2024-01-01", []Condition{{"created_at", Gte, date}}},
+		{"timestamp", "filter[created_at][lt]=2024-01-02T03:04:05Z", []Condition{{"created_at", Lt, timestamp}}},
+		{
+			"sorted by field and operator",
+			"filter[views][lt]=20&filter[status]=published&filter[views][gt]=10",
+			[]Condition{{"status", Eq, "published"}, {"views", Gt, 10}, {"views", Lt, 20}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := Parse(query, testSchema)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidFilters(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"unknown field", "filter[author]=someone", "can't filter by author"},
+		{"unknown operator", "filter[views][like]=10", "views can't be filtered with like"},
+		{"operator the field doesn't allow", "filter[status][gt]=published", "status can't be filtered with gt"},
+		{"malformed key", "filter[status", "isn't a valid filter"},
+		{"field with uppercase letters", "filter[Status]=published", "isn't a valid filter"},
+		{"nested operator", "filter[views][gt][eq]=10", "isn't a valid filter"},
+		{"bare filter", "filter=published", "isn't a valid filter"},
+		{"integer", "filter[views][gt]=ten", "filter[views] must be an integer"},
+		{"integer list", "filter[views][in]=1,two", "filter[views] must be a comma separated list of integers"},
+		{"bool", "filter[featured]=yes please", "filter[featured] must be true or false"},
+		{"time", "filter[created_at][gt]=yesterday", "filter[created_at] must be an RFC 3339 timestamp"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = Parse(query, testSchema)
+			if err == nil {
+				t.Fatal("Parse() returned no error")
+			}
+
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse() error = %q, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSQL(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []Condition
+		first      int
+		wantSQL    string
+		wantArgs   []any
+	}{
+		{"no conditions", nil, 1, "", nil},
+		{"eq", []Condition{{"status", Eq, "published"}}, 1, " AND status = $1", []any{"published"}},
+		{"ne", []Condition{{"status", Ne, "held"}}, 1, " AND status <> $1", []any{"held"}},
+		{"gt", []Condition{{"views", Gt, 10}}, 1, " AND views > $1", []any{10}},
+		{"gte", []Condition{{"views", Gte, 10}}, 1, " AND views >= $1", []any{10}},
+		{"lt", []Condition{{"views", Lt, 10}}, 1, " AND views < $1", []any{10}},
+		{"lte", []Condition{{"views", Lte, 10}}, 1, " AND views <= $1", []any{10}},
+		{
+			"in",
+			[]Condition{{"status", In, []string{"published", "held"}}},
+			1,
+			" AND status = ANY($1)",
+			[]any{pq.Array([]string{"published", "held"})},
+		},
+		{
+			"numbered from first",
+			[]Condition{{"status", Eq, "published"}, {"views", In, []int64{1, 2}}, {"views", Lt, 20}},
+			9,
+			" AND status = $9 AND views = ANY($10) AND views < $11",
+			[]any{"published", pq.Array([]int64{1, 2}), 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sql, args := SQL(tt.conditions, tt.first)
+			if sql != tt.wantSQL {
+				t.Errorf("SQL() = %q, want %q", sql, tt.wantSQL)
+			}
+
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("SQL() args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestValuesAreNeverInterpolated(t *testing.T) {
+	injections := []string{
+		"published' OR '1'='1",
+		"x; DROP TABLE post; --",
+		"$1",
+		"published') OR true --",
+	}
+
+	for _, injection := range injections {
+		t.Run(injection, func(t *testing.T) {
+			query := url.Values{
+				"filter[status]":     {injection},
+				"filter[status][in]": {injection + ",held"},
+			}
+
+			conditions, err := Parse(query, testSchema)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sql, args := SQL(conditions, 1)
+
+			want := " AND status = $1 AND status = ANY($2)"
+			if sql != want {
+				t.Errorf("SQL() = %q, want %q", sql, want)
+			}
+
+			wantArgs := []any{injection, pq.Array([]string{injection, "held"})}
+			if !reflect.DeepEqual(args, wantArgs) {
+				t.Errorf("SQL() args = %#v, want %#v", args, wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Package i18n translates the API's messages into the language a client asks for with Accept-Language. The catalogs
+// are embedded into the binary and keyed by the English message, anything without a translation stays in English.
+package i18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultLanguage is the language the messages are written in.
+const DefaultLanguage = "en"
+
+//go:embed locales/*.json
+var localeFS embed.FS
+
+// Catalog holds the translations of every supported language.
+type Catalog struct {
+	languages map[string]map[string]string
+}
+
+// Load reads the embedded catalogs, the language of a catalog is its file name, e.g. de.json for German.
+func Load() (*Catalog, error) {
+	files, err := localeFS.ReadDir("locales")
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := &Catalog{languages: map[string]map[string]string{}}
+
+	for _, file := range files {
+		data, err := localeFS.ReadFile(path.Join("locales", file.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		var messages map[string]string
+		err = json.Unmarshal(data, &messages)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't parse %s: %w", file.Name(), err)
+		}
+
+		catalog.languages[strings.ToLower(strings.TrimSuffix(file.Name(), path.Ext(file.Name())))] = messages
+	}
+
+	return catalog, nil
+}
+
+// Negotiate returns the translator for the most preferred language in the Accept-Language header which has a
+// catalog. Regional tags fall back to their base language, so de-AT is served in German. If none of them are
+// supported, the messages are returned in English.
+func (c *Catalog) Negotiate(acceptLanguage string) Translator {
+	if c == nil {
+		return Translator{Language: DefaultLanguage}
+	}
+
+	for _, tag := range parseAcceptLanguage(acceptLanguage) {
+		base, _, _ := strings.Cut(tag, "-")
+
+		for _, language := range []string{tag, base} {
+			if language == DefaultLanguage {
+				return Translator{Language: DefaultLanguage}
+			}
+
+			if messages, ok := c.languages[language]; ok {
+				return Translator{Language: language, messages: messages}
+			}
+		}
+	}
+
+	return Translator{Language: DefaultLanguage}
+}
+
+// Languages returns the supported languages in alphabetical order, English included.
+func (c *Catalog) Languages() []string {
+	languages := []string{DefaultLanguage}
+	if c != nil {
+		for language := range c.languages {
+			languages = append(languages, language)
+		}
+	}
+
+	sort.Strings(languages)
+
+	return languages
+}
+
+// parseAcceptLanguage returns the lowercased language tags in the header, most preferred first. Tags with a
+// q value of 0 and the * wildcard are left out.
+func parseAcceptLanguage(acceptLanguage string) []string {
+	type weightedTag struct {
+		tag string
+		q   float64
+	}
+
+	var tags []weightedTag
+
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(fields[0]))
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, _ = strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			}
+		}
+
+		if tag == "" || tag == "*" || q <= 0 {
+			continue
+		}
+
+		tags = append(tags, weightedTag{tag: tag, q: q})
+	}
+
+	// stable, so tags with the same q value keep the client's order
+	sort.SliceStable(tags, func(i, j int) bool {
+		return tags[i].q > tags[j].q
+	})
+
+	result := make([]string, len(tags))
+	for i, t := range tags {
+		result[i] = t.tag
+	}
+
+	return result
+}
+
+// Translator translates messages into a single language. The zero value returns them unchanged.
+type Translator struct {
+	Language string
+	messages map[string]string
+}
+
+// Translate returns the translation of the English message, or the message itself if there is none.
+func (t Translator) Translate(message string) string {
+	if translation, ok := t.messages[message]; ok && translation != "" {
+		return translation
+	}
+
+	return message
+}
+
+// Sprintf translates format before formatting it, so the arguments aren't part of the catalog's keys.
+func (t Translator) Sprintf(format string, args ...any) string {
+	return fmt.Sprintf(t.Translate(format), args...)
+}
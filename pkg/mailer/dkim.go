@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"github.com/emersion/go-msgauth/dkim"
+)
+
+// DKIM signs messages, so receivers can check they were sent by the domain. The public key has to be published in the
+// TXT record <selector>._domainkey.<domain>.
+type DKIM struct {
+	options *dkim.SignOptions
+}
+
+// NewDKIM returns a DKIM signer with the PEM encoded private key, which is an RSA key in PKCS #1 or PKCS #8 form or an
+// Ed25519 key in PKCS #8 form.
+func NewDKIM(domain, selector string, privateKey []byte) (*DKIM, error) {
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("dkim private key isn't PEM encoded")
+	}
+
+	var signer crypto.Signer
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err == nil {
+		var ok bool
+		signer, ok = key.(crypto.Signer)
+		if !ok {
+			return nil, errors.New("dkim private key must be an RSA or Ed25519 key")
+		}
+	} else {
+		signer, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, errors.New("dkim private key must be an RSA or Ed25519 key")
+		}
+	}
+
+	return &DKIM{options: &dkim.SignOptions{
+		Domain:   domain,
+		Selector: selector,
+		Signer:   signer,
+	}}, nil
+}
+
+// sign returns the message with a DKIM-Signature header, message has to use CRLF line endings.
+func (d *DKIM) sign(message []byte) (*bytes.Buffer, error) {
+	signed := new(bytes.Buffer)
+
+	err := dkim.Sign(signed, bytes.NewReader(message), d.options)
+	if err != nil {
+		return nil, err
+	}
+
+	return signed, nil
+}
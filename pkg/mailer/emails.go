@@ -0,0 +1,100 @@
+package mailer
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	SecurityEventPasswordChanged    = "password_changed"
+	SecurityEventPasswordReset      = "password_reset"
+	SecurityEventMfaEnabled         = "mfa_enabled"
+	SecurityEventMfaDisabled        = "mfa_disabled"
+	SecurityEventNewLogin           = "new_login"
+	SecurityEventAccountDeactivated = "account_deactivated"
+)
+
+// Email is the data of one of the templates in templates/, which it names. Emails are stored in the job queue as
+// JSON, so the JSON names of their fields can't change without breaking emails which are still queued.
+type Email interface {
+	Template() string
+}
+
+type Welcome struct {
+	Username string `json:"Username"`
+}
+
+func (Welcome) Template() string { return "welcome_user.tmpl" }
+
+type Verification struct {
+	Username string `json:"username"`
+	Token    string `json:"verificationToken"`
+}
+
+func (Verification) Template() string { return "verify_email.tmpl" }
+
+// VerificationReminder is sent to users who haven't verified their address some time after registering, with a new
+// verification token. DeleteAt is when the account is deleted if it's still unverified, nil if it's kept.
+type VerificationReminder struct {
+	Username string     `json:"username"`
+	Token    string     `json:"verificationToken"`
+	DeleteAt *time.Time `json:"deleteAt,omitempty"`
+}
+
+func (VerificationReminder) Template() string { return "verification_reminder.tmpl" }
+
+type PasswordReset struct {
+	Username string `json:"username"`
+	Token    string `json:"passwordResetToken"`
+}
+
+func (PasswordReset) Template() string { return "password_reset.tmpl" }
+
+type Mention struct {
+	Username  string `json:"username"`
+	Author    string `json:"author"`
+	PostID    string `json:"postId"`
+	PostTitle string `json:"postTitle"`
+}
+
+func (Mention) Template() string { return "mention.tmpl" }
+
+// SecurityAlert tells the user about a change to their account, Event is one of the SecurityEvent constants.
+type SecurityAlert struct {
+	Username  string    `json:"username"`
+	Event     string    `json:"event"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"user_agent"`
+	Time      time.Time `json:"time"`
+}
+
+func (SecurityAlert) Template() string { return "security_alert.tmpl" }
+
+// Decode unmarshals the data of the named template.
+func Decode(template string, data []byte) (Email, error) {
+	var email Email
+	switch template {
+	case Welcome{}.Template():
+		email = &Welcome{}
+	case Verification{}.Template():
+		email = &Verification{}
+	case VerificationReminder{}.Template():
+		email = &VerificationReminder{}
+	case PasswordReset{}.Template():
+		email = &PasswordReset{}
+	case Mention{}.Template():
+		email = &Mention{}
+	case SecurityAlert{}.Template():
+		email = &SecurityAlert{}
+	default:
+		return nil, fmt.Errorf("mailer: unknown template %q", template)
+	}
+
+	err := json.Unmarshal(data, email)
+	if err != nil {
+		return nil, err
+	}
+
+	return email, nil
+}
@@ -0,0 +1,153 @@
+package mailer
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+const (
+	EventBounce    = "bounce"
+	EventComplaint = "complaint"
+)
+
+// Event is a permanent bounce or a spam complaint reported by the email provider, addresses with either of them
+// shouldn't be emailed again.
+type Event struct {
+	Recipient string
+	Type      string
+}
+
+// ParseSendGridEvents returns the bounces and complaints in a batch of the SendGrid event webhook, other events are
+// skipped. Blocked messages are left out as well, since they're usually temporary.
+func ParseSendGridEvents(body []byte) ([]Event, error) {
+	var payload []struct {
+		Email string `json:"email"`
+		Event string `json:"event"`
+		Type  string `json:"type"`
+	}
+
+	err := json.Unmarshal(body, &payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	for _, event := range payload {
+		switch {
+		case event.Event == "bounce" && event.Type != "blocked":
+			events = append(events, Event{Recipient: event.Email, Type: EventBounce})
+		case event.Event == "spamreport":
+			events = append(events, Event{Recipient: event.Email, Type: EventComplaint})
+		}
+	}
+
+	return events, nil
+}
+
+// ParseMailgunEvent returns the permanent failure or complaint of a Mailgun webhook request, if it is one.
+func ParseMailgunEvent(body []byte) ([]Event, error) {
+	var payload struct {
+		EventData struct {
+			Event     string `json:"event"`
+			Severity  string `json:"severity"`
+			Recipient string `json:"recipient"`
+		} `json:"event-data"`
+	}
+
+	err := json.Unmarshal(body, &payload)
+	if err != nil {
+		return nil, err
+	}
+
+	event := payload.EventData
+	switch {
+	case event.Event == "failed" && event.Severity == "permanent":
+		return []Event{{Recipient: event.Recipient, Type: EventBounce}}, nil
+	case event.Event == "complained":
+		return []Event{{Recipient: event.Recipient, Type: EventComplaint}}, nil
+	default:
+		return nil, nil
+	}
+}
+
+// SNSMessage is a message Amazon SNS posts to HTTPS subscriptions, SES publishes its notifications through SNS.
+type SNSMessage struct {
+	Type         string
+	Message      string
+	SubscribeURL string
+}
+
+const SNSSubscriptionConfirmation = "SubscriptionConfirmation"
+
+// ParseSNSMessage decodes the message, the subscribe URL of subscription confirmations has to point to SNS.
+func ParseSNSMessage(body []byte) (SNSMessage, error) {
+	var message SNSMessage
+
+	err := json.Unmarshal(body, &message)
+	if err != nil {
+		return SNSMessage{}, err
+	}
+
+	if message.Type == SNSSubscriptionConfirmation {
+		subscribeURL, err := url.Parse(message.SubscribeURL)
+		if err != nil {
+			return SNSMessage{}, err
+		}
+
+		if subscribeURL.Scheme != "https" || !strings.HasPrefix(subscribeURL.Hostname(), "sns.") || !strings.HasSuffix(subscribeURL.Hostname(), ".amazonaws.com") {
+			return SNSMessage{}, errors.New("subscribe url doesn't point to sns")
+		}
+	}
+
+	return message, nil
+}
+
+// ParseSESNotification returns the permanent bounces and complaints of an SES notification, which is the Message of
+// an SNS notification. Both SES notifications and SES event publishing are supported.
+func ParseSESNotification(message string) ([]Event, error) {
+	var notification struct {
+		NotificationType string `json:"notificationType"`
+		EventType        string `json:"eventType"`
+		Bounce           struct {
+			BounceType        string `json:"bounceType"`
+			BouncedRecipients []struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"bouncedRecipients"`
+		} `json:"bounce"`
+		Complaint struct {
+			ComplainedRecipients []struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"complainedRecipients"`
+		} `json:"complaint"`
+	}
+
+	err := json.Unmarshal([]byte(message), &notification)
+	if err != nil {
+		return nil, err
+	}
+
+	notificationType := notification.NotificationType
+	if notificationType == "" {
+		notificationType = notification.EventType
+	}
+
+	var events []Event
+	switch notificationType {
+	case "Bounce":
+		if notification.Bounce.BounceType != "Permanent" {
+			return nil, nil
+		}
+
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			events = append(events, Event{Recipient: recipient.EmailAddress, Type: EventBounce})
+		}
+	case "Complaint":
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			events = append(events, Event{Recipient: recipient.EmailAddress, Type: EventComplaint})
+		}
+	}
+
+	return events, nil
+}
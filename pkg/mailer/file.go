@@ -0,0 +1,50 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var unsafeFileCharacters = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+// File writes messages to a directory as .eml files instead of sending them, so emails can be read without an email
+// provider during development.
+type File struct {
+	dir string
+}
+
+// NewFile returns a File sender writing to dir, which is created if it doesn't exist.
+func NewFile(dir string) (*File, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{dir: dir}, nil
+}
+
+func (f *File) Send(ctx context.Context, message Message) error {
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().UTC().Format("20060102T150405.000000000"), unsafeFileCharacters.ReplaceAllString(message.To, "_"))
+
+	file, err := os.Create(filepath.Join(f.dir, name))
+	if err != nil {
+		return err
+	}
+
+	_, err = newMailMessage(message).WriteTo(file)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
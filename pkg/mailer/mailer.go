@@ -2,65 +2,127 @@ package mailer
 
 import (
 	"bytes"
+	"context"
 	"embed"
-	"github.com/go-mail/mail/v2"
-	"html/template"
-	"time"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
 )
 
+// The email providers, which are selected with MAIL_PROVIDER.
+const (
+	ProviderSMTP     = "smtp"
+	ProviderSendGrid = "sendgrid"
+	ProviderMailgun  = "mailgun"
+	ProviderSES      = "ses"
+)
+
+// DefaultLanguage is the language of the templates in templates/, which are used for every language without
+// templates of its own.
+const DefaultLanguage = "en"
+
 //go:embed "templates"
 var templateFS embed.FS
 
-type Mailer struct {
-	dialer *mail.Dialer
-	sender string
+// Message is a rendered email.
+type Message struct {
+	From      string
+	To        string
+	Subject   string
+	PlainBody string
+	HTMLBody  string
 }
 
-func New(host string, port int, username, password, sender string) *Mailer {
-	dialer := mail.NewDialer(host, port, username, password)
-	dialer.Timeout = 5 * time.Second
+// Sender delivers messages through an email provider. Failed messages are retried by the job queue, so
+// implementations shouldn't retry them on their own.
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+type Mailer struct {
+	sender Sender
+	from   string
+}
 
+func New(sender Sender, from string) *Mailer {
 	return &Mailer{
-		dialer: dialer,
 		sender: sender,
+		from:   from,
 	}
 }
 
-func (m *Mailer) Send(recipient, templateFile string, data any) error {
-	tmpl, err := template.New("email").ParseFS(templateFS, "templates/"+templateFile)
+// Send renders the email in the language and hands it over to the sender.
+func (m *Mailer) Send(ctx context.Context, recipient, language string, email Email) error {
+	subject, plainBody, htmlBody, err := render(email, language)
 	if err != nil {
 		return err
 	}
 
+	return m.sender.Send(ctx, Message{
+		From:      m.from,
+		To:        recipient,
+		Subject:   subject,
+		PlainBody: plainBody,
+		HTMLBody:  htmlBody,
+	})
+}
+
+// templatePath returns the path of the email's template in the language. Translations are kept in a directory named
+// after the language, e.g. templates/de/ for German. Regional tags fall back to their base language and languages
+// without a translation of the template to templates/ itself, which is in English.
+func templatePath(email Email, language string) string {
+	language = strings.ToLower(language)
+	base, _, _ := strings.Cut(language, "-")
+
+	for _, dir := range []string{language, base} {
+		if dir == "" || dir == DefaultLanguage {
+			continue
+		}
+
+		name := path.Join("templates", dir, email.Template())
+		if _, err := fs.Stat(templateFS, name); err == nil {
+			return name
+		}
+	}
+
+	return path.Join("templates", email.Template())
+}
+
+// render executes the subject, plainBody and content templates of the email in the language. The subject and plain
+// text body are rendered with text/template, so they aren't HTML escaped, and the content is embedded in
+// templates/layout.tmpl, which is shared by every language.
+func render(email Email, language string) (string, string, string, error) {
+	name := templatePath(email, language)
+
+	textTmpl, err := texttemplate.New("email").ParseFS(templateFS, name)
+	if err != nil {
+		return "", "", "", err
+	}
+
 	subject := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(subject, "subject", data)
+	err = textTmpl.ExecuteTemplate(subject, "subject", email)
 	if err != nil {
-		return err
+		return "", "", "", err
 	}
 
 	plainBody := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(plainBody, "plainBody", data)
+	err = textTmpl.ExecuteTemplate(plainBody, "plainBody", email)
 	if err != nil {
-		return err
+		return "", "", "", err
 	}
 
-	htmlBody := new(bytes.Buffer)
-	err = tmpl.ExecuteTemplate(htmlBody, "htmlBody", data)
+	htmlTmpl, err := htmltemplate.New("email").ParseFS(templateFS, "templates/layout.tmpl", name)
 	if err != nil {
-		return err
+		return "", "", "", err
 	}
 
-	msg := mail.NewMessage()
-	msg.SetHeader("To", recipient)
-	msg.SetHeader("From", m.sender)
-	msg.SetHeader("Subject", subject.String())
-	msg.SetBody("text/plain", plainBody.String())
-	msg.AddAlternative("text/html", htmlBody.String())
-
-	err = m.dialer.DialAndSend(msg)
+	htmlBody := new(bytes.Buffer)
+	err = htmlTmpl.ExecuteTemplate(htmlBody, "htmlBody", email)
 	if err != nil {
-		return err
+		return "", "", "", err
 	}
 
-	return nil
+	return subject.String(), plainBody.String(), htmlBody.String(), nil
 }
@@ -0,0 +1,54 @@
+package mailer
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Mailgun sends messages with the Mailgun messages API.
+type Mailgun struct {
+	domain  string
+	apiKey  string
+	baseURL string
+	client  *http.Client
+}
+
+// NewMailgun returns a Mailgun sender for the domain, baseURL is https://api.mailgun.net unless the domain is in the
+// EU region, which uses https://api.eu.mailgun.net.
+func NewMailgun(domain, apiKey, baseURL string, timeout time.Duration) *Mailgun {
+	return &Mailgun{
+		domain:  domain,
+		apiKey:  apiKey,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (m *Mailgun) Send(ctx context.Context, msg Message) error {
+	form := url.Values{
+		"from":    {msg.From},
+		"to":      {msg.To},
+		"subject": {msg.Subject},
+		"text":    {msg.PlainBody},
+		"html":    {msg.HTMLBody},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, m.baseURL+"/v3/"+url.PathEscape(m.domain)+"/messages", strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+
+	req.SetBasicAuth("api", m.apiKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse("mailgun", resp)
+}
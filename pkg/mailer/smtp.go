@@ -0,0 +1,93 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-mail/mail/v2"
+	netmail "net/mail"
+	"time"
+)
+
+// SMTP sends messages through an SMTP server.
+type SMTP struct {
+	dialer *mail.Dialer
+	dkim   *DKIM
+}
+
+// NewSMTP returns an SMTP sender, messages are signed with dkim unless it's nil.
+func NewSMTP(host string, port int, username, password string, timeout time.Duration, dkim *DKIM) *SMTP {
+	dialer := mail.NewDialer(host, port, username, password)
+	dialer.Timeout = timeout
+	// failed emails are retried by the job queue
+	dialer.RetryFailure = false
+
+	return &SMTP{dialer: dialer, dkim: dkim}
+}
+
+// Send sends the message. The SMTP timeout is shortened to the context's deadline, so the call doesn't outlive
+// the request or job it was made for.
+func (s *SMTP) Send(ctx context.Context, message Message) error {
+	msg := newMailMessage(message)
+
+	dialer := *s.dialer
+	if deadline, ok := ctx.Deadline(); ok {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return context.DeadlineExceeded
+		}
+
+		if dialer.Timeout == 0 || remaining < dialer.Timeout {
+			dialer.Timeout = remaining
+		}
+	}
+
+	err := ctx.Err()
+	if err != nil {
+		return err
+	}
+
+	if s.dkim == nil {
+		return dialer.DialAndSend(msg)
+	}
+
+	return s.sendSigned(&dialer, message, msg)
+}
+
+// sendSigned signs the message with DKIM before sending it, go-mail can't sign the messages it writes itself.
+func (s *SMTP) sendSigned(dialer *mail.Dialer, message Message, msg *mail.Message) error {
+	from, err := netmail.ParseAddress(message.From)
+	if err != nil {
+		return err
+	}
+
+	raw := new(bytes.Buffer)
+	_, err = msg.WriteTo(raw)
+	if err != nil {
+		return err
+	}
+
+	signed, err := s.dkim.sign(raw.Bytes())
+	if err != nil {
+		return err
+	}
+
+	sender, err := dialer.Dial()
+	if err != nil {
+		return err
+	}
+	defer sender.Close()
+
+	return sender.Send(from.Address, []string{message.To}, signed)
+}
+
+// newMailMessage builds the MIME message with the plain text body and the HTML body as its alternative.
+func newMailMessage(message Message) *mail.Message {
+	msg := mail.NewMessage()
+	msg.SetHeader("To", message.To)
+	msg.SetHeader("From", message.From)
+	msg.SetHeader("Subject", message.Subject)
+	msg.SetBody("text/plain", message.PlainBody)
+	msg.AddAlternative("text/html", message.HTMLBody)
+
+	return msg
+}
@@ -0,0 +1,214 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"go.uber.org/zap"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+const (
+	minBackoff = 10 * time.Second
+	maxBackoff = time.Hour
+)
+
+// HandlerFunc runs a single job, returning an error schedules the job to be retried. The context
+// is cancelled once the job runs out of time, external calls made by the handler should use it.
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+type Options struct {
+	Workers      int
+	PollInterval time.Duration
+	MaxAttempts  int
+	// JobTimeout is how long a single attempt of a job may run, it should be shorter than StaleAfter.
+	JobTimeout time.Duration
+	// StaleAfter is how long a job may stay running before it's assumed that its worker died.
+	StaleAfter time.Duration
+	// Retention is how long completed jobs are kept around for inspection.
+	Retention time.Duration
+}
+
+// Queue is a job queue backed by the job table, so jobs survive restarts and are
+// shared between every instance of the server.
+type Queue struct {
+	repository *repository.JobRepository
+	logger     *zap.Logger
+	options    Options
+
+	mu       sync.RWMutex
+	handlers map[string]HandlerFunc
+}
+
+func New(repository *repository.JobRepository, logger *zap.Logger, options Options) *Queue {
+	return &Queue{
+		repository: repository,
+		logger:     logger,
+		options:    options,
+		handlers:   map[string]HandlerFunc{},
+	}
+}
+
+// Register sets the handler for a job type, it should be called before Start.
+func (q *Queue) Register(jobType string, handler HandlerFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.handlers[jobType] = handler
+}
+
+func (q *Queue) handler(jobType string) (HandlerFunc, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	handler, ok := q.handlers[jobType]
+	return handler, ok
+}
+
+// Enqueue adds a job which should be run as soon as a worker is free. Jobs enqueued inside a
+// transaction are only picked up by workers once it commits, and are discarded if it rolls back.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload any) (repository.Job, error) {
+	return q.EnqueueAt(ctx, jobType, payload, time.Now())
+}
+
+// EnqueueAt adds a job which shouldn't be run before runAt.
+func (q *Queue) EnqueueAt(ctx context.Context, jobType string, payload any, runAt time.Time) (repository.Job, error) {
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return repository.Job{}, fmt.Errorf("couldn't encode payload of %s job: %w", jobType, err)
+	}
+
+	return q.repository.InsertJob(ctx, repository.Job{
+		Type:        jobType,
+		Payload:     encoded,
+		MaxAttempts: q.options.MaxAttempts,
+		RunAt:       runAt,
+	})
+}
+
+// Start starts the workers and the maintenance loop in the background.
+func (q *Queue) Start() {
+	for i := 0; i < q.options.Workers; i++ {
+		go q.work(i)
+	}
+
+	go q.maintain()
+
+	q.logger.Info("job queue started", zap.Int("workers", q.options.Workers))
+}
+
+func (q *Queue) work(worker int) {
+	logger := q.logger.With(zap.Int("worker", worker))
+
+	for {
+		job, err := q.repository.ClaimJob(context.Background())
+		if err != nil {
+			if !errors.Is(err, repository.ErrNoJobs) {
+				logger.Error("couldn't claim job", zap.Error(err))
+			}
+
+			time.Sleep(q.options.PollInterval)
+			continue
+		}
+
+		q.run(logger.With(zap.Int("jobId", job.ID), zap.String("jobType", job.Type)), job)
+	}
+}
+
+func (q *Queue) run(logger *zap.Logger, job repository.Job) {
+	handler, ok := q.handler(job.Type)
+	if !ok {
+		logger.Error("no handler registered for job type")
+		q.kill(logger, job, "no handler registered for job type")
+		return
+	}
+
+	started := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), q.options.JobTimeout)
+	defer cancel()
+
+	err := runHandler(ctx, handler, job.Payload)
+	if err == nil {
+		logger.Debug("job completed", zap.Duration("duration", time.Since(started)))
+
+		err = q.repository.CompleteJob(context.Background(), job.ID)
+		if err != nil {
+			logger.Error("couldn't mark job as completed", zap.Error(err))
+		}
+		return
+	}
+
+	if job.Attempts >= job.MaxAttempts {
+		logger.Error("job failed for the last time", zap.Error(err), zap.Int("attempts", job.Attempts))
+		q.kill(logger, job, err.Error())
+		return
+	}
+
+	retryAt := time.Now().Add(Backoff(job.Attempts))
+	logger.Warn("job failed, retrying later", zap.Error(err), zap.Int("attempts", job.Attempts), zap.Time("retryAt", retryAt))
+
+	err = q.repository.RetryJobAt(context.Background(), job.ID, err.Error(), retryAt)
+	if err != nil {
+		logger.Error("couldn't reschedule job", zap.Error(err))
+	}
+}
+
+func (q *Queue) kill(logger *zap.Logger, job repository.Job, reason string) {
+	err := q.repository.KillJob(context.Background(), job.ID, reason)
+	if err != nil {
+		logger.Error("couldn't move job to the dead letter state", zap.Error(err))
+	}
+}
+
+// runHandler turns a panicking handler into a failed job instead of taking the worker down with it.
+func runHandler(ctx context.Context, handler HandlerFunc, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler(ctx, payload)
+}
+
+// maintain requeues jobs of dead workers and deletes old completed jobs.
+func (q *Queue) maintain() {
+	ticker := time.NewTicker(q.options.StaleAfter)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		requeued, err := q.repository.RequeueStaleJobs(context.Background(), q.options.StaleAfter)
+		if err != nil {
+			q.logger.Error("couldn't requeue stale jobs", zap.Error(err))
+		} else if requeued > 0 {
+			q.logger.Warn("requeued stale jobs", zap.Int("count", requeued))
+		}
+
+		deleted, err := q.repository.DeleteCompletedJobs(context.Background(), time.Now().Add(-q.options.Retention))
+		if err != nil {
+			q.logger.Error("couldn't delete completed jobs", zap.Error(err))
+		} else if deleted > 0 {
+			q.logger.Debug("deleted completed jobs", zap.Int("count", deleted))
+		}
+	}
+}
+
+// Backoff returns how long to wait before retrying a job which failed the given number of times.
+// The delay doubles with every attempt and is jittered so failed jobs don't retry in lockstep.
+func Backoff(attempts int) time.Duration {
+	delay := maxBackoff
+	if attempts < 20 {
+		delay = minBackoff << attempts
+	}
+
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay/2 + time.Duration(rand.Int63n(int64(delay/2)))
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type AuditLogRepository struct {
+	db *DB
+}
+
+type AuditLogEntry struct {
+	ID         int
+	ActorID    *int `db:"actor_id"`
+	Action     string
+	TargetType string `db:"target_type"`
+	TargetID   int    `db:"target_id"`
+	Before     *json.RawMessage
+	After      *json.RawMessage
+	RequestID  string `db:"request_id"`
+	// Reason is the justification given by the actor, it's empty for actions which don't require one.
+	Reason    string
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type AuditLogFilter struct {
+	ActorID    int
+	Action     string
+	TargetType string
+	TargetID   int
+}
+
+func NewAuditLogRepository(db *DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) InsertEntry(ctx context.Context, entry AuditLogEntry) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO audit_log (actor_id, action, target_type, target_id, before, after, request_id, reason) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)",
+		entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, entry.Before, entry.After, entry.RequestID, entry.Reason)
+	return handleError(err)
+}
+
+// FindEntries returns the newest entries first, zero values in the filter are ignored.
+func (r *AuditLogRepository) FindEntries(ctx context.Context, filter AuditLogFilter, page, limit int) ([]AuditLogEntry, error) {
+	var entries []AuditLogEntry
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT * FROM audit_log
+		WHERE ($1 = 0 OR actor_id = $1)
+		AND ($2 = '' OR action = $2)
+		AND ($3 = '' OR target_type = $3)
+		AND ($4 = 0 OR target_id = $4)
+		ORDER BY created_at DESC, id DESC LIMIT $5 OFFSET $6`
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &entries, query, filter.ActorID, filter.Action, filter.TargetType, filter.TargetID, limit, calculateOffset(page, limit))
+	if err != nil {
+		return nil, handleError(err)
+	}
+
+	return entries, nil
+}
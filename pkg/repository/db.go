@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DB routes queries to the primary database or, for reads which can tolerate replication lag, to a read replica.
+// Reads fall back to the primary while the replica is unavailable or when no replica is configured.
+type DB struct {
+	primary *sqlx.DB
+	replica *sqlx.DB
+
+	replicaHealthy atomic.Bool
+
+	queryTimeout time.Duration
+
+	observer QueryObserver
+
+	// stmts caches statements of the hottest queries, so Postgres parses and plans them once per
+	// connection instead of on every call.
+	stmtsMu sync.Mutex
+	stmts   map[string]*sqlx.Stmt
+}
+
+// NewDB creates a DB, replica may be nil if there is no read replica. Reads only go to the replica once
+// MonitorReplica has seen it respond. Every query is limited to queryTimeout.
+func NewDB(primary, replica *sqlx.DB, queryTimeout time.Duration) *DB {
+	return &DB{primary: primary, replica: replica, queryTimeout: queryTimeout}
+}
+
+// Primary returns the primary database, for tools like migrations which need it directly.
+func (d *DB) Primary() *sqlx.DB {
+	return d.primary
+}
+
+// queryContext derives the query's context from the caller's, so queries are cancelled along
+// with the request or job they were made for, and limits them to the query timeout.
+func (d *DB) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, d.queryTimeout)
+}
+
+// reader returns the replica if it's healthy and the primary otherwise.
+func (d *DB) reader() *sqlx.DB {
+	if d.replica != nil && d.replicaHealthy.Load() {
+		return d.replica
+	}
+
+	return d.primary
+}
+
+// MonitorReplica pings the replica every interval and routes reads to the primary while it can't be reached.
+// onChange is called whenever the replica becomes unavailable or recovers. It blocks, so it should be run in a goroutine.
+func (d *DB) MonitorReplica(interval time.Duration, onChange func(healthy bool, err error)) {
+	if d.replica == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), interval)
+		err := d.replica.PingContext(ctx)
+		cancel()
+
+		healthy := err == nil
+		if d.replicaHealthy.Swap(healthy) != healthy {
+			onChange(healthy, err)
+		}
+
+		<-ticker.C
+	}
+}
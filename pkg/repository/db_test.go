@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"errors"
+	"github.com/golang-migrate/migrate/v4"
+	"os"
+	"testing"
+	"time"
+)
+
+// newTestDB connects to the database in TEST_DATABASE_URL and migrates it, the test or benchmark is skipped if
+// it isn't set. The database should be a throwaway one, tests insert rows into it.
+func newTestDB(tb testing.TB) *DB {
+	tb.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		tb.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	primary, err := NewPostgres(dsn, PoolOptions{MaxOpenConns: 10, MaxIdleConns: 10})
+	if err != nil {
+		tb.Fatal(err)
+	}
+	tb.Cleanup(func() { primary.Close() })
+
+	migrator, err := NewMigrator(primary)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	err = migrator.Up()
+	migrator.Close()
+	if err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		tb.Fatal(err)
+	}
+
+	return NewDB(primary, nil, 5*time.Second)
+}
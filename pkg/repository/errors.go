@@ -1,8 +1,42 @@
 package repository
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
-	ErrUniqueViolation = errors.New("unique constraint violated")
-	ErrNotFound        = errors.New("not found")
+	ErrUniqueViolation      = errors.New("unique constraint violated")
+	ErrNotFound             = errors.New("not found")
+	ErrForeignKeyViolation  = errors.New("foreign key constraint violated")
+	ErrNotNullViolation     = errors.New("not null constraint violated")
+	ErrCheckViolation       = errors.New("check constraint violated")
+	ErrSerializationFailure = errors.New("transaction couldn't be serialized")
+	ErrTimeout              = errors.New("query timed out")
+	ErrCanceled             = errors.New("query canceled")
 )
+
+// Error describes a failed query. errors.Is reports true for its Kind, so callers can
+// match on the kind of failure without knowing about the underlying driver.
+type Error struct {
+	Kind       error
+	Code       string
+	Constraint string
+	Err        error
+}
+
+func (e *Error) Error() string {
+	if e.Constraint != "" {
+		return fmt.Sprintf("%s (%s): %v", e.Kind, e.Constraint, e.Err)
+	}
+
+	return fmt.Sprintf("%s: %v", e.Kind, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrRecordHistoryNotFound = errors.New("record history not found")
+
+// HistoryRepository keeps a snapshot of user and post records after every change, so
+// admins can see what a record looked like at any point in time.
+type HistoryRepository struct {
+	db *DB
+}
+
+type RecordChange struct {
+	ID         int
+	RecordType string `db:"record_type"`
+	RecordID   int    `db:"record_id"`
+	ActorID    *int   `db:"actor_id"`
+	// Snapshot is nil if the record was deleted by this change
+	Snapshot  *json.RawMessage
+	ChangedAt time.Time `db:"changed_at"`
+}
+
+func NewHistoryRepository(db *DB) *HistoryRepository {
+	return &HistoryRepository{db: db}
+}
+
+func (r *HistoryRepository) handleError(err error) error {
+	err = handleError(err)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ErrRecordHistoryNotFound
+	default:
+		return err
+	}
+}
+
+func (r *HistoryRepository) InsertChange(ctx context.Context, change RecordChange) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO record_history (record_type, record_id, actor_id, snapshot) VALUES ($1, $2, $3, $4)",
+		change.RecordType, change.RecordID, change.ActorID, change.Snapshot)
+	return r.handleError(err)
+}
+
+// FindChanges returns the changes of a record, newest first.
+func (r *HistoryRepository) FindChanges(ctx context.Context, recordType string, recordId, page, limit int) ([]RecordChange, error) {
+	var changes []RecordChange
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &changes, "SELECT * FROM record_history WHERE record_type = $1 AND record_id = $2 ORDER BY changed_at DESC, id DESC LIMIT $3 OFFSET $4",
+		recordType, recordId, limit, calculateOffset(page, limit))
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return changes, nil
+}
+
+// FindChangeAsOf returns the last change of a record made at or before the provided time.
+func (r *HistoryRepository) FindChangeAsOf(ctx context.Context, recordType string, recordId int, at time.Time) (RecordChange, error) {
+	var change RecordChange
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).GetContext(ctx, &change, "SELECT * FROM record_history WHERE record_type = $1 AND record_id = $2 AND changed_at <= $3 ORDER BY changed_at DESC, id DESC LIMIT 1",
+		recordType, recordId, at)
+	if err != nil {
+		return RecordChange{}, r.handleError(err)
+	}
+
+	return change, nil
+}
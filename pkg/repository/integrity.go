@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+)
+
+type IntegrityRepository struct {
+	db *DB
+}
+
+// orphanCheck describes rows in table whose column references a row in parentTable which doesn't exist.
+type orphanCheck struct {
+	Name        string
+	Table       string
+	Column      string
+	ParentTable string
+}
+
+var orphanChecks = []orphanCheck{
+	{Name: "posts of deleted users", Table: "post", Column: "user_id", ParentTable: "\"user\""},
+	{Name: "blacklisted tokens of deleted users", Table: "token_blacklist", Column: "user_id", ParentTable: "\"user\""},
+	{Name: "password reset tokens of deleted users", Table: "password_reset_token", Column: "user_id", ParentTable: "\"user\""},
+	{Name: "email verification tokens of deleted users", Table: "email_verification_token", Column: "user_id", ParentTable: "\"user\""},
+}
+
+type IntegrityReport struct {
+	Name    string
+	Orphans int
+	Fixed   bool
+}
+
+func NewIntegrityRepository(db *DB) *IntegrityRepository {
+	return &IntegrityRepository{db: db}
+}
+
+// CheckOrphans counts orphaned rows for every check, and deletes them if fix is true.
+func (r *IntegrityRepository) CheckOrphans(ctx context.Context, fix bool) ([]IntegrityReport, error) {
+	var reports []IntegrityReport
+
+	for _, check := range orphanChecks {
+		report := IntegrityReport{Name: check.Name}
+
+		condition := fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s parent WHERE parent.id = %s.%s)", check.ParentTable, check.Table, check.Column)
+
+		ctx, cancel := r.db.queryContext(ctx)
+		err := conn(ctx, r.db).GetContext(ctx, &report.Orphans, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s", check.Table, condition))
+		cancel()
+		if err != nil {
+			return nil, handleError(err)
+		}
+
+		if fix && report.Orphans > 0 {
+			ctx, cancel := r.db.queryContext(ctx)
+			_, err = conn(ctx, r.db).ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s", check.Table, condition))
+			cancel()
+			if err != nil {
+				return nil, handleError(err)
+			}
+
+			report.Fixed = true
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports, nil
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	JobStatusPending   = "pending"
+	JobStatusRunning   = "running"
+	JobStatusCompleted = "completed"
+	JobStatusDead      = "dead"
+)
+
+var (
+	ErrJobNotFound = errors.New("job not found")
+	ErrNoJobs      = errors.New("no jobs ready to run")
+)
+
+type JobRepository struct {
+	db *DB
+}
+
+type Job struct {
+	ID          int
+	Type        string
+	Payload     json.RawMessage
+	Status      string
+	Attempts    int
+	MaxAttempts int        `db:"max_attempts"`
+	LastError   string     `db:"last_error"`
+	RunAt       time.Time  `db:"run_at"`
+	LockedAt    *time.Time `db:"locked_at"`
+	CreatedAt   time.Time  `db:"created_at"`
+	UpdatedAt   time.Time  `db:"updated_at"`
+}
+
+func NewJobRepository(db *DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+func (r *JobRepository) handleError(err error) error {
+	err = handleError(err)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ErrJobNotFound
+	default:
+		return err
+	}
+}
+
+func (r *JobRepository) InsertJob(ctx context.Context, job Job) (Job, error) {
+	var newJob Job
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &newJob, "INSERT INTO job (type, payload, max_attempts, run_at) VALUES ($1, $2, $3, $4) RETURNING *", job.Type, job.Payload, job.MaxAttempts, job.RunAt)
+	if err != nil {
+		return Job{}, r.handleError(err)
+	}
+
+	return newJob, nil
+}
+
+// ClaimJob locks the next pending job which is due and marks it as running. Jobs locked by
+// other workers are skipped, so multiple instances can poll the same table.
+func (r *JobRepository) ClaimJob(ctx context.Context) (Job, error) {
+	var job Job
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `UPDATE job SET status = $1, attempts = attempts + 1, locked_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM job WHERE status = $2 AND run_at <= NOW()
+			ORDER BY run_at, id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING *`
+
+	err := conn(ctx, r.db).GetContext(ctx, &job, query, JobStatusRunning, JobStatusPending)
+	if err != nil {
+		err = handleError(err)
+		if errors.Is(err, ErrNotFound) {
+			return Job{}, ErrNoJobs
+		}
+
+		return Job{}, err
+	}
+
+	return job, nil
+}
+
+func (r *JobRepository) CompleteJob(ctx context.Context, jobId int) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE job SET status = $1, last_error = '', locked_at = NULL, updated_at = NOW() WHERE id = $2", JobStatusCompleted, jobId)
+	return r.handleError(err)
+}
+
+// RetryJobAt puts a failed job back into the queue, to be run again at runAt.
+func (r *JobRepository) RetryJobAt(ctx context.Context, jobId int, lastError string, runAt time.Time) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE job SET status = $1, last_error = $2, run_at = $3, locked_at = NULL, updated_at = NOW() WHERE id = $4", JobStatusPending, lastError, runAt, jobId)
+	return r.handleError(err)
+}
+
+// KillJob moves a job which ran out of attempts into the dead letter state.
+func (r *JobRepository) KillJob(ctx context.Context, jobId int, lastError string) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE job SET status = $1, last_error = $2, locked_at = NULL, updated_at = NOW() WHERE id = $3", JobStatusDead, lastError, jobId)
+	return r.handleError(err)
+}
+
+// ResurrectJob moves a dead job back into the queue with a fresh set of attempts.
+func (r *JobRepository) ResurrectJob(ctx context.Context, jobId int) (Job, error) {
+	var job Job
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &job, "UPDATE job SET status = $1, attempts = 0, run_at = NOW(), updated_at = NOW() WHERE id = $2 AND status = $3 RETURNING *", JobStatusPending, jobId, JobStatusDead)
+	if err != nil {
+		return Job{}, r.handleError(err)
+	}
+
+	return job, nil
+}
+
+// RequeueStaleJobs puts jobs which have been running for longer than timeout back into the queue,
+// which happens when an instance dies while running a job.
+func (r *JobRepository) RequeueStaleJobs(ctx context.Context, timeout time.Duration) (int, error) {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE job SET status = $1, locked_at = NULL, updated_at = NOW() WHERE status = $2 AND locked_at < $3", JobStatusPending, JobStatusRunning, time.Now().Add(-timeout))
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), r.handleError(err)
+}
+
+// DeleteCompletedJobs deletes completed jobs which finished before the provided time.
+func (r *JobRepository) DeleteCompletedJobs(ctx context.Context, before time.Time) (int, error) {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM job WHERE status = $1 AND updated_at < $2", JobStatusCompleted, before)
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), r.handleError(err)
+}
+
+// FindJobs returns the most recently updated jobs first, an empty status returns jobs in every state.
+func (r *JobRepository) FindJobs(ctx context.Context, status string, page, limit int) ([]Job, error) {
+	var jobs []Job
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &jobs, "SELECT * FROM job WHERE ($1 = '' OR status = $1) ORDER BY updated_at DESC, id DESC LIMIT $2 OFFSET $3", status, limit, calculateOffset(page, limit))
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return jobs, nil
+}
+
+func (r *JobRepository) CountJobsByStatus(ctx context.Context) (map[string]int, error) {
+	var rows []struct {
+		Status string
+		Count  int
+	}
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &rows, "SELECT status, COUNT(*) AS count FROM job GROUP BY status")
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	counts := map[string]int{}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+
+	return counts, nil
+}
+
+// CountJobsSince counts the jobs of a type which entered the status after the provided time.
+func (r *JobRepository) CountJobsSince(ctx context.Context, jobType, status string, since time.Time) (int, error) {
+	var count int
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).GetContext(ctx, &count, "SELECT COUNT(*) FROM job WHERE type = $1 AND status = $2 AND updated_at >= $3", jobType, status, since)
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	return count, nil
+}
+
+// CountEmailsSince counts the jobs of a type which were queued after the provided time to send the recipient an email
+// made from the template, whatever became of them. The payload must have recipient and template fields.
+//
+// It first takes an advisory lock on the recipient which is held until the transaction ends, so callers which count
+// and then queue an email in a transaction can't both see the count below a limit. Outside of a transaction the lock
+// is released right away.
+func (r *JobRepository) CountEmailsSince(ctx context.Context, jobType, recipient, template string, since time.Time) (int, error) {
+	var count int
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext(LOWER($1)))", recipient)
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	query := `SELECT COUNT(*) FROM job WHERE type = $1 AND LOWER(payload->>'recipient') = LOWER($2)
+		AND payload->>'template' = $3 AND created_at >= $4`
+
+	err = conn(ctx, r.db).GetContext(ctx, &count, query, jobType, recipient, template, since)
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"context"
+	"github.com/XiovV/blog-api/migrations"
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/jmoiron/sqlx"
+)
+
+// NewMigrator returns a migrator for the migrations embedded in the binary. It holds a single connection of db
+// for as long as it's open, closing it returns the connection without closing db. Concurrent migrations are
+// serialised with an advisory lock, so every instance can safely migrate on startup.
+func NewMigrator(db *sqlx.DB) (*migrate.Migrate, error) {
+	source, err := iofs.New(migrations.FS, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := context.Background()
+
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	driver, err := postgres.WithConnection(ctx, conn, &postgres.Config{})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	m, err := migrate.NewWithInstance("iofs", source, "postgres", driver)
+	if err != nil {
+		driver.Close()
+		return nil, err
+	}
+
+	return m, nil
+}
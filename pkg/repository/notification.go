@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	NotificationTypeMention = "mention"
+)
+
+type NotificationRepository struct {
+	db *DB
+}
+
+// Notification tells a user about something that happened, like being mentioned in a post. The actor is the user
+// who caused it, it's nil once they have been deleted.
+type Notification struct {
+	ID        int
+	UserID    int `db:"user_id"`
+	Type      string
+	ActorID   *int       `db:"actor_id"`
+	PostID    *int       `db:"post_id"`
+	ReadAt    *time.Time `db:"read_at"`
+	CreatedAt time.Time  `db:"created_at"`
+
+	// The fields below are only set by FindNotifications.
+	ActorUsername *string `db:"actor_username"`
+	PostPublicID  *string `db:"post_public_id"`
+	PostTitle     *string `db:"post_title"`
+}
+
+func NewNotificationRepository(db *DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) InsertNotification(ctx context.Context, notification Notification) (Notification, error) {
+	var newNotification Notification
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &newNotification, "INSERT INTO notification (user_id, type, actor_id, post_id) VALUES ($1, $2, $3, $4) RETURNING *", notification.UserID, notification.Type, notification.ActorID, notification.PostID)
+	if err != nil {
+		return Notification{}, handleError(err)
+	}
+
+	return newNotification, nil
+}
+
+// FindNotifications returns the user's notifications, newest first.
+func (r *NotificationRepository) FindNotifications(ctx context.Context, userId int, unreadOnly bool, page, limit int) ([]Notification, error) {
+	var notifications []Notification
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT notification.*, "user".username AS actor_username, post.public_id AS post_public_id, post.title AS post_title
+		FROM notification
+		LEFT JOIN "user" ON "user".id = notification.actor_id
+		LEFT JOIN post ON post.id = notification.post_id
+		WHERE notification.user_id = $1 AND (NOT $2 OR notification.read_at IS NULL)
+		ORDER BY notification.created_at DESC, notification.id DESC LIMIT $3 OFFSET $4`
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &notifications, query, userId, unreadOnly, limit, calculateOffset(page, limit))
+	if err != nil {
+		return nil, handleError(err)
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) CountUnreadNotifications(ctx context.Context, userId int) (int, error) {
+	var count int
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).GetContext(ctx, &count, "SELECT COUNT(*) FROM notification WHERE user_id = $1 AND read_at IS NULL", userId)
+	if err != nil {
+		return 0, handleError(err)
+	}
+
+	return count, nil
+}
+
+// MarkNotificationsRead marks every unread notification of the user as read and returns how many there were.
+func (r *NotificationRepository) MarkNotificationsRead(ctx context.Context, userId int) (int, error) {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE notification SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL", userId)
+	if err != nil {
+		return 0, handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), handleError(err)
+}
+
+// NotificationPreferences controls which events notify the user in the app and which by email. Users who haven't
+// changed their preferences are notified about everything.
+type NotificationPreferences struct {
+	UserID       int  `db:"user_id"`
+	MentionInApp bool `db:"mention_in_app"`
+	MentionEmail bool `db:"mention_email"`
+	// SecurityEmail controls the emails about changes to the user's account, like logins from new devices.
+	SecurityEmail bool      `db:"security_email"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+func (r *NotificationRepository) FindNotificationPreferences(ctx context.Context, userId int) (NotificationPreferences, error) {
+	var preferences NotificationPreferences
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &preferences, "SELECT * FROM notification_preference WHERE user_id = $1", userId)
+	if err != nil {
+		err = handleError(err)
+		if errors.Is(err, ErrNotFound) {
+			return NotificationPreferences{UserID: userId, MentionInApp: true, MentionEmail: true, SecurityEmail: true}, nil
+		}
+
+		return NotificationPreferences{}, err
+	}
+
+	return preferences, nil
+}
+
+func (r *NotificationRepository) UpdateNotificationPreferences(ctx context.Context, preferences NotificationPreferences) (NotificationPreferences, error) {
+	var updated NotificationPreferences
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `INSERT INTO notification_preference (user_id, mention_in_app, mention_email, security_email, updated_at) VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET mention_in_app = $2, mention_email = $3, security_email = $4, updated_at = NOW()
+		RETURNING *`
+
+	err := conn(ctx, r.db).GetContext(ctx, &updated, query, preferences.UserID, preferences.MentionInApp, preferences.MentionEmail, preferences.SecurityEmail)
+	if err != nil {
+		return NotificationPreferences{}, handleError(err)
+	}
+
+	return updated, nil
+}
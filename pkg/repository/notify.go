@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"github.com/lib/pq"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Channels instances notify each other on when data they may hold in memory changes. Notifications are sent
+// in the transaction of the change, so they're only delivered once it has been committed.
+const (
+	ChannelPostChanged        = "post_changed"
+	ChannelMaintenanceChanged = "maintenance_changed"
+)
+
+// listenerPingInterval is how often an idle listener checks its connection, so it notices a broken connection
+// without having to wait for the next notification.
+const listenerPingInterval = 90 * time.Second
+
+func notify(ctx context.Context, q querier, channel, payload string) error {
+	_, err := q.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	return err
+}
+
+func notifyPostChanged(ctx context.Context, q querier, postId int) error {
+	return notify(ctx, q, ChannelPostChanged, strconv.Itoa(postId))
+}
+
+// Notifications receives the notifications sent by every instance, including this one, and passes them to
+// the handlers subscribed to their channel.
+type Notifications struct {
+	listener *pq.Listener
+
+	mu       sync.RWMutex
+	handlers map[string][]func(payload string)
+}
+
+// NewNotifications opens a dedicated connection for listening, which is reestablished whenever it breaks.
+// onProblem is called with the errors of the connection.
+func NewNotifications(dsn string, onProblem func(err error)) *Notifications {
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			onProblem(err)
+		}
+	})
+
+	return &Notifications{listener: listener, handlers: map[string][]func(payload string){}}
+}
+
+// Subscribe calls handler with the payload of every notification on channel. Handlers are called one at a time,
+// so they should return quickly. A handler is also called with an empty payload after the connection has been
+// reestablished, since notifications sent in the meantime are lost and anything derived from the channel
+// has to be treated as stale.
+func (n *Notifications) Subscribe(channel string, handler func(payload string)) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	if len(n.handlers[channel]) == 0 {
+		err := n.listener.Listen(channel)
+		if err != nil {
+			return err
+		}
+	}
+
+	n.handlers[channel] = append(n.handlers[channel], handler)
+
+	return nil
+}
+
+// Run dispatches notifications until Close is called. It blocks, so it should be run in a goroutine.
+func (n *Notifications) Run() {
+	for {
+		select {
+		case notification, ok := <-n.listener.Notify:
+			if !ok {
+				return
+			}
+
+			if notification == nil {
+				n.dispatchAll()
+				continue
+			}
+
+			n.dispatch(notification.Channel, notification.Extra)
+
+		case <-time.After(listenerPingInterval):
+			go n.listener.Ping()
+		}
+	}
+}
+
+func (n *Notifications) dispatch(channel, payload string) {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	for _, handler := range n.handlers[channel] {
+		handler(payload)
+	}
+}
+
+func (n *Notifications) dispatchAll() {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	for _, handlers := range n.handlers {
+		for _, handler := range handlers {
+			handler("")
+		}
+	}
+}
+
+func (n *Notifications) Close() error {
+	return n.listener.Close()
+}
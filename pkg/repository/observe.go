@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"github.com/jmoiron/sqlx"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// QueryStats describes a finished query.
+type QueryStats struct {
+	// Method is the repository method which made the query, e.g. UserRepository.FindUserByID.
+	Method string
+	// Query is the SQL with string literals removed and whitespace collapsed, arguments are never included.
+	Query    string
+	Duration time.Duration
+	Err      error
+}
+
+// QueryObserver is called after every query, it must be safe for concurrent use.
+type QueryObserver func(ctx context.Context, stats QueryStats)
+
+// SetQueryObserver sets the function called after every query, it should be called before the DB is used.
+func (d *DB) SetQueryObserver(observer QueryObserver) {
+	d.observer = observer
+}
+
+func (d *DB) observe(q querier) querier {
+	if d.observer == nil {
+		return q
+	}
+
+	return observedQuerier{querier: q, observer: d.observer}
+}
+
+// observedQuerier times every query made through it and reports it to the observer.
+type observedQuerier struct {
+	querier
+	observer QueryObserver
+}
+
+func (q observedQuerier) report(ctx context.Context, query string, started time.Time, err error) {
+	reportQuery(ctx, q.observer, query, started, err)
+}
+
+// report is used by queries which don't go through an observedQuerier.
+func (d *DB) report(ctx context.Context, query string, started time.Time, err error) {
+	if d.observer != nil {
+		reportQuery(ctx, d.observer, query, started, err)
+	}
+}
+
+func reportQuery(ctx context.Context, observer QueryObserver, query string, started time.Time, err error) {
+	observer(ctx, QueryStats{
+		Method:   callingMethod(),
+		Query:    sanitizeQuery(query),
+		Duration: time.Since(started),
+		Err:      err,
+	})
+}
+
+func (q observedQuerier) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	started := time.Now()
+	rows, err := q.querier.QueryContext(ctx, query, args...)
+	q.report(ctx, query, started, err)
+	return rows, err
+}
+
+func (q observedQuerier) QueryxContext(ctx context.Context, query string, args ...interface{}) (*sqlx.Rows, error) {
+	started := time.Now()
+	rows, err := q.querier.QueryxContext(ctx, query, args...)
+	q.report(ctx, query, started, err)
+	return rows, err
+}
+
+func (q observedQuerier) QueryRowxContext(ctx context.Context, query string, args ...interface{}) *sqlx.Row {
+	started := time.Now()
+	row := q.querier.QueryRowxContext(ctx, query, args...)
+	q.report(ctx, query, started, row.Err())
+	return row
+}
+
+func (q observedQuerier) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	started := time.Now()
+	result, err := q.querier.ExecContext(ctx, query, args...)
+	q.report(ctx, query, started, err)
+	return result, err
+}
+
+func (q observedQuerier) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	started := time.Now()
+	err := q.querier.GetContext(ctx, dest, query, args...)
+	q.report(ctx, query, started, err)
+	return err
+}
+
+func (q observedQuerier) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	started := time.Now()
+	err := q.querier.SelectContext(ctx, dest, query, args...)
+	q.report(ctx, query, started, err)
+	return err
+}
+
+const repositoryPackage = "github.com/XiovV/blog-api/pkg/repository."
+
+// callingMethod returns the repository method which made the query, skipping the instrumentation and
+// the helpers queries are run through. Closures are attributed to the method they're defined in.
+func callingMethod() string {
+	pcs := make([]uintptr, 16)
+	n := runtime.Callers(4, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	for {
+		frame, more := frames.Next()
+
+		name := strings.TrimPrefix(frame.Function, repositoryPackage)
+		if name != frame.Function && !isInstrumentation(name) {
+			name = strings.NewReplacer("(*", "", ")", "").Replace(name)
+			if i := strings.Index(name, ".func"); i != -1 {
+				name = name[:i]
+			}
+
+			return name
+		}
+
+		if !more {
+			return "unknown"
+		}
+	}
+}
+
+var instrumentationPrefixes = []string{"observedQuerier.", "(*DB).", "reportQuery", "inTx", "getPrepared", "getWithStatement"}
+
+func isInstrumentation(name string) bool {
+	for _, prefix := range instrumentationPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'`)
+	whitespace    = regexp.MustCompile(`\s+`)
+)
+
+const maxQueryLength = 500
+
+// sanitizeQuery removes string literals, which could contain personal data, and makes the query fit on one line.
+func sanitizeQuery(query string) string {
+	query = stringLiteral.ReplaceAllString(query, "'?'")
+	query = strings.TrimSpace(whitespace.ReplaceAllString(query, " "))
+
+	if len(query) > maxQueryLength {
+		query = query[:maxQueryLength] + "…"
+	}
+
+	return query
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// The roles a user can have in an organization, what they allow is defined by rbac/organization_policy.csv.
+const (
+	OrganizationRoleAdmin  = "admin"
+	OrganizationRoleEditor = "editor"
+	OrganizationRoleReader = "reader"
+)
+
+var (
+	ErrOrganizationNotFound      = errors.New("organization not found")
+	ErrOrganizationAlreadyExists = errors.New("organization with this slug already exists")
+	ErrMemberNotFound            = errors.New("member not found")
+	ErrLastOrganizationAdmin     = errors.New("an organization must have at least one admin")
+)
+
+type OrganizationRepository struct {
+	db *DB
+}
+
+type Organization struct {
+	ID        int
+	PublicID  string `db:"public_id"`
+	Name      string
+	Slug      string
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+// UserOrganization is an organization along with the role of the user it was looked up for.
+type UserOrganization struct {
+	Organization
+	Role string `db:"member_role"`
+}
+
+type OrganizationMember struct {
+	OrganizationID int       `db:"organization_id"`
+	UserID         int       `db:"user_id"`
+	UserPublicID   string    `db:"user_public_id"`
+	Username       string    `db:"username"`
+	Role           string    `db:"role"`
+	CreatedAt      time.Time `db:"created_at"`
+}
+
+func NewOrganizationRepository(db *DB) *OrganizationRepository {
+	return &OrganizationRepository{db: db}
+}
+
+func (r *OrganizationRepository) handleError(err error) error {
+	err = handleError(err)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ErrOrganizationNotFound
+	case errors.Is(err, ErrUniqueViolation):
+		return ErrOrganizationAlreadyExists
+	default:
+		return err
+	}
+}
+
+// InsertOrganization creates the organization and makes its creator its first admin.
+func (r *OrganizationRepository) InsertOrganization(ctx context.Context, organization Organization, creatorId int) (Organization, error) {
+	var newOrganization Organization
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := inTx(ctx, r.db, func(q querier) error {
+		err := q.GetContext(ctx, &newOrganization, "INSERT INTO organization (name, slug) VALUES ($1, $2) RETURNING *", organization.Name, organization.Slug)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ExecContext(ctx, "INSERT INTO organization_member (organization_id, user_id, role) VALUES ($1, $2, $3)", newOrganization.ID, creatorId, OrganizationRoleAdmin)
+		return err
+	})
+	if err != nil {
+		return Organization{}, r.handleError(err)
+	}
+
+	return newOrganization, nil
+}
+
+func (r *OrganizationRepository) FindOrganizationByPublicID(ctx context.Context, publicId string) (Organization, error) {
+	var organization Organization
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &organization, "SELECT * FROM organization WHERE public_id = $1", publicId)
+	if err != nil {
+		return Organization{}, r.handleError(err)
+	}
+
+	return organization, nil
+}
+
+// FindOrganizationsByUserID returns the organizations the user is a member of.
+func (r *OrganizationRepository) FindOrganizationsByUserID(ctx context.Context, userId int) ([]UserOrganization, error) {
+	var organizations []UserOrganization
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT organization.*, organization_member.role AS member_role FROM organization
+		INNER JOIN organization_member ON organization_member.organization_id = organization.id
+		WHERE organization_member.user_id = $1 ORDER BY organization.name, organization.id`
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &organizations, query, userId)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return organizations, nil
+}
+
+func (r *OrganizationRepository) DeleteOrganization(ctx context.Context, organizationId int) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM organization WHERE id = $1", organizationId)
+	return r.handleError(err)
+}
+
+// FindMemberRole returns the user's role in the organization, or ErrMemberNotFound if they aren't a member.
+func (r *OrganizationRepository) FindMemberRole(ctx context.Context, organizationId, userId int) (string, error) {
+	var role string
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &role, "SELECT role FROM organization_member WHERE organization_id = $1 AND user_id = $2", organizationId, userId)
+	if err != nil {
+		err = handleError(err)
+		if errors.Is(err, ErrNotFound) {
+			return "", ErrMemberNotFound
+		}
+
+		return "", err
+	}
+
+	return role, nil
+}
+
+func (r *OrganizationRepository) FindMembers(ctx context.Context, organizationId int) ([]OrganizationMember, error) {
+	var members []OrganizationMember
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT organization_member.organization_id, organization_member.user_id, "user".public_id AS user_public_id,
+		"user".username, organization_member.role, organization_member.created_at FROM organization_member
+		INNER JOIN "user" ON "user".id = organization_member.user_id
+		WHERE organization_member.organization_id = $1 ORDER BY "user".username`
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &members, query, organizationId)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return members, nil
+}
+
+// SetMember adds the user to the organization or changes their role if they're already a member.
+// It returns ErrLastOrganizationAdmin instead of demoting the organization's only admin.
+func (r *OrganizationRepository) SetMember(ctx context.Context, organizationId, userId int, role string) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := inTx(ctx, r.db, func(q querier) error {
+		if role != OrganizationRoleAdmin {
+			err := ensureAnotherAdmin(ctx, q, organizationId, userId)
+			if err != nil {
+				return err
+			}
+		}
+
+		query := `INSERT INTO organization_member (organization_id, user_id, role) VALUES ($1, $2, $3)
+			ON CONFLICT (organization_id, user_id) DO UPDATE SET role = $3`
+
+		_, err := q.ExecContext(ctx, query, organizationId, userId, role)
+		return err
+	})
+	if errors.Is(err, ErrLastOrganizationAdmin) {
+		return err
+	}
+
+	return r.handleError(err)
+}
+
+// DeleteMember removes the user from the organization. It returns ErrLastOrganizationAdmin instead of
+// removing the organization's only admin.
+func (r *OrganizationRepository) DeleteMember(ctx context.Context, organizationId, userId int) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := inTx(ctx, r.db, func(q querier) error {
+		err := ensureAnotherAdmin(ctx, q, organizationId, userId)
+		if err != nil {
+			return err
+		}
+
+		result, err := q.ExecContext(ctx, "DELETE FROM organization_member WHERE organization_id = $1 AND user_id = $2", organizationId, userId)
+		if err != nil {
+			return err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if affected == 0 {
+			return ErrMemberNotFound
+		}
+
+		return nil
+	})
+	if errors.Is(err, ErrLastOrganizationAdmin) || errors.Is(err, ErrMemberNotFound) {
+		return err
+	}
+
+	return r.handleError(err)
+}
+
+// ensureAnotherAdmin returns ErrLastOrganizationAdmin if the user is the organization's only admin. The admins
+// are locked until the transaction ends, so concurrent requests can't demote the last two admins at once.
+func ensureAnotherAdmin(ctx context.Context, q querier, organizationId, userId int) error {
+	var admins []int
+	err := q.SelectContext(ctx, &admins, "SELECT user_id FROM organization_member WHERE organization_id = $1 AND role = $2 FOR UPDATE", organizationId, OrganizationRoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	if len(admins) == 1 && admins[0] == userId {
+		return ErrLastOrganizationAdmin
+	}
+
+	return nil
+}
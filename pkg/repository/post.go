@@ -1,26 +1,107 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"github.com/jmoiron/sqlx"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/filter"
+	"github.com/lib/pq"
+	"strings"
+	"time"
 )
 
 var (
-	ErrPostNotFound = errors.New("post not found")
+	ErrPostNotFound       = errors.New("post not found")
+	ErrPostAuthorNotFound = errors.New("user isn't a co-author of this post")
 )
 
 type PostRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
+// The states of a post, posts flagged as spam are held until a moderator approves them. Held posts are only
+// returned by the lookups by id and FindHeldPosts, lists leave them out unless asked for them.
+const (
+	PostStatusPublished = "published"
+	PostStatusHeld      = "held"
+)
+
+// Post is identified by PublicID in the API, so posts can't be enumerated by incrementing ids. OrganizationID
+// is the organization the post was published in, it's nil for personal posts.
 type Post struct {
-	ID     int
-	UserID int `db:"user_id"`
-	Title  string
-	Body   string
+	ID             int
+	PublicID       string `db:"public_id"`
+	UserID         int    `db:"user_id"`
+	OrganizationID *int   `db:"organization_id"`
+	Title          string
+	Body           string
+	Status         string
+	// CanonicalURL is where the post was originally published if it's cross-posted, it's empty otherwise.
+	CanonicalURL string `db:"canonical_url"`
+	License      string
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+const (
+	PostSortCreatedAt = "created_at"
+	PostSortUpdatedAt = "updated_at"
+)
+
+// PostListOptions sorts and filters lists of posts, zero values keep the default order of oldest
+// posts first and don't filter anything.
+type PostListOptions struct {
+	SortBy        string
+	Descending    bool
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	// IncludeHeld returns posts held for moderation too, it should only be set when listing the author's own posts.
+	IncludeHeld bool
+	// ViewerID is the user the list is for. Posts of shadowbanned users are left out unless they're the viewer's.
+	ViewerID int
+	// Filters are parsed with PostFilters.
+	Filters []filter.Condition
+}
+
+var allComparisons = []filter.Operator{filter.Eq, filter.Ne, filter.Gt, filter.Gte, filter.Lt, filter.Lte}
+
+// PostFilters are the fields lists of posts can be filtered by.
+var PostFilters = filter.Schema{
+	"status":     {Column: "status", Type: filter.String, Operators: []filter.Operator{filter.Eq, filter.Ne, filter.In}},
+	"license":    {Column: "license", Type: filter.String, Operators: []filter.Operator{filter.Eq, filter.Ne, filter.In}},
+	"created_at": {Column: "created_at", Type: filter.Time, Operators: allComparisons},
+	"updated_at": {Column: "updated_at", Type: filter.Time, Operators: allComparisons},
+}
+
+// notShadowbanned filters out the posts of shadowbanned users.
+const notShadowbanned = `user_id NOT IN (SELECT id FROM "user" WHERE shadowbanned)`
+
+func (o PostListOptions) orderBy() string {
+	column := PostSortCreatedAt
+	if o.SortBy == PostSortUpdatedAt {
+		column = PostSortUpdatedAt
+	}
+
+	direction := "ASC"
+	if o.Descending {
+		direction = "DESC"
+	}
+
+	return fmt.Sprintf("%s %s, id %s", column, direction, direction)
 }
 
-func NewPostRepository(db *sqlx.DB) *PostRepository {
+// postColumns are the columns scanned into a Post, search_vector is left out since it's only used for searching.
+const postColumns = "id, public_id, user_id, organization_id, title, body, status, canonical_url, license, created_at, updated_at"
+
+// postSearchVector returns the expression computing a post's search_vector from the title and body placeholders,
+// matches in the title weigh more than matches in the body. The column isn't generated by the database, so every
+// insert and update of a post has to set it.
+func postSearchVector(title, body string) string {
+	return fmt.Sprintf("setweight(to_tsvector('english', %s::text), 'A') || setweight(to_tsvector('english', %s::text), 'B')", title, body)
+}
+
+func NewPostRepository(db *DB) *PostRepository {
 	return &PostRepository{db: db}
 }
 
@@ -35,13 +116,18 @@ func (r *PostRepository) handleError(err error) error {
 	}
 }
 
-func (r *PostRepository) InsertPost(post Post) (Post, error) {
+// InsertPost publishes the post unless its Status is PostStatusHeld.
+func (r *PostRepository) InsertPost(ctx context.Context, post Post) (Post, error) {
 	var newPost Post
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	if post.Status == "" {
+		post.Status = PostStatusPublished
+	}
+
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	err := r.db.GetContext(ctx, &newPost, "INSERT INTO post (user_id, title, body) VALUES ($1, $2, $3) RETURNING *;", post.UserID, post.Title, post.Body)
+	err := conn(ctx, r.db).GetContext(ctx, &newPost, "INSERT INTO post (user_id, organization_id, title, body, status, canonical_url, license, search_vector) VALUES ($1, $2, $3, $4, $5, $6, $7, "+postSearchVector("$3", "$4")+") RETURNING "+postColumns, post.UserID, post.OrganizationID, post.Title, post.Body, post.Status, post.CanonicalURL, post.License)
 	if err != nil {
 		return Post{}, r.handleError(err)
 	}
@@ -49,13 +135,81 @@ func (r *PostRepository) InsertPost(post Post) (Post, error) {
 	return newPost, nil
 }
 
-func (r *PostRepository) FindPostByPostID(postId int) (Post, error) {
+// insertPostsBatchSize keeps the number of parameters of a single insert well below Postgres' limit of 65535.
+const insertPostsBatchSize = 1000
+
+// insertPostsColumns is the number of parameters of every row inserted by InsertPosts.
+const insertPostsColumns = 7
+
+// InsertPosts inserts the posts with one multi-row insert per batch, all in a single transaction,
+// so either every post is inserted or none are.
+func (r *PostRepository) InsertPosts(ctx context.Context, posts []Post) ([]Post, error) {
+	newPosts := make([]Post, 0, len(posts))
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := inTx(ctx, r.db, func(q querier) error {
+		for start := 0; start < len(posts); start += insertPostsBatchSize {
+			end := start + insertPostsBatchSize
+			if end > len(posts) {
+				end = len(posts)
+			}
+
+			values := make([]string, 0, end-start)
+			args := make([]any, 0, (end-start)*insertPostsColumns)
+			for i, post := range posts[start:end] {
+				if post.Status == "" {
+					post.Status = PostStatusPublished
+				}
+
+				n := i * insertPostsColumns
+				title, body := fmt.Sprintf("$%d", n+3), fmt.Sprintf("$%d", n+4)
+				values = append(values, fmt.Sprintf("($%d, $%d, %s, %s, $%d, $%d, $%d, %s)", n+1, n+2, title, body, n+5, n+6, n+7, postSearchVector(title, body)))
+				args = append(args, post.UserID, post.OrganizationID, post.Title, post.Body, post.Status, post.CanonicalURL, post.License)
+			}
+
+			var batch []Post
+			err := q.SelectContext(ctx, &batch, "INSERT INTO post (user_id, organization_id, title, body, status, canonical_url, license, search_vector) VALUES "+strings.Join(values, ", ")+" RETURNING "+postColumns, args...)
+			if err != nil {
+				return err
+			}
+
+			newPosts = append(newPosts, batch...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return newPosts, nil
+}
+
+const findPostByPostIDQuery = "SELECT " + postColumns + " FROM post WHERE id = $1"
+
+func (r *PostRepository) FindPostByPostID(ctx context.Context, postId int) (Post, error) {
+	var post Post
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := getPrepared(ctx, r.db, &post, findPostByPostIDQuery, postId)
+	if err != nil {
+		return Post{}, r.handleError(err)
+	}
+
+	return post, nil
+}
+
+func (r *PostRepository) FindPostByPublicID(ctx context.Context, publicId string) (Post, error) {
 	var post Post
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	err := r.db.GetContext(ctx, &post, "SELECT * FROM post WHERE id = $1", postId)
+	err := getPrepared(ctx, r.db, &post, "SELECT "+postColumns+" FROM post WHERE public_id = $1", publicId)
 	if err != nil {
 		return Post{}, r.handleError(err)
 	}
@@ -63,21 +217,35 @@ func (r *PostRepository) FindPostByPostID(postId int) (Post, error) {
 	return post, nil
 }
 
-func (r *PostRepository) DeletePostByPostID(postId int) error {
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+func (r *PostRepository) DeletePostByPostID(ctx context.Context, postId int) error {
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, "DELETE FROM post WHERE id = $1", postId)
+	err := inTx(ctx, r.db, func(q querier) error {
+		_, err := q.ExecContext(ctx, "DELETE FROM post WHERE id = $1", postId)
+		if err != nil {
+			return err
+		}
+
+		return notifyPostChanged(ctx, q, postId)
+	})
 	return r.handleError(err)
 }
 
-func (r *PostRepository) UpdatePost(post Post) (Post, error) {
+func (r *PostRepository) UpdatePost(ctx context.Context, post Post) (Post, error) {
 	var updatedPost Post
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	err := r.db.GetContext(ctx, &updatedPost, "UPDATE post SET title = $1, body = $2 WHERE id = $3 RETURNING *", post.Title, post.Body, post.ID)
+	err := inTx(ctx, r.db, func(q querier) error {
+		err := q.GetContext(ctx, &updatedPost, "UPDATE post SET title = $1, body = $2, canonical_url = $3, license = $4, search_vector = "+postSearchVector("$1", "$2")+", updated_at = NOW() WHERE id = $5 RETURNING "+postColumns, post.Title, post.Body, post.CanonicalURL, post.License, post.ID)
+		if err != nil {
+			return err
+		}
+
+		return notifyPostChanged(ctx, q, updatedPost.ID)
+	})
 	if err != nil {
 		return Post{}, r.handleError(err)
 	}
@@ -85,16 +253,263 @@ func (r *PostRepository) UpdatePost(post Post) (Post, error) {
 	return updatedPost, nil
 }
 
-func (r *PostRepository) FindByUserID(userId, page, limit int) ([]Post, error) {
+func (r *PostRepository) FindByUserID(ctx context.Context, userId int, options PostListOptions, page, limit int) ([]Post, error) {
+	var posts []Post
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	filters, filterArgs := filter.SQL(options.Filters, 9)
+
+	query := `SELECT ` + postColumns + ` FROM post WHERE user_id = $1 AND organization_id IS NULL
+		AND ($2::timestamptz IS NULL OR created_at > $2)
+		AND ($3::timestamptz IS NULL OR created_at < $3)
+		AND ($4::timestamptz IS NULL OR updated_at > $4)
+		AND ($5 OR status = 'published')
+		AND (user_id = $8 OR ` + notShadowbanned + `)` + filters + `
+		ORDER BY ` + options.orderBy() + ` LIMIT $6 OFFSET $7`
+
+	args := append([]any{userId, options.CreatedAfter, options.CreatedBefore, options.UpdatedAfter, options.IncludeHeld, limit, calculateOffset(page, limit), options.ViewerID}, filterArgs...)
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &posts, query, args...)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return posts, nil
+}
+
+// FindByOrganizationID returns the posts published in the organization.
+func (r *PostRepository) FindByOrganizationID(ctx context.Context, organizationId int, options PostListOptions, page, limit int) ([]Post, error) {
+	var posts []Post
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	filters, filterArgs := filter.SQL(options.Filters, 9)
+
+	query := `SELECT ` + postColumns + ` FROM post WHERE organization_id = $1
+		AND ($2::timestamptz IS NULL OR created_at > $2)
+		AND ($3::timestamptz IS NULL OR created_at < $3)
+		AND ($4::timestamptz IS NULL OR updated_at > $4)
+		AND ($5 OR status = 'published')
+		AND (user_id = $8 OR ` + notShadowbanned + `)` + filters + `
+		ORDER BY ` + options.orderBy() + ` LIMIT $6 OFFSET $7`
+
+	args := append([]any{organizationId, options.CreatedAfter, options.CreatedBefore, options.UpdatedAfter, options.IncludeHeld, limit, calculateOffset(page, limit), options.ViewerID}, filterArgs...)
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &posts, query, args...)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return posts, nil
+}
+
+// FindLatestByUserID returns the user's newest posts first, none if the user is shadowbanned.
+func (r *PostRepository) FindLatestByUserID(ctx context.Context, userId, page, limit int) ([]Post, error) {
+	var posts []Post
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &posts, "SELECT "+postColumns+" FROM post WHERE user_id = $1 AND organization_id IS NULL AND status = 'published' AND "+notShadowbanned+" ORDER BY created_at DESC, id DESC LIMIT $2 OFFSET $3", userId, limit, calculateOffset(page, limit))
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return posts, nil
+}
+
+// CountByUserID counts the user's published posts, none if the user is shadowbanned.
+func (r *PostRepository) CountByUserID(ctx context.Context, userId int) (int, error) {
+	var count int
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).GetContext(ctx, &count, "SELECT COUNT(*) FROM post WHERE user_id = $1 AND organization_id IS NULL AND status = 'published' AND "+notShadowbanned, userId)
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	return count, nil
+}
+
+// PostSearchResult is a post matching a search, a higher Rank means a better match.
+type PostSearchResult struct {
+	Post
+	Rank float64
+}
+
+// SearchPosts finds posts matching the query, which supports the web search syntax of quoted phrases, "or" and
+// "-" to exclude words. The best matches come first.
+func (r *PostRepository) SearchPosts(ctx context.Context, query string, page, limit int) ([]PostSearchResult, error) {
+	var results []PostSearchResult
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &results, `SELECT `+postColumns+`, ts_rank(search_vector, query) AS rank
+		FROM post, websearch_to_tsquery('english', $1) query
+		WHERE search_vector @@ query AND organization_id IS NULL AND status = 'published' AND `+notShadowbanned+`
+		ORDER BY rank DESC, id DESC LIMIT $2 OFFSET $3`, query, limit, calculateOffset(page, limit))
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return results, nil
+}
+
+// FindHeldPosts returns the posts held for moderation, oldest first so moderators work through them in the
+// order they were submitted.
+func (r *PostRepository) FindHeldPosts(ctx context.Context, page, limit int) ([]Post, error) {
 	var posts []Post
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	err := r.db.SelectContext(ctx, &posts, "SELECT * FROM post WHERE user_id = $1 LIMIT $2 OFFSET $3", userId, limit, calculateOffset(page, limit))
+	err := readConn(ctx, r.db).SelectContext(ctx, &posts, "SELECT "+postColumns+" FROM post WHERE status = $1 ORDER BY created_at, id LIMIT $2 OFFSET $3", PostStatusHeld, limit, calculateOffset(page, limit))
 	if err != nil {
 		return nil, r.handleError(err)
 	}
 
 	return posts, nil
 }
+
+// PublishHeldPost publishes a post held for moderation. It returns ErrPostNotFound if there is no held post with the id.
+func (r *PostRepository) PublishHeldPost(ctx context.Context, postId int) (Post, error) {
+	var post Post
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := inTx(ctx, r.db, func(q querier) error {
+		err := q.GetContext(ctx, &post, "UPDATE post SET status = $1 WHERE id = $2 AND status = $3 RETURNING "+postColumns, PostStatusPublished, postId, PostStatusHeld)
+		if err != nil {
+			return err
+		}
+
+		return notifyPostChanged(ctx, q, post.ID)
+	})
+	if err != nil {
+		return Post{}, r.handleError(err)
+	}
+
+	return post, nil
+}
+
+// SetMentions replaces the users mentioned in the post and returns the ones who weren't mentioned in it before,
+// so editing a post only notifies users who have been newly mentioned.
+func (r *PostRepository) SetMentions(ctx context.Context, postId int, userIds []int) ([]int, error) {
+	var mentioned []int
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := inTx(ctx, r.db, func(q querier) error {
+		_, err := q.ExecContext(ctx, "DELETE FROM mention WHERE post_id = $1 AND NOT (user_id = ANY($2))", postId, pq.Array(userIds))
+		if err != nil {
+			return err
+		}
+
+		return q.SelectContext(ctx, &mentioned, "INSERT INTO mention (post_id, user_id) SELECT $1, unnest($2::bigint[]) ON CONFLICT DO NOTHING RETURNING user_id", postId, pq.Array(userIds))
+	})
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return mentioned, nil
+}
+
+// PostAuthor is the owner or a co-author of a post.
+type PostAuthor struct {
+	UserID       int       `db:"user_id"`
+	UserPublicID string    `db:"user_public_id"`
+	Username     string    `db:"username"`
+	Verified     bool      `db:"verified"`
+	CoAuthor     bool      `db:"co_author"`
+	CreatedAt    time.Time `db:"created_at"`
+}
+
+// FindPostAuthors returns the post's owner followed by its co-authors.
+func (r *PostRepository) FindPostAuthors(ctx context.Context, postId int) ([]PostAuthor, error) {
+	var authors []PostAuthor
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT "user".id AS user_id, "user".public_id AS user_public_id, "user".username, "user".verified, FALSE AS co_author, post.created_at
+		FROM post INNER JOIN "user" ON "user".id = post.user_id WHERE post.id = $1
+		UNION ALL
+		SELECT "user".id, "user".public_id, "user".username, "user".verified, TRUE, post_author.created_at
+		FROM post_author INNER JOIN "user" ON "user".id = post_author.user_id WHERE post_author.post_id = $1
+		ORDER BY co_author, created_at`
+
+	err := conn(ctx, r.db).SelectContext(ctx, &authors, query, postId)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return authors, nil
+}
+
+func (r *PostRepository) IsCoAuthor(ctx context.Context, postId, userId int) (bool, error) {
+	var exists bool
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &exists, "SELECT EXISTS (SELECT 1 FROM post_author WHERE post_id = $1 AND user_id = $2)", postId, userId)
+	if err != nil {
+		return false, r.handleError(err)
+	}
+
+	return exists, nil
+}
+
+// CountCoAuthors returns how many co-authors the post has, not counting its owner.
+func (r *PostRepository) CountCoAuthors(ctx context.Context, postId int) (int, error) {
+	var count int
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &count, "SELECT COUNT(*) FROM post_author WHERE post_id = $1", postId)
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	return count, nil
+}
+
+// InsertCoAuthor makes the user a co-author of the post, adding an existing co-author again does nothing.
+func (r *PostRepository) InsertCoAuthor(ctx context.Context, postId, userId int) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO post_author (post_id, user_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", postId, userId)
+	return r.handleError(err)
+}
+
+// DeleteCoAuthor returns ErrPostAuthorNotFound if the user isn't a co-author of the post.
+func (r *PostRepository) DeleteCoAuthor(ctx context.Context, postId, userId int) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM post_author WHERE post_id = $1 AND user_id = $2", postId, userId)
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	if affected == 0 {
+		return ErrPostAuthorNotFound
+	}
+
+	return nil
+}
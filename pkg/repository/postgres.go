@@ -10,32 +10,45 @@ import (
 	"time"
 )
 
-const (
-	MaxOpenConns        = 25
-	MaxIdleConns        = 25
-	MaxIdleTime         = "15m"
-	DefaultQueryTimeout = 5
-)
-
 type Postgres struct {
 	db *sqlx.DB
 }
 
-func NewPostgres(dsn string) (*sqlx.DB, error) {
-	db, err := sqlx.Connect("postgres", dsn)
+// PoolOptions configures a connection pool, zero durations mean connections are never closed for being idle or old.
+type PoolOptions struct {
+	MaxOpenConns int
+	MaxIdleConns int
+	MaxIdleTime  time.Duration
+	MaxLifetime  time.Duration
+}
+
+func (o PoolOptions) Validate() error {
+	switch {
+	case o.MaxOpenConns < 1:
+		return errors.New("max open connections must be at least 1")
+	case o.MaxIdleConns < 0 || o.MaxIdleConns > o.MaxOpenConns:
+		return errors.New("max idle connections must be between 0 and max open connections")
+	case o.MaxIdleTime < 0:
+		return errors.New("max idle time must not be negative")
+	case o.MaxLifetime < 0:
+		return errors.New("max connection lifetime must not be negative")
+	}
+
+	return nil
+}
+
+func NewPostgres(dsn string, options PoolOptions) (*sqlx.DB, error) {
+	err := options.Validate()
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetMaxOpenConns(MaxOpenConns)
-	db.SetMaxIdleConns(MaxIdleConns)
-
-	duration, err := time.ParseDuration(MaxIdleTime)
+	db, err := sqlx.Connect("postgres", dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	db.SetConnMaxIdleTime(duration)
+	configurePool(db, options)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
@@ -48,24 +61,62 @@ func NewPostgres(dsn string) (*sqlx.DB, error) {
 	return db, nil
 }
 
+// NewReplica opens a connection pool to a read replica. Unlike NewPostgres it doesn't require the replica
+// to be reachable, reads are served by the primary until it is.
+func NewReplica(dsn string, options PoolOptions) (*sqlx.DB, error) {
+	err := options.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sqlx.Open("postgres", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	configurePool(db, options)
+
+	return db, nil
+}
+
+func configurePool(db *sqlx.DB, options PoolOptions) {
+	db.SetMaxOpenConns(options.MaxOpenConns)
+	db.SetMaxIdleConns(options.MaxIdleConns)
+	db.SetConnMaxIdleTime(options.MaxIdleTime)
+	db.SetConnMaxLifetime(options.MaxLifetime)
+}
+
 func calculateOffset(page, limit int) int {
 	return (page - 1) * limit
 }
 
-func newBackgroundContext(duration int) (context.Context, context.CancelFunc) {
-	return context.WithTimeout(context.Background(), time.Duration(duration)*time.Second)
+var pqErrorKinds = map[string]error{
+	"unique_violation":      ErrUniqueViolation,
+	"foreign_key_violation": ErrForeignKeyViolation,
+	"not_null_violation":    ErrNotNullViolation,
+	"check_violation":       ErrCheckViolation,
+	"serialization_failure": ErrSerializationFailure,
+	"deadlock_detected":     ErrSerializationFailure,
+	"query_canceled":        ErrTimeout,
 }
 
 func handleError(err error) error {
 	var pqErr *pq.Error
 	switch {
 	case errors.As(err, &pqErr):
-		if pqErr.Code.Name() == "unique_violation" {
-			return ErrUniqueViolation
+		kind, ok := pqErrorKinds[pqErr.Code.Name()]
+		if ok {
+			return &Error{Kind: kind, Code: string(pqErr.Code), Constraint: pqErr.Constraint, Err: err}
 		}
 
 	case errors.Is(err, sql.ErrNoRows):
 		return ErrNotFound
+
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Kind: ErrTimeout, Err: err}
+
+	case errors.Is(err, context.Canceled):
+		return &Error{Kind: ErrCanceled, Err: err}
 	}
 
 	return err
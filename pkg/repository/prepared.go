@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"time"
+)
+
+// statement returns the prepared statement for the query, preparing it on first use.
+func (d *DB) statement(ctx context.Context, query string) (*sqlx.Stmt, error) {
+	d.stmtsMu.Lock()
+	defer d.stmtsMu.Unlock()
+
+	if stmt, ok := d.stmts[query]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := d.primary.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if d.stmts == nil {
+		d.stmts = map[string]*sqlx.Stmt{}
+	}
+	d.stmts[query] = stmt
+
+	return stmt, nil
+}
+
+// forgetStatement drops a statement which can't be used anymore, so it's prepared again on next use.
+func (d *DB) forgetStatement(query string) {
+	d.stmtsMu.Lock()
+	defer d.stmtsMu.Unlock()
+
+	if stmt, ok := d.stmts[query]; ok {
+		stmt.Close()
+		delete(d.stmts, query)
+	}
+}
+
+// getPrepared is GetContext for queries made often enough to be worth preparing. Inside a transaction the
+// statement is bound to it. Statements whose plan was invalidated by a schema change are prepared again.
+func getPrepared(ctx context.Context, db *DB, dest interface{}, query string, args ...interface{}) error {
+	started := time.Now()
+
+	err := getWithStatement(ctx, db, dest, query, args...)
+	if isInvalidatedPlan(err) {
+		db.forgetStatement(query)
+		err = getWithStatement(ctx, db, dest, query, args...)
+	}
+
+	db.report(ctx, query, started, err)
+
+	return err
+}
+
+func getWithStatement(ctx context.Context, db *DB, dest interface{}, query string, args ...interface{}) error {
+	stmt, err := db.statement(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		stmt = tx.StmtxContext(ctx, stmt)
+		defer stmt.Close()
+	}
+
+	return stmt.GetContext(ctx, dest, args...)
+}
+
+// isInvalidatedPlan reports whether the error is Postgres refusing to run a prepared statement because
+// the tables it uses were altered, e.g. "cached plan must not change result type".
+func isInvalidatedPlan(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "0A000"
+}
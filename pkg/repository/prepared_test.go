@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// insertBenchmarkPost inserts a user with a single post, both are deleted when the benchmark ends.
+func insertBenchmarkPost(b *testing.B, db *DB) (int, int) {
+	b.Helper()
+
+	ctx := context.Background()
+	users := NewUserRepository(db)
+
+	_, err := users.EnsureRole(ctx, "user")
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	name := fmt.Sprintf("benchmark%d", time.Now().UnixNano())
+
+	userId, err := users.InsertUser(ctx, User{Username: name, Email: name + "@example.com", Password: "password", Role: "user"})
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { users.DeleteUserByID(context.Background(), userId) })
+
+	post, err := NewPostRepository(db).InsertPost(ctx, Post{UserID: userId, Title: "benchmark", Body: "benchmark"})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	return userId, post.ID
+}
+
+func BenchmarkFindUserByID(b *testing.B) {
+	db := newTestDB(b)
+	userId, _ := insertBenchmarkPost(b, db)
+	users := NewUserRepository(db)
+
+	b.Run("prepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := users.FindUserByID(context.Background(), userId)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("unprepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var user User
+			err := db.primary.GetContext(context.Background(), &user, findUserByIDQuery, userId)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkFindPostByPostID(b *testing.B) {
+	db := newTestDB(b)
+	_, postId := insertBenchmarkPost(b, db)
+	posts := NewPostRepository(db)
+
+	b.Run("prepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := posts.FindPostByPostID(context.Background(), postId)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("unprepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var post Post
+			err := db.primary.GetContext(context.Background(), &post, findPostByPostIDQuery, postId)
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,130 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusResolved  = "resolved"
+	ReportStatusDismissed = "dismissed"
+)
+
+var (
+	ErrReportNotFound      = errors.New("report not found")
+	ErrReportAlreadyExists = errors.New("you have already reported this post")
+)
+
+type ReportRepository struct {
+	db *DB
+}
+
+// Report is a user's complaint about a post. The post, reporter and reviewer are nil once they have been deleted,
+// the report itself is kept so moderators can still see what was reported.
+type Report struct {
+	ID         int
+	PostID     *int `db:"post_id"`
+	ReporterID *int `db:"reporter_id"`
+	Reason     string
+	Details    string
+	Status     string
+	ReviewerID *int       `db:"reviewer_id"`
+	ReviewNote string     `db:"review_note"`
+	CreatedAt  time.Time  `db:"created_at"`
+	ReviewedAt *time.Time `db:"reviewed_at"`
+
+	// The fields below are only set by FindReports.
+	PostPublicID     *string `db:"post_public_id"`
+	PostTitle        *string `db:"post_title"`
+	ReporterUsername *string `db:"reporter_username"`
+}
+
+func NewReportRepository(db *DB) *ReportRepository {
+	return &ReportRepository{db: db}
+}
+
+func (r *ReportRepository) handleError(err error) error {
+	err = handleError(err)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ErrReportNotFound
+	case errors.Is(err, ErrUniqueViolation):
+		return ErrReportAlreadyExists
+	default:
+		return err
+	}
+}
+
+// InsertReport returns ErrReportAlreadyExists if the reporter already has an open report about the post.
+func (r *ReportRepository) InsertReport(ctx context.Context, report Report) (Report, error) {
+	var newReport Report
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &newReport, "INSERT INTO report (post_id, reporter_id, reason, details) VALUES ($1, $2, $3, $4) RETURNING *", report.PostID, report.ReporterID, report.Reason, report.Details)
+	if err != nil {
+		return Report{}, r.handleError(err)
+	}
+
+	return newReport, nil
+}
+
+// FindReports returns the reports in the status, or all of them if status is empty, oldest first so
+// moderators work through the queue in the order reports came in.
+func (r *ReportRepository) FindReports(ctx context.Context, status string, page, limit int) ([]Report, error) {
+	var reports []Report
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT report.*, post.public_id AS post_public_id, post.title AS post_title, "user".username AS reporter_username
+		FROM report
+		LEFT JOIN post ON post.id = report.post_id
+		LEFT JOIN "user" ON "user".id = report.reporter_id
+		WHERE $1 = '' OR report.status = $1
+		ORDER BY report.created_at, report.id LIMIT $2 OFFSET $3`
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &reports, query, status, limit, calculateOffset(page, limit))
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return reports, nil
+}
+
+func (r *ReportRepository) CountOpenReports(ctx context.Context) (int, error) {
+	var count int
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).GetContext(ctx, &count, "SELECT COUNT(*) FROM report WHERE status = $1", ReportStatusOpen)
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	return count, nil
+}
+
+// ReviewReport closes an open report as resolved or dismissed. It returns ErrReportNotFound if there is
+// no open report with the id.
+func (r *ReportRepository) ReviewReport(ctx context.Context, reportId int, status string, reviewerId int, note string) (Report, error) {
+	var report Report
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `UPDATE report SET status = $1, reviewer_id = $2, review_note = $3, reviewed_at = NOW()
+		WHERE id = $4 AND status = $5 RETURNING *`
+
+	err := conn(ctx, r.db).GetContext(ctx, &report, query, status, reviewerId, note, reportId, ReportStatusOpen)
+	if err != nil {
+		return Report{}, r.handleError(err)
+	}
+
+	return report, nil
+}
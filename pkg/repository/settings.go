@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// SettingsRepository stores site-wide settings, which live in single row tables.
+type SettingsRepository struct {
+	db *DB
+}
+
+type Appearance struct {
+	AccentColor string    `db:"accent_color"`
+	LogoURL     string    `db:"logo_url"`
+	FooterText  string    `db:"footer_text"`
+	UpdatedAt   time.Time `db:"updated_at"`
+}
+
+func NewSettingsRepository(db *DB) *SettingsRepository {
+	return &SettingsRepository{db: db}
+}
+
+func (r *SettingsRepository) GetAppearance(ctx context.Context) (Appearance, error) {
+	var appearance Appearance
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &appearance, "SELECT accent_color, logo_url, footer_text, updated_at FROM appearance WHERE id = 1")
+	if err != nil {
+		return Appearance{}, handleError(err)
+	}
+
+	return appearance, nil
+}
+
+func (r *SettingsRepository) UpdateAppearance(ctx context.Context, appearance Appearance) (Appearance, error) {
+	var updated Appearance
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `INSERT INTO appearance (id, accent_color, logo_url, footer_text, updated_at) VALUES (1, $1, $2, $3, NOW())
+		ON CONFLICT (id) DO UPDATE SET accent_color = $1, logo_url = $2, footer_text = $3, updated_at = NOW()
+		RETURNING accent_color, logo_url, footer_text, updated_at`
+
+	err := conn(ctx, r.db).GetContext(ctx, &updated, query, appearance.AccentColor, appearance.LogoURL, appearance.FooterText)
+	if err != nil {
+		return Appearance{}, handleError(err)
+	}
+
+	return updated, nil
+}
+
+type Maintenance struct {
+	Enabled    bool
+	AllowReads bool `db:"allow_reads"`
+	Message    string
+	// RetryAfter is in seconds
+	RetryAfter int       `db:"retry_after"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func (r *SettingsRepository) GetMaintenance(ctx context.Context) (Maintenance, error) {
+	var maintenance Maintenance
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &maintenance, "SELECT enabled, allow_reads, message, retry_after, updated_at FROM maintenance WHERE id = 1")
+	if err != nil {
+		return Maintenance{}, handleError(err)
+	}
+
+	return maintenance, nil
+}
+
+func (r *SettingsRepository) UpdateMaintenance(ctx context.Context, maintenance Maintenance) (Maintenance, error) {
+	var updated Maintenance
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `INSERT INTO maintenance (id, enabled, allow_reads, message, retry_after, updated_at) VALUES (1, $1, $2, $3, $4, NOW())
+		ON CONFLICT (id) DO UPDATE SET enabled = $1, allow_reads = $2, message = $3, retry_after = $4, updated_at = NOW()
+		RETURNING enabled, allow_reads, message, retry_after, updated_at`
+
+	err := inTx(ctx, r.db, func(q querier) error {
+		err := q.GetContext(ctx, &updated, query, maintenance.Enabled, maintenance.AllowReads, maintenance.Message, maintenance.RetryAfter)
+		if err != nil {
+			return err
+		}
+
+		return notify(ctx, q, ChannelMaintenanceChanged, "")
+	})
+	if err != nil {
+		return Maintenance{}, handleError(err)
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	StatsIntervalDay   = "day"
+	StatsIntervalWeek  = "week"
+	StatsIntervalMonth = "month"
+)
+
+type StatsRepository struct {
+	db *DB
+}
+
+// PlatformStats are the platform's totals and how they changed since a point in time. Users and Posts are all-time
+// totals, the other counts only cover the time since then.
+type PlatformStats struct {
+	Users          int
+	Posts          int
+	Registrations  int
+	ActiveUsers    int `db:"active_users"`
+	PostsPublished int `db:"posts_published"`
+	LoginFailures  int `db:"login_failures"`
+
+	Series []StatsBucket
+}
+
+// StatsBucket holds the counts of a single day, week or month. Active users are the users who logged in at least once.
+type StatsBucket struct {
+	Start          time.Time
+	Registrations  int
+	ActiveUsers    int `db:"active_users"`
+	PostsPublished int `db:"posts_published"`
+	LoginFailures  int `db:"login_failures"`
+}
+
+func NewStatsRepository(db *DB) *StatsRepository {
+	return &StatsRepository{db: db}
+}
+
+// InsertLoginAttempt records a login, userId is nil if nobody has the username which was tried.
+func (r *StatsRepository) InsertLoginAttempt(ctx context.Context, userId *int, succeeded bool, userAgent string) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO login_attempt (user_id, succeeded, user_agent) VALUES ($1, $2, $3)", userId, succeeded, userAgent)
+	return handleError(err)
+}
+
+// FindLoginDevice reports whether the user logged in successfully before and whether any of those logins was made
+// with the user agent. Only logins within LOGIN_ATTEMPT_RETENTION are known.
+func (r *StatsRepository) FindLoginDevice(ctx context.Context, userId int, userAgent string) (bool, bool, error) {
+	var device struct {
+		LoggedIn bool `db:"logged_in"`
+		Known    bool
+	}
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT COUNT(*) > 0 AS logged_in, COUNT(*) FILTER (WHERE user_agent = $2) > 0 AS known
+		FROM login_attempt WHERE user_id = $1 AND succeeded`
+
+	err := conn(ctx, r.db).GetContext(ctx, &device, query, userId, userAgent)
+	if err != nil {
+		return false, false, handleError(err)
+	}
+
+	return device.LoggedIn, device.Known, nil
+}
+
+// DeleteLoginAttemptsBefore removes login attempts older than before and returns how many there were.
+func (r *StatsRepository) DeleteLoginAttemptsBefore(ctx context.Context, before time.Time) (int, error) {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM login_attempt WHERE created_at < $1", before)
+	if err != nil {
+		return 0, handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), handleError(err)
+}
+
+// FindPlatformStats counts what happened on the platform since the given time, split into buckets of the interval,
+// which has to be one of the StatsInterval constants. The first bucket only counts from since onwards.
+func (r *StatsRepository) FindPlatformStats(ctx context.Context, since time.Time, interval string) (PlatformStats, error) {
+	var stats PlatformStats
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	totalsQuery := `SELECT
+			(SELECT COUNT(*) FROM "user") AS users,
+			(SELECT COUNT(*) FROM post WHERE status = 'published') AS posts,
+			(SELECT COUNT(*) FROM "user" WHERE created_at >= $1) AS registrations,
+			(SELECT COUNT(DISTINCT user_id) FROM login_attempt WHERE succeeded AND created_at >= $1) AS active_users,
+			(SELECT COUNT(*) FROM post WHERE status = 'published' AND created_at >= $1) AS posts_published,
+			(SELECT COUNT(*) FROM login_attempt WHERE NOT succeeded AND created_at >= $1) AS login_failures`
+
+	err := readConn(ctx, r.db).GetContext(ctx, &stats, totalsQuery, since)
+	if err != nil {
+		return PlatformStats{}, handleError(err)
+	}
+
+	seriesQuery := `WITH bucket AS (
+			SELECT start, GREATEST(start, $1::timestamptz) AS since, start + ('1 ' || $2)::interval AS until
+			FROM generate_series(date_trunc($2, $1::timestamptz), NOW(), ('1 ' || $2)::interval) AS start
+		)
+		SELECT bucket.start,
+			(SELECT COUNT(*) FROM "user" WHERE created_at >= bucket.since AND created_at < bucket.until) AS registrations,
+			(SELECT COUNT(DISTINCT user_id) FROM login_attempt WHERE succeeded AND created_at >= bucket.since AND created_at < bucket.until) AS active_users,
+			(SELECT COUNT(*) FROM post WHERE status = 'published' AND created_at >= bucket.since AND created_at < bucket.until) AS posts_published,
+			(SELECT COUNT(*) FROM login_attempt WHERE NOT succeeded AND created_at >= bucket.since AND created_at < bucket.until) AS login_failures
+		FROM bucket ORDER BY bucket.start`
+
+	err = readConn(ctx, r.db).SelectContext(ctx, &stats.Series, seriesQuery, since, interval)
+	if err != nil {
+		return PlatformStats{}, handleError(err)
+	}
+
+	return stats, nil
+}
@@ -1,33 +1,65 @@
 package repository
 
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrAPITokenNotFound = errors.New("api token not found")
+)
+
 type RefreshToken struct {
 	ID     int
 	UserID int `db:"user_id"`
 	Token  string
+	// ExpiresAt is when the token stops being valid anyway, after which it no longer has to be blacklisted.
+	ExpiresAt time.Time `db:"expires_at"`
 }
 
-func (r *UserRepository) InsertRefreshToken(token RefreshToken) error {
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+func (r *UserRepository) InsertRefreshToken(ctx context.Context, token RefreshToken) error {
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, "INSERT INTO token_blacklist (user_id, token) VALUES ($1, $2)", token.UserID, token.Token)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO token_blacklist (user_id, token, expires_at) VALUES ($1, $2, $3)", token.UserID, token.Token, token.ExpiresAt)
 	return r.handleError(err)
 }
 
-func (r *UserRepository) IsRefreshTokenBlacklisted(userId int, token string) (bool, error) {
+func (r *UserRepository) IsRefreshTokenBlacklisted(ctx context.Context, userId int, token string) (bool, error) {
 	var tok RefreshToken
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	err := r.db.GetContext(ctx, &tok, "SELECT user_id, token FROM token_blacklist WHERE user_id = $1 AND token = $2", userId, token)
+	err := conn(ctx, r.db).GetContext(ctx, &tok, "SELECT user_id, token FROM token_blacklist WHERE user_id = $1 AND token = $2", userId, token)
 	if err != nil {
-		return false, r.handleError(err)
+		err = handleError(err)
+		if errors.Is(err, ErrNotFound) {
+			return false, nil
+		}
+
+		return false, err
 	}
 
 	return true, nil
 }
 
+// DeleteExpiredRefreshTokens removes blacklisted refresh tokens which have expired, since they're rejected without
+// checking the blacklist.
+func (r *UserRepository) DeleteExpiredRefreshTokens(ctx context.Context) (int, error) {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM token_blacklist WHERE expires_at < NOW()")
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), r.handleError(err)
+}
+
 type PasswordResetToken struct {
 	ID     int
 	UserID int `db:"user_id"`
@@ -35,21 +67,21 @@ type PasswordResetToken struct {
 	Expiry int64
 }
 
-func (r *UserRepository) InsertPasswordResetToken(token PasswordResetToken) error {
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+func (r *UserRepository) InsertPasswordResetToken(ctx context.Context, token PasswordResetToken) error {
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, "INSERT INTO password_reset_token (user_id, token, expiry) VALUES ($1, $2, $3)", token.UserID, token.Token, token.Expiry)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO password_reset_token (user_id, token, expiry) VALUES ($1, $2, $3)", token.UserID, token.Token, token.Expiry)
 	return r.handleError(err)
 }
 
-func (r *UserRepository) GetPasswordResetToken(token string) (PasswordResetToken, error) {
+func (r *UserRepository) GetPasswordResetToken(ctx context.Context, token string) (PasswordResetToken, error) {
 	var tok PasswordResetToken
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	err := r.db.GetContext(ctx, &tok, "SELECT id, user_id, token, expiry FROM password_reset_token WHERE token = $1", token)
+	err := conn(ctx, r.db).GetContext(ctx, &tok, "SELECT id, user_id, token, expiry FROM password_reset_token WHERE token = $1", token)
 	if err != nil {
 		return PasswordResetToken{}, r.handleError(err)
 	}
@@ -57,18 +89,181 @@ func (r *UserRepository) GetPasswordResetToken(token string) (PasswordResetToken
 	return tok, nil
 }
 
-func (r *UserRepository) DeleteAllPasswordResetTokensForUser(userId int) error {
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+func (r *UserRepository) DeleteAllPasswordResetTokensForUser(ctx context.Context, userId int) error {
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, "DELETE FROM password_reset_token WHERE user_id = $1", userId)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM password_reset_token WHERE user_id = $1", userId)
 	return r.handleError(err)
 }
 
-func (r *UserRepository) DeletePasswordResetToken(token string) error {
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+func (r *UserRepository) DeletePasswordResetToken(ctx context.Context, token string) error {
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, "DELETE FROM password_reset_token WHERE token = $1", token)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM password_reset_token WHERE token = $1", token)
 	return r.handleError(err)
 }
+
+func (r *UserRepository) DeleteExpiredPasswordResetTokens(ctx context.Context) (int, error) {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM password_reset_token WHERE expiry < EXTRACT(EPOCH FROM NOW())")
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), r.handleError(err)
+}
+
+// APIToken is a read-only token which can be used to read a user's public data. Only a hash of the token is stored.
+type APIToken struct {
+	ID         int
+	UserID     int `db:"user_id"`
+	Name       string
+	TokenHash  string     `db:"token_hash"`
+	CreatedAt  time.Time  `db:"created_at"`
+	LastUsedAt *time.Time `db:"last_used_at"`
+}
+
+func (r *UserRepository) InsertAPIToken(ctx context.Context, token APIToken) (APIToken, error) {
+	var newToken APIToken
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &newToken, "INSERT INTO api_token (user_id, name, token_hash) VALUES ($1, $2, $3) RETURNING *", token.UserID, token.Name, token.TokenHash)
+	if err != nil {
+		return APIToken{}, handleError(err)
+	}
+
+	return newToken, nil
+}
+
+func (r *UserRepository) FindAPITokensByUserID(ctx context.Context, userId int) ([]APIToken, error) {
+	var tokens []APIToken
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).SelectContext(ctx, &tokens, "SELECT * FROM api_token WHERE user_id = $1 ORDER BY id", userId)
+	if err != nil {
+		return nil, handleError(err)
+	}
+
+	return tokens, nil
+}
+
+// UseAPIToken finds the token by its hash and updates the time it was last used.
+func (r *UserRepository) UseAPIToken(ctx context.Context, tokenHash string) (APIToken, error) {
+	var token APIToken
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &token, "UPDATE api_token SET last_used_at = NOW() WHERE token_hash = $1 RETURNING *", tokenHash)
+	if err != nil {
+		return APIToken{}, handleError(err)
+	}
+
+	return token, nil
+}
+
+func (r *UserRepository) DeleteAPIToken(ctx context.Context, userId, tokenId int) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM api_token WHERE id = $1 AND user_id = $2", tokenId, userId)
+	if err != nil {
+		return handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return handleError(err)
+	}
+
+	if affected == 0 {
+		return ErrAPITokenNotFound
+	}
+
+	return nil
+}
+
+var ErrVerificationTokenNotFound = errors.New("email verification token not found")
+
+type EmailVerificationToken struct {
+	ID        int
+	UserID    int    `db:"user_id"`
+	TokenHash string `db:"token_hash"`
+	Expiry    int64
+}
+
+// InsertVerificationToken replaces the user's previous verification tokens, so only the link
+// from the most recent email works, and records when it was sent.
+func (r *UserRepository) InsertVerificationToken(ctx context.Context, token EmailVerificationToken) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := inTx(ctx, r.db, func(q querier) error {
+		_, err := q.ExecContext(ctx, "DELETE FROM email_verification_token WHERE user_id = $1", token.UserID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ExecContext(ctx, "INSERT INTO email_verification_token (user_id, token_hash, expiry) VALUES ($1, $2, $3)", token.UserID, token.TokenHash, token.Expiry)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ExecContext(ctx, "UPDATE \"user\" SET verification_sent_at = NOW() WHERE id = $1", token.UserID)
+		return err
+	})
+
+	return r.handleError(err)
+}
+
+func (r *UserRepository) GetVerificationToken(ctx context.Context, tokenHash string) (EmailVerificationToken, error) {
+	var tok EmailVerificationToken
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &tok, "SELECT id, user_id, token_hash, expiry FROM email_verification_token WHERE token_hash = $1", tokenHash)
+	if err != nil {
+		err = handleError(err)
+		if errors.Is(err, ErrNotFound) {
+			return EmailVerificationToken{}, ErrVerificationTokenNotFound
+		}
+
+		return EmailVerificationToken{}, err
+	}
+
+	return tok, nil
+}
+
+// MarkEmailVerified marks the user's email as verified and deletes their verification tokens.
+// Users who are already verified keep their original verification time.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userId int) (time.Time, error) {
+	var verifiedAt time.Time
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := inTx(ctx, r.db, func(q querier) error {
+		err := q.GetContext(ctx, &verifiedAt, "UPDATE \"user\" SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW() WHERE id = $1 RETURNING email_verified_at", userId)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.ExecContext(ctx, "DELETE FROM email_verification_token WHERE user_id = $1", userId)
+		return err
+	})
+	if err != nil {
+		return time.Time{}, r.handleError(err)
+	}
+
+	return verifiedAt, nil
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"github.com/jmoiron/sqlx"
+)
+
+type txKey struct{}
+
+// querier is implemented by both *sqlx.DB and *sqlx.Tx, so queries don't care whether they run in a transaction.
+type querier interface {
+	sqlx.ExtContext
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+}
+
+// conn returns the transaction started by TxManager.WithinTx if ctx carries one, so repository methods
+// called inside it take part in it, and the primary database otherwise.
+func conn(ctx context.Context, db *DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return db.observe(tx)
+	}
+
+	return db.observe(db.primary)
+}
+
+// readConn is conn for reads which may be served by a replica. It must not be used when the result has
+// to reflect a write made just before, since the replica may lag behind the primary.
+func readConn(ctx context.Context, db *DB) querier {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return db.observe(tx)
+	}
+
+	return db.observe(db.reader())
+}
+
+// inTx runs fn in the caller's transaction if there is one and in a new transaction otherwise. It's used
+// by repository methods which consist of several statements that have to be applied together.
+func inTx(ctx context.Context, db *DB, fn func(q querier) error) error {
+	if tx, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(db.observe(tx))
+	}
+
+	tx, err := db.primary.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = fn(db.observe(tx))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// TxManager runs operations spanning several repositories atomically.
+type TxManager struct {
+	db *DB
+}
+
+func NewTxManager(db *DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx runs fn in a transaction, which is committed if fn returns nil and rolled back otherwise.
+// Repository methods only take part in the transaction when they're called with the context passed
+// to fn. Calling WithinTx inside fn joins the outer transaction instead of starting a new one.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sqlx.Tx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := m.db.primary.BeginTxx(ctx, nil)
+	if err != nil {
+		return handleError(err)
+	}
+	defer tx.Rollback()
+
+	err = fn(context.WithValue(ctx, txKey{}, tx))
+	if err != nil {
+		return err
+	}
+
+	return handleError(tx.Commit())
+}
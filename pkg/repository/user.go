@@ -1,20 +1,19 @@
 package repository
 
 import (
+	"context"
 	"errors"
-	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
+	"time"
 )
 
 type UserRepository struct {
-	db *sqlx.DB
+	db *DB
 }
 
 const (
-	normalRole = iota + 1
-	moderatorRole
-	adminRole
 	defaultActiveState = true
+	defaultLocale      = "en"
 )
 
 var (
@@ -23,16 +22,38 @@ var (
 )
 
 type User struct {
-	ID        int
+	ID int
+	// PublicID identifies the user in the API, so users can't be enumerated by incrementing ids.
+	PublicID  string `db:"public_id"`
 	Username  string
 	Email     string
 	Password  string
 	MFASecret []byte `db:"mfa_secret"`
 	Role      string
 	Active    bool
+	// Verified is the badge admins give to notable authors, unrelated to the email address being verified.
+	Verified bool
+	// Shadowbanned users keep seeing their posts, but nobody else does.
+	Shadowbanned bool
+
+	EmailVerifiedAt    *time.Time `db:"email_verified_at"`
+	VerificationSentAt *time.Time `db:"verification_sent_at"`
+	// VerificationReminderSentAt is when the user was reminded to verify their address, at most once.
+	VerificationReminderSentAt *time.Time `db:"verification_reminder_sent_at"`
+	// EmailUndeliverableAt is set once the email provider reports a permanent bounce or a spam complaint for the
+	// address, which isn't emailed anymore.
+	EmailUndeliverableAt     *time.Time `db:"email_undeliverable_at"`
+	EmailUndeliverableReason *string    `db:"email_undeliverable_reason"`
+	// Locale is the language the user is emailed in.
+	Locale    string
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
 }
 
-func NewUserRepository(db *sqlx.DB) *UserRepository {
+// userColumns are the columns of a User selected from "user" joined with role.
+const userColumns = `"user".id, "user".public_id, username, email, password, mfa_secret, active, verified, shadowbanned, email_verified_at, verification_sent_at, verification_reminder_sent_at, email_undeliverable_at, email_undeliverable_reason, locale, "user".created_at, "user".updated_at, role.name as role`
+
+func NewUserRepository(db *DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
@@ -49,13 +70,18 @@ func (r *UserRepository) handleError(err error) error {
 	}
 }
 
-func (r *UserRepository) InsertUser(user User) (int, error) {
+func (r *UserRepository) InsertUser(ctx context.Context, user User) (int, error) {
 	var id int
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	err := r.db.GetContext(ctx, &id, "INSERT INTO \"user\" (username, email, password, role, active) VALUES ($1, $2, $3, $4, $5) RETURNING id", user.Username, user.Email, user.Password, normalRole, defaultActiveState)
+	locale := user.Locale
+	if locale == "" {
+		locale = defaultLocale
+	}
+
+	err := conn(ctx, r.db).GetContext(ctx, &id, "INSERT INTO \"user\" (username, email, password, role, active, locale) VALUES ($1, $2, $3, (SELECT id FROM role WHERE name = $4), $5, $6) RETURNING id", user.Username, user.Email, user.Password, user.Role, defaultActiveState, locale)
 	if err != nil {
 		return 0, r.handleError(err)
 	}
@@ -63,11 +89,59 @@ func (r *UserRepository) InsertUser(user User) (int, error) {
 	return id, nil
 }
 
-func (r *UserRepository) DeleteUserByID(userId int) error {
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+func (r *UserRepository) DeleteUserByID(ctx context.Context, userId int) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM \"user\" WHERE id = $1", userId)
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) InsertMfaSecret(ctx context.Context, userId int, secret []byte, recoveryCodes []string) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE \"user\" SET mfa_secret = $1, recovery = $2, updated_at = NOW() WHERE id = $3", secret, pq.Array(recoveryCodes), userId)
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) SetPassword(ctx context.Context, userId int, password string) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE \"user\" SET password = $1, updated_at = NOW() WHERE id = $2", password, userId)
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) SetRecoveryCodes(ctx context.Context, userId int, recoveryCodes []string) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE \"user\" SET recovery = $1 WHERE id = $2", pq.Array(recoveryCodes), userId)
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	return nil
+}
+
+func (r *UserRepository) SetActiveState(ctx context.Context, userId int, active bool) error {
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, "DELETE FROM \"user\" WHERE id = $1", userId)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE \"user\" SET active = $1, updated_at = NOW() WHERE id = $2", active, userId)
 	if err != nil {
 		return r.handleError(err)
 	}
@@ -75,11 +149,11 @@ func (r *UserRepository) DeleteUserByID(userId int) error {
 	return nil
 }
 
-func (r *UserRepository) InsertMfaSecret(userId int, secret []byte, recoveryCodes []string) error {
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+func (r *UserRepository) SetLocale(ctx context.Context, userId int, locale string) error {
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, "UPDATE \"user\" SET mfa_secret = $1, recovery = $2 WHERE id = $3", secret, pq.Array(recoveryCodes), userId)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE \"user\" SET locale = $1, updated_at = NOW() WHERE id = $2", locale, userId)
 	if err != nil {
 		return r.handleError(err)
 	}
@@ -87,11 +161,11 @@ func (r *UserRepository) InsertMfaSecret(userId int, secret []byte, recoveryCode
 	return nil
 }
 
-func (r *UserRepository) SetPassword(userId int, password string) error {
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+func (r *UserRepository) SetVerified(ctx context.Context, userId int, verified bool) error {
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, "UPDATE \"user\" SET password = $1 WHERE id = $2", password, userId)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE \"user\" SET verified = $1, updated_at = NOW() WHERE id = $2", verified, userId)
 	if err != nil {
 		return r.handleError(err)
 	}
@@ -99,11 +173,11 @@ func (r *UserRepository) SetPassword(userId int, password string) error {
 	return nil
 }
 
-func (r *UserRepository) SetRecoveryCodes(userId int, recoveryCodes []string) error {
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+func (r *UserRepository) SetShadowbanned(ctx context.Context, userId int, shadowbanned bool) error {
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, "UPDATE \"user\" SET recovery = $1 WHERE id = $2", pq.Array(recoveryCodes), userId)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE \"user\" SET shadowbanned = $1, updated_at = NOW() WHERE id = $2", shadowbanned, userId)
 	if err != nil {
 		return r.handleError(err)
 	}
@@ -111,11 +185,12 @@ func (r *UserRepository) SetRecoveryCodes(userId int, recoveryCodes []string) er
 	return nil
 }
 
-func (r *UserRepository) SetActiveState(userId int, active bool) error {
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+// SetRole moves the user to an existing role.
+func (r *UserRepository) SetRole(ctx context.Context, userId int, role string) error {
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	_, err := r.db.ExecContext(ctx, "UPDATE \"user\" SET active = $1 WHERE id = $2", active, userId)
+	_, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE \"user\" SET role = (SELECT id FROM role WHERE name = $1), updated_at = NOW() WHERE id = $2", role, userId)
 	if err != nil {
 		return r.handleError(err)
 	}
@@ -123,13 +198,16 @@ func (r *UserRepository) SetActiveState(userId int, active bool) error {
 	return nil
 }
 
-func (r *UserRepository) FindUserByID(id int) (User, error) {
+const findUserByIDQuery = "SELECT " + userColumns + " FROM \"user\" INNER JOIN role ON \"user\".role = role.id WHERE \"user\".id = $1"
+
+func (r *UserRepository) FindUserByID(ctx context.Context, id int) (User, error) {
 	var user User
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	err := r.db.GetContext(ctx, &user, "SELECT \"user\".id, username, email, password, mfa_secret, active, role.name as role FROM \"user\" INNER JOIN role ON \"user\".role = role.id WHERE \"user\".id = $1", id)
+	// every authenticated request looks up its user, so the statement is prepared once and reused
+	err := getPrepared(ctx, r.db, &user, findUserByIDQuery, id)
 	if err != nil {
 		return User{}, r.handleError(err)
 	}
@@ -137,13 +215,13 @@ func (r *UserRepository) FindUserByID(id int) (User, error) {
 	return user, nil
 }
 
-func (r *UserRepository) FindUserByUsername(username string) (User, error) {
+func (r *UserRepository) FindUserByPublicID(ctx context.Context, publicId string) (User, error) {
 	var user User
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	err := r.db.GetContext(ctx, &user, "SELECT \"user\".id, username, email, password, mfa_secret FROM \"user\" WHERE username = $1", username)
+	err := conn(ctx, r.db).GetContext(ctx, &user, "SELECT "+userColumns+" FROM \"user\" INNER JOIN role ON \"user\".role = role.id WHERE \"user\".public_id = $1", publicId)
 	if err != nil {
 		return User{}, r.handleError(err)
 	}
@@ -151,13 +229,13 @@ func (r *UserRepository) FindUserByUsername(username string) (User, error) {
 	return user, nil
 }
 
-func (r *UserRepository) FindUserByEmail(email string) (User, error) {
+func (r *UserRepository) FindUserByUsername(ctx context.Context, username string) (User, error) {
 	var user User
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	err := r.db.GetContext(ctx, &user, "SELECT \"user\".id, username, email, password, mfa_secret FROM \"user\" WHERE email = $1", email)
+	err := conn(ctx, r.db).GetContext(ctx, &user, "SELECT "+userColumns+" FROM \"user\" INNER JOIN role ON \"user\".role = role.id WHERE username = $1", username)
 	if err != nil {
 		return User{}, r.handleError(err)
 	}
@@ -165,13 +243,42 @@ func (r *UserRepository) FindUserByEmail(email string) (User, error) {
 	return user, nil
 }
 
-func (r *UserRepository) GetUserRecoveryCodes(username string) ([]string, error) {
+// FindUsersByUsernames returns the users with any of the usernames, usernames nobody has are skipped.
+func (r *UserRepository) FindUsersByUsernames(ctx context.Context, usernames []string) ([]User, error) {
+	var users []User
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).SelectContext(ctx, &users, "SELECT "+userColumns+" FROM \"user\" INNER JOIN role ON \"user\".role = role.id WHERE username = ANY($1)", pq.Array(usernames))
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) FindUserByEmail(ctx context.Context, email string) (User, error) {
+	var user User
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &user, "SELECT "+userColumns+" FROM \"user\" INNER JOIN role ON \"user\".role = role.id WHERE email = $1", email)
+	if err != nil {
+		return User{}, r.handleError(err)
+	}
+
+	return user, nil
+}
+
+func (r *UserRepository) GetUserRecoveryCodes(ctx context.Context, username string) ([]string, error) {
 	var recoveryCodes []string
 
-	ctx, cancel := newBackgroundContext(DefaultQueryTimeout)
+	ctx, cancel := r.db.queryContext(ctx)
 	defer cancel()
 
-	row := r.db.QueryRowxContext(ctx, "SELECT recovery FROM \"user\" WHERE username = $1", username)
+	row := conn(ctx, r.db).QueryRowxContext(ctx, "SELECT recovery FROM \"user\" WHERE username = $1", username)
 	err := row.Scan(pq.Array(&recoveryCodes))
 	if err != nil {
 		return nil, r.handleError(err)
@@ -179,3 +286,191 @@ func (r *UserRepository) GetUserRecoveryCodes(username string) ([]string, error)
 
 	return recoveryCodes, nil
 }
+
+func (r *UserRepository) FindUsersWithMfaSecret(ctx context.Context) ([]User, error) {
+	var users []User
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).SelectContext(ctx, &users, "SELECT id, username, mfa_secret FROM \"user\" WHERE mfa_secret IS NOT NULL")
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return users, nil
+}
+
+// ReplaceMfaSecret swaps the user's MFA secret for secret, unless it's no longer oldSecret because the user set up MFA
+// again in the meantime. It returns false if the secret wasn't replaced.
+func (r *UserRepository) ReplaceMfaSecret(ctx context.Context, userId int, oldSecret, secret []byte) (bool, error) {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "UPDATE \"user\" SET mfa_secret = $1 WHERE id = $2 AND mfa_secret = $3", secret, userId, oldSecret)
+	if err != nil {
+		return false, r.handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, r.handleError(err)
+}
+
+func (r *UserRepository) RoleExists(ctx context.Context, name string) (bool, error) {
+	var exists bool
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &exists, "SELECT EXISTS(SELECT 1 FROM role WHERE name = $1)", name)
+	if err != nil {
+		return false, r.handleError(err)
+	}
+
+	return exists, nil
+}
+
+// EnsureRole creates the role if it doesn't exist yet and reports whether it was created.
+func (r *UserRepository) EnsureRole(ctx context.Context, name string) (bool, error) {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "INSERT INTO role (name) SELECT $1::text WHERE NOT EXISTS (SELECT 1 FROM role WHERE name = $1)", name)
+	if err != nil {
+		return false, r.handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, r.handleError(err)
+	}
+
+	return affected > 0, nil
+}
+
+func (r *UserRepository) CountUsersWithRole(ctx context.Context, name string) (int, error) {
+	var count int
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &count, "SELECT COUNT(*) FROM \"user\" INNER JOIN role ON \"user\".role = role.id WHERE role.name = $1", name)
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	return count, nil
+}
+
+// PromoteUsers moves every user with the fromRole who has at least minPosts published posts to the toRole. Held
+// posts don't count, so spam can't earn a promotion.
+func (r *UserRepository) PromoteUsers(ctx context.Context, fromRole, toRole string, minPosts int) ([]User, error) {
+	var users []User
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `UPDATE "user" SET role = (SELECT id FROM role WHERE name = $2), updated_at = NOW()
+		WHERE role = (SELECT id FROM role WHERE name = $1)
+		AND (SELECT COUNT(*) FROM post WHERE post.user_id = "user".id AND post.status = $4) >= $3
+		RETURNING id, username`
+
+	err := conn(ctx, r.db).SelectContext(ctx, &users, query, fromRole, toRole, minPosts, PostStatusPublished)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return users, nil
+}
+
+// MarkEmailUndeliverable marks the address of every user with the email as undeliverable, reason is mailer.EventBounce
+// or mailer.EventComplaint. Addresses which are already marked keep their first reason. It returns how many users
+// were marked.
+func (r *UserRepository) MarkEmailUndeliverable(ctx context.Context, email, reason string) (int, error) {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE "user" SET email_undeliverable_at = NOW(), email_undeliverable_reason = $2, updated_at = NOW()
+		WHERE LOWER(email) = LOWER($1) AND email_undeliverable_at IS NULL`, email, reason)
+	if err != nil {
+		return 0, r.handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	return int(affected), r.handleError(err)
+}
+
+// IsEmailUndeliverable reports whether any user with the email has it marked as undeliverable.
+func (r *UserRepository) IsEmailUndeliverable(ctx context.Context, email string) (bool, error) {
+	var undeliverable bool
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &undeliverable, `SELECT EXISTS(SELECT 1 FROM "user" WHERE LOWER(email) = LOWER($1) AND email_undeliverable_at IS NOT NULL)`, email)
+	if err != nil {
+		return false, r.handleError(err)
+	}
+
+	return undeliverable, nil
+}
+
+// FindUsersToRemind returns up to limit active users who registered before createdBefore, haven't verified their
+// address and haven't been reminded to, oldest first.
+func (r *UserRepository) FindUsersToRemind(ctx context.Context, createdBefore time.Time, limit int) ([]User, error) {
+	var users []User
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `SELECT ` + userColumns + ` FROM "user" INNER JOIN role ON "user".role = role.id
+		WHERE email_verified_at IS NULL AND verification_reminder_sent_at IS NULL AND active AND "user".created_at < $1
+		ORDER BY "user".created_at LIMIT $2`
+
+	err := conn(ctx, r.db).SelectContext(ctx, &users, query, createdBefore, limit)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return users, nil
+}
+
+// MarkVerificationReminderSent records that the user was reminded to verify their address. It returns false if they
+// already were, so a reminder isn't sent twice when several instances look for users to remind at the same time.
+func (r *UserRepository) MarkVerificationReminderSent(ctx context.Context, userId int) (bool, error) {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, `UPDATE "user" SET verification_reminder_sent_at = NOW()
+		WHERE id = $1 AND verification_reminder_sent_at IS NULL AND email_verified_at IS NULL`, userId)
+	if err != nil {
+		return false, r.handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, r.handleError(err)
+}
+
+// DeleteUnverifiedUsers deletes the active users who registered before createdBefore and never verified their address,
+// and returns them. If remindedBefore is set, only users who were reminded to verify it before then are deleted. Users
+// who wrote posts or joined an organization are kept, deleting them would cascade to content other people may rely on.
+func (r *UserRepository) DeleteUnverifiedUsers(ctx context.Context, createdBefore time.Time, remindedBefore *time.Time) ([]User, error) {
+	var users []User
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `DELETE FROM "user" USING role WHERE "user".role = role.id
+		AND email_verified_at IS NULL AND active AND "user".created_at < $1
+		AND ($2::timestamptz IS NULL OR verification_reminder_sent_at < $2)
+		AND NOT EXISTS (SELECT 1 FROM post WHERE post.user_id = "user".id)
+		AND NOT EXISTS (SELECT 1 FROM organization_member WHERE organization_member.user_id = "user".id)
+		RETURNING ` + userColumns
+
+	err := conn(ctx, r.db).SelectContext(ctx, &users, query, createdBefore, remindedBefore)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return users, nil
+}
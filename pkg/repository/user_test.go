@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestPromoteUsersCountsPublishedPosts(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	users := NewUserRepository(db)
+	posts := NewPostRepository(db)
+
+	// roles of their own keep the promotion away from any other users in the database
+	suffix := time.Now().UnixNano()
+	fromRole, toRole := fmt.Sprintf("promotion-from-%d", suffix), fmt.Sprintf("promotion-to-%d", suffix)
+	for _, role := range []string{fromRole, toRole} {
+		_, err := users.EnsureRole(ctx, role)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		db.primary.Exec("DELETE FROM role WHERE name = $1 OR name = $2", fromRole, toRole)
+	})
+
+	const minPosts = 2
+
+	tests := []struct {
+		name         string
+		statuses     []string
+		wantPromoted bool
+	}{
+		{"only published posts", []string{PostStatusPublished, PostStatusPublished}, true},
+		{"only held posts", []string{PostStatusHeld, PostStatusHeld, PostStatusHeld}, false},
+		{"held posts make up the minimum", []string{PostStatusPublished, PostStatusHeld}, false},
+		{"no posts", nil, false},
+	}
+
+	for i, tt := range tests {
+		name := fmt.Sprintf("promotion%d_%d", suffix, i)
+
+		userId, err := users.InsertUser(ctx, User{Username: name, Email: name + "@example.com", Password: "password", Role: fromRole})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { users.DeleteUserByID(context.Background(), userId) })
+
+		for _, status := range tt.statuses {
+			_, err := posts.InsertPost(ctx, Post{UserID: userId, Title: tt.name, Body: tt.name, Status: status})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	promoted, err := users.PromoteUsers(ctx, fromRole, toRole, minPosts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gotPromoted := map[int]bool{}
+	for _, user := range promoted {
+		gotPromoted[user.ID] = true
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			name := fmt.Sprintf("promotion%d_%d", suffix, i)
+
+			user, err := users.FindUserByUsername(ctx, name)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if gotPromoted[user.ID] != tt.wantPromoted {
+				t.Errorf("promoted = %v, want %v", gotPromoted[user.ID], tt.wantPromoted)
+			}
+
+			wantRole := fromRole
+			if tt.wantPromoted {
+				wantRole = toRole
+			}
+
+			if user.Role != wantRole {
+				t.Errorf("role = %q, want %q", user.Role, wantRole)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/lib/pq"
+	"time"
+)
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+type WebhookRepository struct {
+	db *DB
+}
+
+type Webhook struct {
+	ID        int
+	URL       string
+	Secret    string
+	Events    pq.StringArray
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int
+	WebhookID      int `db:"webhook_id"`
+	Event          string
+	Payload        json.RawMessage
+	Status         string
+	Attempts       int
+	ResponseStatus *int       `db:"response_status"`
+	LastError      string     `db:"last_error"`
+	CreatedAt      time.Time  `db:"created_at"`
+	DeliveredAt    *time.Time `db:"delivered_at"`
+}
+
+func NewWebhookRepository(db *DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) handleError(err error) error {
+	err = handleError(err)
+
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return ErrWebhookNotFound
+	default:
+		return err
+	}
+}
+
+func (r *WebhookRepository) InsertWebhook(ctx context.Context, webhook Webhook) (Webhook, error) {
+	var newWebhook Webhook
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &newWebhook, "INSERT INTO webhook (url, secret, events) VALUES ($1, $2, $3) RETURNING *", webhook.URL, webhook.Secret, webhook.Events)
+	if err != nil {
+		return Webhook{}, r.handleError(err)
+	}
+
+	return newWebhook, nil
+}
+
+func (r *WebhookRepository) FindWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).SelectContext(ctx, &webhooks, "SELECT * FROM webhook ORDER BY id")
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) FindWebhookByID(ctx context.Context, webhookId int) (Webhook, error) {
+	var webhook Webhook
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &webhook, "SELECT * FROM webhook WHERE id = $1", webhookId)
+	if err != nil {
+		return Webhook{}, r.handleError(err)
+	}
+
+	return webhook, nil
+}
+
+// FindWebhooksForEvent returns the webhooks which are subscribed to the event.
+func (r *WebhookRepository) FindWebhooksForEvent(ctx context.Context, event string) ([]Webhook, error) {
+	var webhooks []Webhook
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).SelectContext(ctx, &webhooks, "SELECT * FROM webhook WHERE $1 = ANY(events)", event)
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, webhookId int) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, "DELETE FROM webhook WHERE id = $1", webhookId)
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return r.handleError(err)
+	}
+
+	if affected == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) InsertDelivery(ctx context.Context, delivery WebhookDelivery) (WebhookDelivery, error) {
+	var newDelivery WebhookDelivery
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &newDelivery, "INSERT INTO webhook_delivery (webhook_id, event, payload) VALUES ($1, $2, $3) RETURNING *", delivery.WebhookID, delivery.Event, delivery.Payload)
+	if err != nil {
+		return WebhookDelivery{}, r.handleError(err)
+	}
+
+	return newDelivery, nil
+}
+
+func (r *WebhookRepository) FindDeliveryByID(ctx context.Context, deliveryId int) (WebhookDelivery, error) {
+	var delivery WebhookDelivery
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := conn(ctx, r.db).GetContext(ctx, &delivery, "SELECT * FROM webhook_delivery WHERE id = $1", deliveryId)
+	if err != nil {
+		err = handleError(err)
+		if errors.Is(err, ErrNotFound) {
+			return WebhookDelivery{}, ErrWebhookDeliveryNotFound
+		}
+
+		return WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+// RecordDeliveryAttempt stores the outcome of an attempt, responseStatus is nil if the endpoint couldn't be reached.
+func (r *WebhookRepository) RecordDeliveryAttempt(ctx context.Context, deliveryId int, status string, responseStatus *int, lastError string) error {
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	query := `UPDATE webhook_delivery SET status = $1, response_status = $2, last_error = $3, attempts = attempts + 1,
+		delivered_at = CASE WHEN $1 = 'succeeded' THEN NOW() ELSE delivered_at END
+		WHERE id = $4`
+
+	_, err := conn(ctx, r.db).ExecContext(ctx, query, status, responseStatus, lastError, deliveryId)
+	return r.handleError(err)
+}
+
+// FindDeliveries returns the deliveries of a webhook, newest first.
+func (r *WebhookRepository) FindDeliveries(ctx context.Context, webhookId int, status string, page, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+
+	ctx, cancel := r.db.queryContext(ctx)
+	defer cancel()
+
+	err := readConn(ctx, r.db).SelectContext(ctx, &deliveries, "SELECT * FROM webhook_delivery WHERE webhook_id = $1 AND ($2 = '' OR status = $2) ORDER BY id DESC LIMIT $3 OFFSET $4", webhookId, status, limit, calculateOffset(page, limit))
+	if err != nil {
+		return nil, r.handleError(err)
+	}
+
+	return deliveries, nil
+}
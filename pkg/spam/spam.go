@@ -0,0 +1,154 @@
+package spam
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Content is what gets checked for spam, along with what's known about its author and the request it was submitted with.
+type Content struct {
+	Author      string
+	AuthorEmail string
+	UserIP      string
+	UserAgent   string
+	Title       string
+	Body        string
+}
+
+// Checker reports whether content is spam. Content flagged as spam is held for moderation instead of being published.
+type Checker interface {
+	IsSpam(ctx context.Context, content Content) (bool, error)
+}
+
+var linkPattern = regexp.MustCompile(`(?i)https?://`)
+
+// Heuristic flags content with too many links or containing any of the blocked words. It doesn't depend on any
+// external service, so it's used when Akismet isn't configured or is unavailable.
+type Heuristic struct {
+	maxLinks     int
+	blockedWords []string
+}
+
+// NewHeuristic returns a Heuristic checker, a maxLinks of 0 or less doesn't limit the number of links.
+func NewHeuristic(maxLinks int, blockedWords []string) *Heuristic {
+	words := make([]string, 0, len(blockedWords))
+	for _, word := range blockedWords {
+		word = strings.ToLower(strings.TrimSpace(word))
+		if word != "" {
+			words = append(words, word)
+		}
+	}
+
+	return &Heuristic{maxLinks: maxLinks, blockedWords: words}
+}
+
+func (h *Heuristic) IsSpam(ctx context.Context, content Content) (bool, error) {
+	text := content.Title + "\n" + content.Body
+
+	if h.maxLinks > 0 && len(linkPattern.FindAllStringIndex(text, -1)) > h.maxLinks {
+		return true, nil
+	}
+
+	text = strings.ToLower(text)
+	for _, word := range h.blockedWords {
+		if strings.Contains(text, word) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+const akismetCommentCheckURL = "https://rest.akismet.com/1.1/comment-check"
+
+// Akismet checks content with the Akismet comment-check API.
+type Akismet struct {
+	key     string
+	blogURL string
+	client  *http.Client
+}
+
+// NewAkismet returns an Akismet checker, blogURL is the front page of the blog the key was issued for.
+func NewAkismet(key, blogURL string, timeout time.Duration) *Akismet {
+	return &Akismet{
+		key:     key,
+		blogURL: blogURL,
+		client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (a *Akismet) IsSpam(ctx context.Context, content Content) (bool, error) {
+	form := url.Values{
+		"api_key":              {a.key},
+		"blog":                 {a.blogURL},
+		"user_ip":              {content.UserIP},
+		"user_agent":           {content.UserAgent},
+		"comment_type":         {"blog-post"},
+		"comment_author":       {content.Author},
+		"comment_author_email": {content.AuthorEmail},
+		"comment_content":      {content.Title + "\n\n" + content.Body},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, akismetCommentCheckURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return false, err
+	}
+
+	// anything other than true or false means the request was rejected, the reason is in X-akismet-debug-help
+	switch strings.TrimSpace(string(body)) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	default:
+		return false, fmt.Errorf("akismet responded with status %d: %s", resp.StatusCode, resp.Header.Get("X-akismet-debug-help"))
+	}
+}
+
+type fallbackChecker struct {
+	primary  Checker
+	fallback Checker
+	onError  func(error)
+}
+
+// WithFallback returns a Checker asking primary first and fallback if primary fails, so content is still checked
+// while an external service is down. onError is called with every error of primary.
+func WithFallback(primary, fallback Checker, onError func(error)) Checker {
+	return &fallbackChecker{primary: primary, fallback: fallback, onError: onError}
+}
+
+func (f *fallbackChecker) IsSpam(ctx context.Context, content Content) (bool, error) {
+	spam, err := f.primary.IsSpam(ctx, content)
+	if err == nil {
+		return spam, nil
+	}
+
+	// the request is gone, there's no point in checking it any further
+	if errors.Is(ctx.Err(), context.Canceled) {
+		return false, err
+	}
+
+	f.onError(err)
+
+	return f.fallback.IsSpam(ctx, content)
+}
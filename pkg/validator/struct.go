@@ -0,0 +1,201 @@
+package validator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ValidateStruct checks the fields of s against their validate tags, see Struct.
+func ValidateStruct(s interface{}) (bool, map[string][]string) {
+	return New().ValidateStruct(s)
+}
+
+// ValidateStruct is the method form of ValidateStruct, for validators with translated messages.
+func (v *Validator) ValidateStruct(s interface{}) (bool, map[string][]string) {
+	v.Struct(s)
+
+	return v.IsValid()
+}
+
+// Struct checks the fields of s, a struct or a pointer to one, against the comma separated rules in their validate
+// tags and keys the errors by the fields' json names:
+//
+//	required    the field can't be empty or nil
+//	min=n       strings have at least n characters, slices and maps at least n items, numbers are at least n
+//	max=n       strings have at most n characters, slices and maps at most n items, numbers are at most n
+//	len=n       strings have exactly n characters, slices and maps exactly n items
+//	email       see Email
+//	url         see URL
+//	oneof=a b   the field is one of the space separated values
+//
+// n is either a number or the name of a limit passed to WithLimits. email, url and oneof accept empty strings, so
+// optional fields don't need to be checked separately. Nil pointers only fail required, the other rules apply to
+// the value they point to. Unknown rules and limits, and rules on kinds they don't apply to, panic, since a typo
+// in a tag would otherwise silently skip the check.
+func (v *Validator) Struct(s interface{}) {
+	value := reflect.Indirect(reflect.ValueOf(s))
+	t := value.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag, ok := field.Tag.Lookup("validate")
+		if !ok {
+			continue
+		}
+
+		key := field.Name
+		if name := strings.Split(field.Tag.Get("json"), ",")[0]; name != "" && name != "-" {
+			key = name
+		}
+
+		v.field(key, value.Field(i), strings.Split(tag, ","))
+	}
+}
+
+func (v *Validator) field(key string, value reflect.Value, rules []string) {
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			for _, rule := range rules {
+				if rule == "required" {
+					v.addErrorf(key, "%s must be provided", key)
+				}
+			}
+
+			return
+		}
+
+		value = value.Elem()
+	}
+
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+
+		switch name {
+		case "required":
+			if value.IsZero() && value.Kind() != reflect.Bool {
+				v.addErrorf(key, "%s must be provided", key)
+			}
+		case "min":
+			v.min(key, value, rule, v.intParam(rule, param))
+		case "max":
+			v.max(key, value, rule, v.intParam(rule, param))
+		case "len":
+			v.len(key, value, rule, v.intParam(rule, param))
+		case "email":
+			if value.String() != "" {
+				v.Email(key, value.String())
+			}
+		case "url":
+			if value.String() != "" {
+				v.URL(key, value.String())
+			}
+		case "oneof":
+			if value.String() != "" {
+				v.OneOf(key, value.String(), strings.Fields(param)...)
+			}
+		default:
+			panic(fmt.Sprintf("validator: unknown rule %q", rule))
+		}
+	}
+}
+
+func (v *Validator) min(key string, value reflect.Value, rule string, min int) {
+	switch kind := value.Kind(); {
+	case kind == reflect.String:
+		v.RequiredMin(key, value.String(), min)
+	case isLenKind(kind):
+		if value.Len() < min {
+			v.addErrorf(key, "%s must contain at least %d items", key, min)
+		}
+	case isIntKind(kind):
+		if value.Int() < int64(min) {
+			v.addErrorf(key, "%s must be at least %d", key, min)
+		}
+	case isUintKind(kind):
+		if min > 0 && value.Uint() < uint64(min) {
+			v.addErrorf(key, "%s must be at least %d", key, min)
+		}
+	case isFloatKind(kind):
+		if value.Float() < float64(min) {
+			v.addErrorf(key, "%s must be at least %d", key, min)
+		}
+	default:
+		panicUnsupportedKind(rule, kind)
+	}
+}
+
+func (v *Validator) max(key string, value reflect.Value, rule string, max int) {
+	switch kind := value.Kind(); {
+	case kind == reflect.String:
+		v.RequiredMax(key, value.String(), max)
+	case isLenKind(kind):
+		if value.Len() > max {
+			v.addErrorf(key, "%s must contain at most %d items", key, max)
+		}
+	case isIntKind(kind):
+		if value.Int() > int64(max) {
+			v.addErrorf(key, "%s must be at most %d", key, max)
+		}
+	case isUintKind(kind):
+		if max < 0 || value.Uint() > uint64(max) {
+			v.addErrorf(key, "%s must be at most %d", key, max)
+		}
+	case isFloatKind(kind):
+		if value.Float() > float64(max) {
+			v.addErrorf(key, "%s must be at most %d", key, max)
+		}
+	default:
+		panicUnsupportedKind(rule, kind)
+	}
+}
+
+func (v *Validator) len(key string, value reflect.Value, rule string, n int) {
+	switch kind := value.Kind(); {
+	case kind == reflect.String:
+		v.RequiredExact(key, value.String(), n)
+	case isLenKind(kind):
+		if value.Len() != n {
+			v.addErrorf(key, "%s must contain exactly %d items", key, n)
+		}
+	default:
+		panicUnsupportedKind(rule, kind)
+	}
+}
+
+func isLenKind(kind reflect.Kind) bool {
+	return kind == reflect.Slice || kind == reflect.Array || kind == reflect.Map
+}
+
+func isIntKind(kind reflect.Kind) bool {
+	return kind >= reflect.Int && kind <= reflect.Int64
+}
+
+func isUintKind(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uintptr
+}
+
+func isFloatKind(kind reflect.Kind) bool {
+	return kind == reflect.Float32 || kind == reflect.Float64
+}
+
+func panicUnsupportedKind(rule string, kind reflect.Kind) {
+	panic(fmt.Sprintf("validator: rule %q doesn't apply to %s", rule, kind))
+}
+
+// intParam returns the rule's parameter, which is a number or the name of a limit passed to WithLimits.
+func (v *Validator) intParam(rule, param string) int {
+	n, err := strconv.Atoi(param)
+	if err == nil {
+		return n
+	}
+
+	n, ok := v.limits[param]
+	if !ok {
+		panic(fmt.Sprintf("validator: rule %q needs an integer or a known limit", rule))
+	}
+
+	return n
+}
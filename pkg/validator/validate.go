@@ -1,18 +1,53 @@
 package validator
 
-import "fmt"
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// maxEmailLength is the longest address SMTP can deliver to.
+const maxEmailLength = 254
+
+// Translator translates the English error messages, see NewLocalized.
+type Translator interface {
+	Translate(message string) string
+}
+
+type english struct{}
+
+func (english) Translate(message string) string {
+	return message
+}
 
 type Validator struct {
-	errors []string
+	errors     map[string][]string
+	translator Translator
+	limits     map[string]int
 }
 
 func New() *Validator {
-	return &Validator{errors: []string{}}
+	return NewLocalized(english{})
+}
+
+// NewLocalized returns a validator whose error messages are translated by t. Only the messages are translated,
+// the errors are still keyed by the field names the client sent.
+func NewLocalized(t Translator) *Validator {
+	return &Validator{errors: map[string][]string{}, translator: t}
+}
+
+// WithLimits names the parameters of the min, max and len rules, so tags can refer to the constants the rest of
+// the code uses, e.g. validate:"max=maxTitleLength".
+func (v *Validator) WithLimits(limits map[string]int) *Validator {
+	v.limits = limits
+	return v
 }
 
 func (v *Validator) RequiredMax(key, value string, max int) {
 	if len(value) > max {
-		v.addError(fmt.Sprintf("%s cannot be longer than %d characters", key, max))
+		v.addErrorf(key, "%s cannot be longer than %d characters", key, max)
 	}
 }
 
@@ -23,17 +58,58 @@ func (v *Validator) RequiredRange(key, value string, min, max int) {
 
 func (v *Validator) RequiredExact(key, value string, n int) {
 	if len(value) != n {
-		v.addError(fmt.Sprintf("%s must be exactly %d characters long", key, n))
+		v.addErrorf(key, "%s must be exactly %d characters long", key, n)
 	}
 }
 
 func (v *Validator) RequiredMin(key, value string, min int) {
 	if len(value) < min {
-		v.addError(fmt.Sprintf("%s must be at least %d characters long", key, min))
+		v.addErrorf(key, "%s must be at least %d characters long", key, min)
+	}
+}
+
+// URL checks that the value is an absolute http or https URL.
+func (v *Validator) URL(key, value string) {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		v.addErrorf(key, "%s must be an absolute http or https url", key)
+	}
+}
+
+// Matches checks that the value matches the pattern, format describes the pattern to the client, e.g. "may only
+// contain digits".
+func (v *Validator) Matches(key, value string, pattern *regexp.Regexp, format string) {
+	if !pattern.MatchString(value) {
+		v.addErrorf(key, "%s %s", key, v.translator.Translate(format))
+	}
+}
+
+func (v *Validator) IntRange(key string, value, min, max int) {
+	if value < min || value > max {
+		v.addErrorf(key, "%s must be between %d and %d", key, min, max)
+	}
+}
+
+func (v *Validator) OneOf(key, value string, allowed ...string) {
+	for _, a := range allowed {
+		if value == a {
+			return
+		}
+	}
+
+	v.addErrorf(key, "%s must be one of: %s", key, strings.Join(allowed, ", "))
+}
+
+// Email checks that the value is a plain email address, without a display name like "Name <name@example.com>".
+func (v *Validator) Email(key, value string) {
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Address != value || len(value) > maxEmailLength {
+		v.addErrorf(key, "%s must be a valid email address", key)
 	}
 }
 
-func (v *Validator) IsValid() (bool, []string) {
+// IsValid returns the errors keyed by the name of the invalid field, so clients can show them next to it.
+func (v *Validator) IsValid() (bool, map[string][]string) {
 	if len(v.errors) > 0 {
 		return false, v.errors
 	}
@@ -41,6 +117,7 @@ func (v *Validator) IsValid() (bool, []string) {
 	return true, nil
 }
 
-func (v *Validator) addError(err string) {
-	v.errors = append(v.errors, err)
+// addErrorf translates the format before formatting it, so the catalogs don't have to contain every key and value.
+func (v *Validator) addErrorf(key, format string, args ...any) {
+	v.errors[key] = append(v.errors[key], fmt.Sprintf(v.translator.Translate(format), args...))
 }
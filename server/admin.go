@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @Summary Re-encrypts every MFA secret with the current AES key.
+// @Description The job runs in the background job queue, once it's completed the previous keys can be removed from AES_PREVIOUS_KEYS.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 202 {object} messageResponse
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/mfa/reencrypt [post]
+func (s *Server) reencryptMfaSecretsHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	job, err := s.Queue.Enqueue(c.Request.Context(), jobTypeMfaReencrypt, nil)
+	if err != nil {
+		s.logger(c).Error("couldn't enqueue mfa secret re-encryption", zap.Error(err))
+		s.internalServerErrorResponse(c)
+		return
+	}
+
+	s.logger(c).Info("mfa secret re-encryption enqueued", zap.String("username", user.Username), zap.Int("jobId", job.ID))
+	s.audit(c, auditActionMfaReencrypt, objectUser, user.ID, nil, gin.H{"aes_key_id": s.Config.AESKeyID, "job_id": job.ID})
+
+	s.acceptedResponse(c, "mfa secret re-encryption has been started")
+}
+
+// reencryptMfaSecrets is idempotent, secrets which are already encrypted with the current key are
+// skipped, so the job can be retried safely when some of the secrets couldn't be re-encrypted.
+func (s *Server) reencryptMfaSecrets(ctx context.Context, logger *zap.Logger) error {
+	users, err := s.UserRepository.FindUsersWithMfaSecret(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't get users with mfa secrets: %w", err)
+	}
+
+	var rotated, skipped, failed int
+	for _, user := range users {
+		if !s.keyring.needsRotation(user.MFASecret) {
+			continue
+		}
+
+		secret, err := s.decryptMfaSecret(user.MFASecret)
+		if err != nil {
+			logger.Error("couldn't decrypt secret", zap.Error(err), zap.Int("userId", user.ID))
+			failed++
+			continue
+		}
+
+		encryptedSecret, err := s.encryptMfaSecret(secret)
+		if err != nil {
+			logger.Error("couldn't encrypt secret", zap.Error(err), zap.Int("userId", user.ID))
+			failed++
+			continue
+		}
+
+		replaced, err := s.UserRepository.ReplaceMfaSecret(ctx, user.ID, user.MFASecret, encryptedSecret)
+		if err != nil {
+			logger.Error("couldn't update secret", zap.Error(err), zap.Int("userId", user.ID))
+			failed++
+			continue
+		}
+
+		// the user set up MFA again since the secrets were read, the new secret is encrypted with the current key
+		if !replaced {
+			skipped++
+			continue
+		}
+
+		rotated++
+	}
+
+	logger.Info("mfa secret re-encryption finished", zap.Int("rotated", rotated), zap.Int("skipped", skipped), zap.Int("failed", failed))
+
+	if failed > 0 {
+		return fmt.Errorf("couldn't re-encrypt %d mfa secrets", failed)
+	}
+
+	return nil
+}
@@ -0,0 +1,149 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"go.uber.org/zap"
+	"net/http"
+	"os"
+	"time"
+)
+
+const (
+	alertDatabase     = "database"
+	alertQueueBacklog = "queue_backlog"
+	alertMailFailures = "mail_failures"
+
+	alertStatusFiring   = "firing"
+	alertStatusResolved = "resolved"
+)
+
+// operatorAlert is posted to OPERATOR_ALERT_URL. The text field makes it usable as a Slack incoming webhook.
+type operatorAlert struct {
+	Text      string    `json:"text"`
+	Condition string    `json:"condition"`
+	Status    string    `json:"status"`
+	Host      string    `json:"host"`
+	Time      time.Time `json:"time"`
+}
+
+// alertState remembers which conditions are firing, so operators get one alert when a condition
+// starts, a reminder every ALERT_REPEAT_INTERVAL while it lasts and one when it's resolved.
+type alertState struct {
+	firingSince map[string]time.Time
+	lastSent    map[string]time.Time
+}
+
+// runAlerts periodically checks the app's health and notifies the operators about critical conditions.
+// The alerts are sent directly rather than through the job queue, since the queue may be what's broken.
+func (s *Server) runAlerts() {
+	if s.Config.OperatorAlertURL == "" {
+		return
+	}
+
+	state := &alertState{firingSince: map[string]time.Time{}, lastSent: map[string]time.Time{}}
+
+	ticker := time.NewTicker(s.Config.AlertCheckInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.checkAlerts(state)
+	}
+}
+
+func (s *Server) checkAlerts(state *alertState) {
+	conditions := map[string]string{}
+
+	counts, err := s.JobRepository.CountJobsByStatus(context.Background())
+	if err != nil {
+		conditions[alertDatabase] = fmt.Sprintf("the database can't be queried: %s", err)
+	} else {
+		conditions[alertDatabase] = ""
+
+		pending := counts[repository.JobStatusPending]
+		if pending > s.Config.AlertQueueBacklog {
+			conditions[alertQueueBacklog] = fmt.Sprintf("%d jobs are waiting in the job queue", pending)
+		} else {
+			conditions[alertQueueBacklog] = ""
+		}
+
+		failed, err := s.JobRepository.CountJobsSince(context.Background(), jobTypeSendEmail, repository.JobStatusDead, time.Now().Add(-s.Config.AlertCheckInterval))
+		if err != nil {
+			s.Logger.Error("couldn't count failed emails", zap.Error(err))
+		} else if failed > 0 {
+			conditions[alertMailFailures] = fmt.Sprintf("%d emails couldn't be delivered after every retry", failed)
+		} else {
+			conditions[alertMailFailures] = ""
+		}
+	}
+
+	for condition, problem := range conditions {
+		s.updateAlert(state, condition, problem)
+	}
+}
+
+// updateAlert sends an alert if the condition changed, problem is empty when the condition is healthy.
+func (s *Server) updateAlert(state *alertState, condition, problem string) {
+	now := time.Now()
+	since, firing := state.firingSince[condition]
+
+	switch {
+	case problem != "" && (!firing || now.Sub(state.lastSent[condition]) >= s.Config.AlertRepeatInterval):
+		if !firing {
+			state.firingSince[condition] = now
+		}
+
+		s.sendOperatorAlert(condition, alertStatusFiring, problem)
+		state.lastSent[condition] = now
+	case problem == "" && firing:
+		delete(state.firingSince, condition)
+		delete(state.lastSent, condition)
+
+		s.sendOperatorAlert(condition, alertStatusResolved, fmt.Sprintf("%s has recovered after %s", condition, now.Sub(since).Round(time.Second)))
+	}
+}
+
+func (s *Server) sendOperatorAlert(condition, status, text string) {
+	hostname, _ := os.Hostname()
+
+	alert := operatorAlert{
+		Text:      fmt.Sprintf("[blog-api %s] %s: %s", s.Config.Environment, status, text),
+		Condition: condition,
+		Status:    status,
+		Host:      hostname,
+		Time:      time.Now().UTC(),
+	}
+
+	s.Logger.Warn("sending operator alert", zap.String("condition", condition), zap.String("status", status), zap.String("text", text))
+
+	body, err := json.Marshal(alert)
+	if err != nil {
+		s.Logger.Error("couldn't encode operator alert", zap.Error(err))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.Config.WebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Config.OperatorAlertURL, bytes.NewReader(body))
+	if err != nil {
+		s.Logger.Error("couldn't create operator alert request", zap.Error(err))
+		return
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		s.Logger.Error("couldn't send operator alert", zap.Error(err), zap.String("condition", condition))
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		s.Logger.Error("operator alert was rejected", zap.Int("status", resp.StatusCode), zap.String("condition", condition))
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxFooterTextLength = 500
+	maxLogoURLLength    = 2048
+)
+
+var accentColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+type appearanceResponse struct {
+	AccentColor string    `json:"accent_color"`
+	LogoURL     string    `json:"logo_url"`
+	FooterText  string    `json:"footer_text"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func newAppearanceResponse(appearance repository.Appearance) appearanceResponse {
+	return appearanceResponse{
+		AccentColor: appearance.AccentColor,
+		LogoURL:     appearance.LogoURL,
+		FooterText:  appearance.FooterText,
+		UpdatedAt:   appearance.UpdatedAt,
+	}
+}
+
+// @Summary Returns the appearance settings frontends should render the blog with.
+// @Tags appearance
+// @Accept json
+// @Produce json
+// @Success 200 {object} appearanceResponse
+// @Failure 500 {object} errorResponse
+// @Router /appearance [get]
+func (s *Server) getAppearanceHandler(c *gin.Context) {
+	appearance, err := s.SettingsRepository.GetAppearance(c.Request.Context())
+	if err != nil {
+		s.logger(c).Debug("couldn't get appearance", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, newAppearanceResponse(appearance))
+}
+
+type updateAppearanceRequest struct {
+	AccentColor string `json:"accent_color"`
+	LogoURL     string `json:"logo_url" validate:"max=maxLogoURLLength,url"`
+	FooterText  string `json:"footer_text" validate:"max=maxFooterTextLength"`
+}
+
+// @Summary Replaces the appearance settings.
+// @Description accent_color must be a hex color such as #1a2b3c, empty values reset a setting.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body updateAppearanceRequest true "Update appearance body"
+// @Security ApiKeyAuth
+// @Success 200 {object} appearanceResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/appearance [put]
+func (s *Server) updateAppearanceHandler(c *gin.Context) {
+	var request updateAppearanceRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	request.AccentColor = strings.TrimSpace(request.AccentColor)
+	request.LogoURL = strings.TrimSpace(request.LogoURL)
+	request.FooterText = strings.TrimSpace(request.FooterText)
+
+	ok, errors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	if request.AccentColor != "" && !accentColorRegex.MatchString(request.AccentColor) {
+		c.Error(ErrInvalidInput{"accent_color must be a hex color such as #1a2b3c"})
+		return
+	}
+
+	before, err := s.SettingsRepository.GetAppearance(c.Request.Context())
+	if err != nil {
+		s.logger(c).Debug("couldn't get appearance", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	appearance, err := s.SettingsRepository.UpdateAppearance(c.Request.Context(), repository.Appearance{
+		AccentColor: request.AccentColor,
+		LogoURL:     request.LogoURL,
+		FooterText:  request.FooterText,
+	})
+	if err != nil {
+		s.logger(c).Debug("couldn't update appearance", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	s.audit(c, auditActionAppearanceUpdate, objectAppearance, 0, newAppearanceResponse(before), newAppearanceResponse(appearance))
+
+	c.JSON(http.StatusOK, newAppearanceResponse(appearance))
+}
@@ -0,0 +1,201 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	auditActionUserDelete        = "user.delete"
+	auditActionUserBootstrap     = "user.bootstrap"
+	auditActionUserVerify        = "user.verify"
+	auditActionUserBadge         = "user.badge"
+	auditActionUserShadowban     = "user.shadowban"
+	auditActionUserPromote       = "user.promote"
+	auditActionUserBan           = "user.ban"
+	auditActionUserRoleChange    = "user.role_change"
+	auditActionUserPasswordReset = "user.password_reset"
+	auditActionUserRemind        = "user.verification_reminder"
+	auditActionUserPurge         = "user.purge"
+	auditActionPostDelete        = "post.delete"
+	auditActionPostEdit          = "post.edit"
+	auditActionPostApprove       = "post.approve"
+	auditActionMfaReencrypt      = "mfa.reencrypt"
+	auditActionJobRetry          = "job.retry"
+	auditActionWebhookCreate     = "webhook.create"
+	auditActionWebhookDelete     = "webhook.delete"
+	auditActionAppearanceUpdate  = "appearance.update"
+	auditActionMaintenanceUpdate = "maintenance.update"
+	auditActionReportResolve     = "report.resolve"
+	auditActionReportDismiss     = "report.dismiss"
+)
+
+type auditUser struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	Active   bool   `json:"active"`
+	Verified bool   `json:"verified"`
+
+	Shadowbanned  bool `json:"shadowbanned"`
+	EmailVerified bool `json:"email_verified"`
+}
+
+func newAuditUser(user repository.User) auditUser {
+	return auditUser{ID: user.ID, Username: user.Username, Email: user.Email, Role: user.Role, Active: user.Active, Verified: user.Verified, Shadowbanned: user.Shadowbanned, EmailVerified: user.EmailVerifiedAt != nil}
+}
+
+type auditPost struct {
+	ID     int    `json:"id"`
+	UserID int    `json:"user_id"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+	Status string `json:"status"`
+
+	CanonicalURL string `json:"canonical_url"`
+	License      string `json:"license"`
+}
+
+func newAuditPost(post repository.Post) auditPost {
+	return auditPost{ID: post.ID, UserID: post.UserID, Title: post.Title, Body: post.Body, Status: post.Status, CanonicalURL: post.CanonicalURL, License: post.License}
+}
+
+// audit records a privileged action performed by the user making the request.
+func (s *Server) audit(c *gin.Context, action, targetType string, targetId int, before, after any) {
+	actor := s.getUserFromContext(c)
+
+	s.recordAudit(&actor.ID, s.getRequestIDFromContext(c), action, targetType, targetId, before, after, "")
+}
+
+// auditWithReason is audit for actions which the actor has to justify.
+func (s *Server) auditWithReason(c *gin.Context, reason, action, targetType string, targetId int, before, after any) {
+	actor := s.getUserFromContext(c)
+
+	s.recordAudit(&actor.ID, s.getRequestIDFromContext(c), action, targetType, targetId, before, after, reason)
+}
+
+// recordAudit records an action, actorId should be nil for actions performed by the system itself.
+func (s *Server) recordAudit(actorId *int, requestId, action, targetType string, targetId int, before, after any, reason string) {
+	entry := repository.AuditLogEntry{
+		ActorID:    actorId,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetId,
+		Before:     s.auditSnapshot(before),
+		After:      s.auditSnapshot(after),
+		RequestID:  requestId,
+		Reason:     reason,
+	}
+
+	// The entry is written even if the client has gone away, the action it describes already happened.
+	err := s.AuditLogRepository.InsertEntry(context.Background(), entry)
+	if err != nil {
+		s.Logger.Error("couldn't insert audit log entry", zap.Error(err), zap.String("action", action), zap.Int("targetId", targetId))
+	}
+}
+
+func (s *Server) auditSnapshot(v any) *json.RawMessage {
+	if v == nil {
+		return nil
+	}
+
+	snapshot, err := json.Marshal(v)
+	if err != nil {
+		s.Logger.Error("couldn't marshal audit snapshot", zap.Error(err))
+		return nil
+	}
+
+	raw := json.RawMessage(snapshot)
+	return &raw
+}
+
+type auditLogEntryResponse struct {
+	ID         int              `json:"id"`
+	ActorID    *int             `json:"actor_id"`
+	Action     string           `json:"action"`
+	TargetType string           `json:"target_type"`
+	TargetID   int              `json:"target_id"`
+	Before     *json.RawMessage `json:"before" swaggertype:"object"`
+	After      *json.RawMessage `json:"after" swaggertype:"object"`
+	RequestID  string           `json:"request_id"`
+	Reason     string           `json:"reason,omitempty"`
+	CreatedAt  time.Time        `json:"created_at"`
+}
+
+// @Summary Returns the audit log of privileged actions, newest first.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int32 true "page"
+// @Param limit query int32 true "limit"
+// @Param actor_id query int false "only return actions performed by this user"
+// @Param action query string false "only return actions of this type, e.g. user.delete"
+// @Param target_type query string false "only return actions performed on this type of object, e.g. post"
+// @Param target_id query int false "only return actions performed on the object with this id"
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[auditLogEntryResponse]
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/audit [get]
+func (s *Server) getAuditLogHandler(c *gin.Context) {
+	page, limit, err := s.validatePageAndLimit(c)
+	if err != nil {
+		s.Logger.Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
+		return
+	}
+
+	filter := repository.AuditLogFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("target_type"),
+	}
+
+	if c.Query("actor_id") != "" {
+		filter.ActorID, err = strconv.Atoi(c.Query("actor_id"))
+		if err != nil {
+			c.Error(ErrInvalidInput{"actor_id must be an integer"})
+			return
+		}
+	}
+
+	if c.Query("target_id") != "" {
+		filter.TargetID, err = strconv.Atoi(c.Query("target_id"))
+		if err != nil {
+			c.Error(ErrInvalidInput{"target_id must be an integer"})
+			return
+		}
+	}
+
+	entries, err := s.AuditLogRepository.FindEntries(c.Request.Context(), filter, page, limit)
+	if err != nil {
+		s.Logger.Debug("couldn't find audit log entries", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	data := []auditLogEntryResponse{}
+	for _, entry := range entries {
+		data = append(data, auditLogEntryResponse{
+			ID:         entry.ID,
+			ActorID:    entry.ActorID,
+			Action:     entry.Action,
+			TargetType: entry.TargetType,
+			TargetID:   entry.TargetID,
+			Before:     entry.Before,
+			After:      entry.After,
+			RequestID:  entry.RequestID,
+			Reason:     entry.Reason,
+			CreatedAt:  entry.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, c, data, page, limit))
+}
@@ -0,0 +1,224 @@
+package server
+
+import (
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+const (
+	maxCoAuthors = 10
+)
+
+type postAuthorResponse struct {
+	UserID   string `json:"user_id"`
+	Username string `json:"username"`
+	Verified bool   `json:"verified"`
+	// CoAuthor is false for the post's owner.
+	CoAuthor  bool      `json:"co_author"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newPostAuthorsResponse(authors []repository.PostAuthor) []postAuthorResponse {
+	response := []postAuthorResponse{}
+	for _, author := range authors {
+		response = append(response, postAuthorResponse{
+			UserID:    author.UserPublicID,
+			Username:  author.Username,
+			Verified:  author.Verified,
+			CoAuthor:  author.CoAuthor,
+			CreatedAt: author.CreatedAt,
+		})
+	}
+
+	return response
+}
+
+// ownerUsername returns the username of the post's owner, authors are sorted with the owner first.
+func ownerUsername(authors []repository.PostAuthor) string {
+	if len(authors) == 0 || authors[0].CoAuthor {
+		return ""
+	}
+
+	return authors[0].Username
+}
+
+type addPostAuthorRequest struct {
+	Username string `json:"username" validate:"required"`
+}
+
+// @Summary Adds a co-author to a post, co-authors may edit the post but not delete it.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param postId path string true "post id"
+// @Param request body addPostAuthorRequest true "Co-author body"
+// @Security ApiKeyAuth
+// @Success 201 {object} listResponse[postAuthorResponse]
+// @Failure 400 {object} errorResponse "Input is invalid or the post has too many co-authors"
+// @Failure 403 {object} errorResponse "The access token is invalid or the user doesn't own the post"
+// @Failure 404 {object} errorResponse "The post or user doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /posts/{postId}/authors [post]
+func (s *Server) addPostAuthorHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	post, ok := s.ownPostFromParam(c, user)
+	if !ok {
+		return
+	}
+
+	var request addPostAuthorRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	ok, errors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	coAuthor, err := s.UserRepository.FindUserByUsername(c.Request.Context(), request.Username)
+	if err != nil {
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("username", request.Username))
+		c.Error(err)
+		return
+	}
+
+	if coAuthor.ID == post.UserID {
+		c.Error(ErrInvalidInput{"the owner of a post can't be its co-author"})
+		return
+	}
+
+	// co-authors of a post published in an organization have to be able to read it
+	if post.OrganizationID != nil {
+		ok, err := s.authorizeInOrganization(c.Request.Context(), coAuthor, *post.OrganizationID, objectPost, actionRead, 0)
+		if err != nil {
+			s.logger(c).Error("couldn't enforce organization rules", zap.Error(err), zap.Int("organizationId", *post.OrganizationID))
+			s.internalServerErrorResponse(c)
+			return
+		}
+
+		if !ok {
+			c.Error(ErrInvalidInput{"co-authors must be members of the post's organization"})
+			return
+		}
+	}
+
+	count, err := s.PostRepository.CountCoAuthors(c.Request.Context(), post.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't count co-authors", zap.Error(err), zap.Int("postId", post.ID))
+		c.Error(err)
+		return
+	}
+
+	if count >= maxCoAuthors {
+		c.Error(ErrInvalidInput{"a post can't have more than 10 co-authors"})
+		return
+	}
+
+	err = s.PostRepository.InsertCoAuthor(c.Request.Context(), post.ID, coAuthor.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't insert co-author", zap.Error(err), zap.Int("postId", post.ID), zap.Int("userId", coAuthor.ID))
+		c.Error(err)
+		return
+	}
+
+	s.logger(c).Info("co-author added", zap.Int("postId", post.ID), zap.Int("userId", coAuthor.ID))
+
+	authors, err := s.PostRepository.FindPostAuthors(c.Request.Context(), post.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't find post authors", zap.Error(err), zap.Int("postId", post.ID))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, newListResponse(s, c, newPostAuthorsResponse(authors), 0, 0))
+}
+
+// @Summary Removes a co-author from a post, co-authors can remove themselves.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param postId path string true "post id"
+// @Param username path string true "username"
+// @Security ApiKeyAuth
+// @Success 200 "Co-author removed successfully"
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the user is neither the post's owner nor the co-author"
+// @Failure 404 {object} errorResponse "The post doesn't exist or the user isn't its co-author"
+// @Failure 500 {object} errorResponse
+// @Router /posts/{postId}/authors/{username} [delete]
+func (s *Server) deletePostAuthorHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	postId, err := s.publicIDParam(c, "postId")
+	if err != nil {
+		s.logger(c).Debug("post id is invalid", zap.String("postId", c.Param("postId")))
+		s.badRequestResponse(c, err.Error())
+		return
+	}
+
+	post, err := s.PostRepository.FindPostByPublicID(c.Request.Context(), postId)
+	if err != nil {
+		s.logger(c).Debug("post could not be found", zap.Error(err), zap.String("postId", postId))
+		c.Error(err)
+		return
+	}
+
+	coAuthor, err := s.UserRepository.FindUserByUsername(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("username", c.Param("username")))
+		c.Error(err)
+		return
+	}
+
+	if user.ID != post.UserID && user.ID != coAuthor.ID {
+		s.logger(c).Debug("user can't remove the co-author", zap.Int("postId", post.ID), zap.Int("userId", coAuthor.ID))
+		s.forbiddenResponse(c, "insufficient permissions")
+		return
+	}
+
+	err = s.PostRepository.DeleteCoAuthor(c.Request.Context(), post.ID, coAuthor.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't delete co-author", zap.Error(err), zap.Int("postId", post.ID), zap.Int("userId", coAuthor.ID))
+		c.Error(err)
+		return
+	}
+
+	s.logger(c).Info("co-author removed", zap.Int("postId", post.ID), zap.Int("userId", coAuthor.ID))
+
+	c.Status(http.StatusOK)
+}
+
+// ownPostFromParam looks up the post in the postId parameter and checks the user owns it. It writes an error
+// response if it doesn't exist or belongs to someone else, in which case the handler should return.
+func (s *Server) ownPostFromParam(c *gin.Context, user repository.User) (repository.Post, bool) {
+	postId, err := s.publicIDParam(c, "postId")
+	if err != nil {
+		s.logger(c).Debug("post id is invalid", zap.String("postId", c.Param("postId")))
+		s.badRequestResponse(c, err.Error())
+		return repository.Post{}, false
+	}
+
+	post, err := s.PostRepository.FindPostByPublicID(c.Request.Context(), postId)
+	if err != nil {
+		s.logger(c).Debug("post could not be found", zap.Error(err), zap.String("postId", postId))
+		c.Error(err)
+		return repository.Post{}, false
+	}
+
+	if post.UserID != user.ID {
+		s.logger(c).Debug("user doesn't own the post", zap.String("postId", postId), zap.String("username", user.Username))
+		s.forbiddenResponse(c, "only the owner of a post can do this")
+		return repository.Post{}, false
+	}
+
+	return post, true
+}
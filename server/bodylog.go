@@ -0,0 +1,140 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"strings"
+)
+
+// redactedFields are the query parameters and JSON fields whose values are never logged.
+var redactedFields = []string{"token", "password", "access_token", "refresh_token", "secret", "totp", "recovery_code", "recovery_codes"}
+
+// bodyLogWriter keeps the first maxSize bytes of the response for bodyLogger.
+type bodyLogWriter struct {
+	gin.ResponseWriter
+	maxSize int
+
+	body      bytes.Buffer
+	truncated bool
+}
+
+func (w *bodyLogWriter) Write(b []byte) (int, error) {
+	w.capture(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyLogWriter) WriteString(s string) (int, error) {
+	w.capture([]byte(s))
+	return w.ResponseWriter.WriteString(s)
+}
+
+func (w *bodyLogWriter) capture(b []byte) {
+	remaining := w.maxSize - w.body.Len()
+	if len(b) > remaining {
+		b = b[:remaining]
+		w.truncated = true
+	}
+
+	w.body.Write(b)
+}
+
+// bodyLogger logs the request and response bodies for diagnosing client integrations, with the values of
+// redactedFields replaced. Only JSON bodies are logged, since nothing else can be redacted, and bodies larger than
+// BODY_LOGGING_MAX_SIZE are left out.
+func (s *Server) bodyLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maxSize := s.Config.BodyLoggingMaxSize
+
+		var requestBody []byte
+		requestTruncated := false
+
+		if c.Request.Body != nil {
+			var err error
+			requestBody, err = io.ReadAll(io.LimitReader(c.Request.Body, int64(maxSize)+1))
+			if err != nil {
+				s.logger(c).Debug("couldn't read request body", zap.Error(err))
+			}
+
+			// the handler still reads the whole body, starting with the part read here
+			c.Request.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(requestBody), c.Request.Body), c.Request.Body}
+
+			if len(requestBody) > maxSize {
+				requestBody = requestBody[:maxSize]
+				requestTruncated = true
+			}
+		}
+
+		writer := &bodyLogWriter{ResponseWriter: c.Writer, maxSize: maxSize}
+		c.Writer = writer
+
+		c.Next()
+
+		s.logger(c).Debug("request and response bodies",
+			zap.String("requestBody", redactBody(c.ContentType(), requestBody, requestTruncated)),
+			zap.String("responseBody", redactBody(writer.Header().Get("Content-Type"), writer.body.Bytes(), writer.truncated)),
+		)
+	}
+}
+
+// redactBody returns the JSON body with the values of redactedFields replaced, at any depth.
+func redactBody(contentType string, body []byte, truncated bool) string {
+	switch {
+	case len(body) == 0:
+		return ""
+	case truncated:
+		return "(too large to log)"
+	case !strings.Contains(contentType, "json"):
+		return "(not json)"
+	}
+
+	var value any
+	err := json.Unmarshal(body, &value)
+	if err != nil {
+		return "(invalid json)"
+	}
+
+	redacted, err := json.Marshal(redactValue(value))
+	if err != nil {
+		return "(invalid json)"
+	}
+
+	return string(redacted)
+}
+
+func redactValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isRedactedField(key) {
+				v[key] = "REDACTED"
+				continue
+			}
+
+			v[key] = redactValue(field)
+		}
+	case []any:
+		for i, element := range v {
+			v[i] = redactValue(element)
+		}
+	}
+
+	return value
+}
+
+func isRedactedField(key string) bool {
+	key = strings.ToLower(key)
+
+	for _, field := range redactedFields {
+		if key == field {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/XiovV/blog-api/pkg/validator"
+	"github.com/alexedwards/argon2id"
+	"go.uber.org/zap"
+	"sort"
+	"strings"
+)
+
+const adminRole = "admin"
+
+// defaultRoles are the roles referenced by rbac/rbac_policy.csv.
+var defaultRoles = []string{"user", "moderator", adminRole}
+
+// Bootstrap prepares a fresh install, it creates the default roles and, if BOOTSTRAP_ADMIN_USERNAME
+// is set and there isn't an admin yet, the initial admin account. It's safe to run on every start.
+func (s *Server) Bootstrap() error {
+	roles := append([]string{s.Config.DefaultRole}, defaultRoles...)
+
+	for _, role := range roles {
+		created, err := s.UserRepository.EnsureRole(context.Background(), role)
+		if err != nil {
+			return fmt.Errorf("couldn't create role %q: %w", role, err)
+		}
+
+		if created {
+			s.Logger.Info("created role", zap.String("role", role))
+		}
+	}
+
+	adminPolicies, err := s.CasbinEnforcer.GetImplicitPermissionsForUser(adminRole)
+	if err != nil {
+		return err
+	}
+
+	if len(adminPolicies) == 0 {
+		s.Logger.Warn("the casbin policy doesn't grant the admin role any permissions", zap.String("role", adminRole))
+	}
+
+	return s.bootstrapAdmin()
+}
+
+func (s *Server) bootstrapAdmin() error {
+	username := strings.TrimSpace(s.Config.BootstrapAdminUsername)
+	email := strings.TrimSpace(s.Config.BootstrapAdminEmail)
+	password := s.Config.BootstrapAdminPassword
+
+	if username == "" {
+		return nil
+	}
+
+	admins, err := s.UserRepository.CountUsersWithRole(context.Background(), adminRole)
+	if err != nil {
+		return err
+	}
+
+	if admins > 0 {
+		return nil
+	}
+
+	v := validator.New()
+	v.RequiredRange("admin username", username, minUsernameLength, maxUsernameLength)
+	v.Email("admin email", email)
+	v.RequiredMin("admin password", password, minPasswordLength)
+
+	ok, validationErrors := v.IsValid()
+	if !ok {
+		var messages []string
+		for _, fieldErrors := range validationErrors {
+			messages = append(messages, fieldErrors...)
+		}
+
+		sort.Strings(messages)
+		return errors.New(strings.Join(messages, ", "))
+	}
+
+	hash, err := argon2id.CreateHash(password, s.argon2Params())
+	if err != nil {
+		return err
+	}
+
+	admin := repository.User{Username: username, Email: email, Password: hash, Role: adminRole}
+	id, err := s.UserRepository.InsertUser(context.Background(), admin)
+	if err != nil {
+		return fmt.Errorf("couldn't create admin: %w", err)
+	}
+
+	_, err = s.UserRepository.MarkEmailVerified(context.Background(), id)
+	if err != nil {
+		return err
+	}
+
+	s.recordUserHistory(nil, id)
+
+	admin.ID = id
+	admin.Active = true
+	s.recordAudit(nil, "", auditActionUserBootstrap, objectUser, id, nil, newAuditUser(admin), "")
+
+	s.Logger.Info("created initial admin", zap.String("username", username))
+
+	return nil
+}
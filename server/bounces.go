@@ -0,0 +1,113 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/mailer"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+)
+
+const (
+	maxMailEventsSize = 1 << 20
+)
+
+// @Summary Receives the bounce and complaint callbacks of the email provider in MAIL_PROVIDER.
+// @Description Addresses with a permanent bounce or a spam complaint are marked as undeliverable and aren't emailed anymore.
+// @Description The callback URL has to include MAIL_WEBHOOK_TOKEN as the token query parameter. SES notifications are received
+// @Description through an SNS subscription, which is confirmed automatically.
+// @Tags mail
+// @Accept json
+// @Produce json
+// @Param token query string true "MAIL_WEBHOOK_TOKEN"
+// @Success 200 "Events processed successfully"
+// @Failure 400 {object} errorResponse "The body is invalid or the provider doesn't report bounces"
+// @Failure 403 {object} errorResponse "The token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /mail/events [post]
+func (s *Server) mailEventsHandler(c *gin.Context) {
+	if s.Config.MailWebhookToken == "" || subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(s.Config.MailWebhookToken)) != 1 {
+		s.logger(c).Debug("mail webhook token is invalid")
+		s.errorResponse(c, http.StatusForbidden, codeInvalidToken, "invalid token")
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxMailEventsSize))
+	if err != nil {
+		s.logger(c).Debug("couldn't read mail events", zap.Error(err))
+		s.badRequestResponse(c, "couldn't read body")
+		return
+	}
+
+	events, err := s.parseMailEvents(c, body)
+	if err != nil {
+		s.logger(c).Debug("couldn't parse mail events", zap.Error(err), zap.String("provider", s.Config.MailProvider))
+		c.Error(ErrInvalidInput{err.Error()})
+		return
+	}
+
+	for _, event := range events {
+		marked, err := s.UserRepository.MarkEmailUndeliverable(c.Request.Context(), event.Recipient, event.Type)
+		if err != nil {
+			s.logger(c).Debug("couldn't mark email undeliverable", zap.Error(err))
+			c.Error(err)
+			return
+		}
+
+		if marked > 0 {
+			s.logger(c).Info("email marked undeliverable", zap.String("email", event.Recipient), zap.String("reason", event.Type))
+		}
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// parseMailEvents returns the bounces and complaints in the callback of the configured provider.
+func (s *Server) parseMailEvents(c *gin.Context, body []byte) ([]mailer.Event, error) {
+	switch s.Config.MailProvider {
+	case mailer.ProviderSendGrid:
+		return mailer.ParseSendGridEvents(body)
+	case mailer.ProviderMailgun:
+		return mailer.ParseMailgunEvent(body)
+	case mailer.ProviderSES:
+		message, err := mailer.ParseSNSMessage(body)
+		if err != nil {
+			return nil, err
+		}
+
+		if message.Type == mailer.SNSSubscriptionConfirmation {
+			return nil, s.confirmSNSSubscription(c.Request.Context(), message.SubscribeURL)
+		}
+
+		return mailer.ParseSESNotification(message.Message)
+	default:
+		return nil, fmt.Errorf("the %s provider doesn't report bounces", s.Config.MailProvider)
+	}
+}
+
+func (s *Server) confirmSNSSubscription(ctx context.Context, subscribeURL string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.Config.WebhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, subscribeURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("couldn't confirm sns subscription: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sns responded to the subscription confirmation with status %d", resp.StatusCode)
+	}
+
+	s.Logger.Info("sns subscription confirmed")
+
+	return nil
+}
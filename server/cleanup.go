@@ -0,0 +1,41 @@
+package server
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"time"
+)
+
+// runTokenCleanup periodically deletes expired password reset tokens, blacklisted refresh tokens which
+// have expired and old login attempts, so the tables don't grow forever. Deleting is idempotent, so every instance can run it.
+func (s *Server) runTokenCleanup() {
+	ticker := time.NewTicker(s.Config.TokenCleanupInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.cleanupTokens()
+	}
+}
+
+func (s *Server) cleanupTokens() {
+	deleted, err := s.UserRepository.DeleteExpiredRefreshTokens(context.Background())
+	if err != nil {
+		s.Logger.Error("couldn't delete expired refresh tokens", zap.Error(err))
+	} else if deleted > 0 {
+		s.Logger.Debug("deleted expired refresh tokens", zap.Int("count", deleted))
+	}
+
+	deleted, err = s.UserRepository.DeleteExpiredPasswordResetTokens(context.Background())
+	if err != nil {
+		s.Logger.Error("couldn't delete expired password reset tokens", zap.Error(err))
+	} else if deleted > 0 {
+		s.Logger.Debug("deleted expired password reset tokens", zap.Int("count", deleted))
+	}
+
+	deleted, err = s.StatsRepository.DeleteLoginAttemptsBefore(context.Background(), time.Now().Add(-s.Config.LoginAttemptRetention))
+	if err != nil {
+		s.Logger.Error("couldn't delete old login attempts", zap.Error(err))
+	} else if deleted > 0 {
+		s.Logger.Debug("deleted old login attempts", zap.Int("count", deleted))
+	}
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	encodingGzip    = "gzip"
+	encodingDeflate = "deflate"
+)
+
+var (
+	gzipWriterPool = sync.Pool{New: func() any {
+		return gzip.NewWriter(io.Discard)
+	}}
+	flateWriterPool = sync.Pool{New: func() any {
+		w, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+		return w
+	}}
+)
+
+// compressWriter buffers the response until it reaches minSize, so small responses are sent uncompressed.
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	minSize  int
+
+	buf         bytes.Buffer
+	compressor  io.WriteCloser
+	passthrough bool
+}
+
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.compressor != nil {
+		return w.compressor.Write(b)
+	}
+
+	if w.passthrough {
+		return w.ResponseWriter.Write(b)
+	}
+
+	w.buf.Write(b)
+	if w.buf.Len() < w.minSize {
+		return len(b), nil
+	}
+
+	err := w.start()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(b), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// start decides whether the buffered response gets compressed and writes it out.
+func (w *compressWriter) start() error {
+	header := w.Header()
+
+	if header.Get("Content-Encoding") != "" {
+		w.passthrough = true
+	} else {
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+
+		switch w.encoding {
+		case encodingGzip:
+			gz := gzipWriterPool.Get().(*gzip.Writer)
+			gz.Reset(w.ResponseWriter)
+			w.compressor = gz
+		case encodingDeflate:
+			fl := flateWriterPool.Get().(*flate.Writer)
+			fl.Reset(w.ResponseWriter)
+			w.compressor = fl
+		}
+	}
+
+	var err error
+	if w.compressor != nil {
+		_, err = w.compressor.Write(w.buf.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf.Bytes())
+	}
+
+	w.buf.Reset()
+
+	return err
+}
+
+func (w *compressWriter) finish() error {
+	if w.compressor == nil {
+		if w.buf.Len() == 0 {
+			return nil
+		}
+
+		_, err := w.ResponseWriter.Write(w.buf.Bytes())
+		return err
+	}
+
+	err := w.compressor.Close()
+
+	switch compressor := w.compressor.(type) {
+	case *gzip.Writer:
+		gzipWriterPool.Put(compressor)
+	case *flate.Writer:
+		flateWriterPool.Put(compressor)
+	}
+
+	return err
+}
+
+// compression compresses responses with gzip or deflate, depending on what the client accepts.
+func (s *Server) compression() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" || c.Request.Method == "HEAD" {
+			c.Next()
+			return
+		}
+
+		c.Header("Vary", "Accept-Encoding")
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding, minSize: s.Config.CompressionMinSize}
+		c.Writer = writer
+
+		c.Next()
+
+		err := writer.finish()
+		if err != nil {
+			s.logger(c).Debug("couldn't write compressed response", zap.Error(err))
+		}
+	}
+}
+
+// negotiateEncoding picks gzip over deflate, ignoring encodings with q=0.
+func negotiateEncoding(acceptEncoding string) string {
+	accepted := map[string]bool{}
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		fields := strings.Split(part, ";")
+		encoding := strings.ToLower(strings.TrimSpace(fields[0]))
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				q, _ = strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			}
+		}
+
+		accepted[encoding] = q > 0
+	}
+
+	switch {
+	case accepted[encodingGzip]:
+		return encodingGzip
+	case accepted[encodingDeflate]:
+		return encodingDeflate
+	default:
+		return ""
+	}
+}
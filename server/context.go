@@ -3,16 +3,37 @@ package server
 import (
 	"github.com/XiovV/blog-api/pkg/repository"
 	"github.com/gin-gonic/gin"
-	"net/http"
+	"go.uber.org/zap"
 )
 
 func (s *Server) getUserFromContext(c *gin.Context) repository.User {
 	userCtx, exists := c.Get("user")
 	if !exists {
-		s.Logger.Error("user not found in context")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+		s.logger(c).Error("user not found in context")
+		s.internalServerErrorResponse(c)
 		return repository.User{}
 	}
 
 	return userCtx.(repository.User)
 }
+
+const loggerKey = "logger"
+
+// logger returns the logger for the current request, annotated with the route and the authenticated user.
+func (s *Server) logger(c *gin.Context) *zap.Logger {
+	logger, exists := c.Get(loggerKey)
+	if !exists {
+		return s.Logger
+	}
+
+	return logger.(*zap.Logger)
+}
+
+// addLoggerFields adds fields to every log line written through logger for the rest of the request.
+func (s *Server) addLoggerFields(c *gin.Context, fields ...zap.Field) {
+	c.Set(loggerKey, s.logger(c).With(fields...))
+}
+
+func (s *Server) getRequestIDFromContext(c *gin.Context) string {
+	return c.GetString(requestIdKey)
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"github.com/XiovV/blog-api/docs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// swaggerUIPage loads Swagger UI from a CDN and points it at the spec.
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Simple Blog API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js" crossorigin></script>
+  <script>
+    window.onload = () => {
+      window.ui = SwaggerUIBundle({url: "docs/openapi.json", dom_id: "#swagger-ui"});
+    };
+  </script>
+</body>
+</html>
+`
+
+// @Summary Serves the interactive API documentation.
+// @Tags docs
+// @Produce html
+// @Success 200
+// @Router /docs [get]
+func (s *Server) docsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
+
+// @Summary Returns the OpenAPI 3 spec of the API.
+// @Tags docs
+// @Produce json
+// @Success 200
+// @Router /docs/openapi.json [get]
+func (s *Server) openAPIHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json", docs.OpenAPI)
+}
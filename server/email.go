@@ -0,0 +1,109 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/XiovV/blog-api/pkg/mailer"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	jobTypeSendEmail = "email.send"
+)
+
+// ErrEmailRateLimited is returned by enqueueEmail when an address has been sent too many emails of a rate limited
+// template, so the endpoints sending them can't be used to flood someone's inbox.
+var ErrEmailRateLimited = errors.New("too many emails have been sent to this address, please try again later")
+
+// rateLimitedTemplates are the emails anyone can trigger for an address, at most EMAIL_RATE_LIMIT of each are
+// queued for it within EMAIL_RATE_LIMIT_WINDOW.
+var rateLimitedTemplates = map[string]bool{
+	mailer.PasswordReset{}.Template(): true,
+	mailer.Verification{}.Template():  true,
+}
+
+// emailJob is the payload of the email.send job. Emails which are only useful for a limited time,
+// like password resets, set ExpiresAt so a retry doesn't deliver a link that no longer works. Jobs queued
+// before emails were localized have no Language and are sent in English.
+type emailJob struct {
+	Recipient string          `json:"recipient"`
+	Language  string          `json:"language,omitempty"`
+	Template  string          `json:"template"`
+	Data      json.RawMessage `json:"data"`
+	ExpiresAt *time.Time      `json:"expires_at,omitempty"`
+}
+
+// enqueueEmail hands the email to the user over to the job queue, which retries it if the SMTP server is
+// unavailable. It's sent in the user's locale.
+//
+// Rate limited emails are counted and queued in a transaction which holds a lock on the recipient, so concurrent
+// requests can't exceed the limit. Callers which create something for the email, like a token, should do it in
+// the same transaction, so it's rolled back if the limit has been reached.
+func (s *Server) enqueueEmail(ctx context.Context, user repository.User, email mailer.Email, expiresAt *time.Time) error {
+	if !rateLimitedTemplates[email.Template()] {
+		return s.queueEmail(ctx, user, email, expiresAt)
+	}
+
+	return s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		sent, err := s.JobRepository.CountEmailsSince(ctx, jobTypeSendEmail, user.Email, email.Template(), time.Now().Add(-s.Config.EmailRateLimitWindow))
+		if err != nil {
+			return err
+		}
+
+		if sent >= s.Config.EmailRateLimit {
+			s.Logger.Warn("email rate limit reached", zap.String("template", email.Template()), zap.String("recipient", user.Email))
+			return ErrEmailRateLimited
+		}
+
+		return s.queueEmail(ctx, user, email, expiresAt)
+	})
+}
+
+func (s *Server) queueEmail(ctx context.Context, user repository.User, email mailer.Email, expiresAt *time.Time) error {
+	data, err := json.Marshal(email)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Queue.Enqueue(ctx, jobTypeSendEmail, emailJob{
+		Recipient: user.Email,
+		Language:  user.Locale,
+		Template:  email.Template(),
+		Data:      data,
+		ExpiresAt: expiresAt,
+	})
+	return err
+}
+
+func (s *Server) sendEmailJob(ctx context.Context, payload json.RawMessage) error {
+	var email emailJob
+	err := json.Unmarshal(payload, &email)
+	if err != nil {
+		return err
+	}
+
+	if email.ExpiresAt != nil && time.Now().After(*email.ExpiresAt) {
+		s.Logger.Warn("dropping expired email", zap.String("template", email.Template), zap.String("recipient", email.Recipient))
+		return nil
+	}
+
+	undeliverable, err := s.UserRepository.IsEmailUndeliverable(ctx, email.Recipient)
+	if err != nil {
+		return err
+	}
+
+	if undeliverable {
+		s.Logger.Warn("suppressing email to undeliverable address", zap.String("template", email.Template), zap.String("recipient", email.Recipient))
+		return nil
+	}
+
+	data, err := mailer.Decode(email.Template, email.Data)
+	if err != nil {
+		return err
+	}
+
+	return s.Mailer.Send(ctx, email.Recipient, email.Language, data)
+}
@@ -1,6 +1,12 @@
 package server
 
-import "errors"
+import (
+	"errors"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"net/http"
+	"sort"
+	"strings"
+)
 
 var (
 	ErrInvalidJSON = errors.New("json is invalid")
@@ -13,3 +19,113 @@ type ErrInvalidInput struct {
 func (e ErrInvalidInput) Error() string {
 	return e.Message
 }
+
+// ErrValidationFailed carries the field errors of a validator, for helpers which validate on behalf of a handler.
+// errorHandler responds to it like validationErrorResponse.
+type ErrValidationFailed struct {
+	Fields map[string][]string
+}
+
+func (e ErrValidationFailed) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, fieldMessages := range e.Fields {
+		messages = append(messages, fieldMessages...)
+	}
+
+	sort.Strings(messages)
+
+	return strings.Join(messages, ", ")
+}
+
+// The codes in error responses. Clients should branch on them rather than on the messages, which are translated
+// and may be reworded.
+const (
+	codeInvalidInput        = "INVALID_INPUT"
+	codeInvalidJSON         = "INVALID_JSON"
+	codeValidationFailed    = "VALIDATION_FAILED"
+	codeInvalidCredentials  = "INVALID_CREDENTIALS"
+	codeInvalidToken        = "INVALID_TOKEN"
+	codeTokenExpired        = "TOKEN_EXPIRED"
+	codeForbidden           = "FORBIDDEN"
+	codeUserInactive        = "USER_INACTIVE"
+	codeEmailNotVerified    = "EMAIL_NOT_VERIFIED"
+	codeRateLimited         = "RATE_LIMITED"
+	codeConflict            = "CONFLICT"
+	codeTransactionConflict = "TRANSACTION_CONFLICT"
+	codeMaintenance         = "MAINTENANCE"
+	codeServiceUnavailable  = "SERVICE_UNAVAILABLE"
+	codeInternalError       = "INTERNAL_ERROR"
+
+	codeUserNotFound              = "USER_NOT_FOUND"
+	codePostNotFound              = "POST_NOT_FOUND"
+	codePostAuthorNotFound        = "POST_AUTHOR_NOT_FOUND"
+	codeAPITokenNotFound          = "API_TOKEN_NOT_FOUND"
+	codeJobNotFound               = "JOB_NOT_FOUND"
+	codeWebhookNotFound           = "WEBHOOK_NOT_FOUND"
+	codeRecordHistoryNotFound     = "RECORD_HISTORY_NOT_FOUND"
+	codeOrganizationNotFound      = "ORGANIZATION_NOT_FOUND"
+	codeMemberNotFound            = "MEMBER_NOT_FOUND"
+	codeReportNotFound            = "REPORT_NOT_FOUND"
+	codeUserAlreadyExists         = "USER_ALREADY_EXISTS"
+	codeOrganizationAlreadyExists = "ORGANIZATION_ALREADY_EXISTS"
+	codeReportAlreadyExists       = "REPORT_ALREADY_EXISTS"
+	codeLastOrganizationAdmin     = "LAST_ORGANIZATION_ADMIN"
+)
+
+// apiError is the body of every error response, wrapped in an "error" object.
+type apiError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Fields holds the messages of a VALIDATION_FAILED error keyed by the invalid field.
+	Fields map[string][]string `json:"fields,omitempty"`
+}
+
+const mimeProblemJSON = "application/problem+json"
+
+// problemDetails is the RFC 7807 form of apiError. The type is always about:blank, so the title is the status
+// text, and the code and fields are extension members.
+type problemDetails struct {
+	Type     string              `json:"type"`
+	Title    string              `json:"title"`
+	Status   int                 `json:"status"`
+	Detail   string              `json:"detail"`
+	Instance string              `json:"instance"`
+	Code     string              `json:"code"`
+	Fields   map[string][]string `json:"fields,omitempty"`
+}
+
+type knownError struct {
+	err    error
+	status int
+	code   string
+}
+
+// knownErrors are the errors handlers pass to c.Error which errorHandler responds to with their own message.
+var knownErrors = []knownError{
+	{repository.ErrUserNotFound, http.StatusNotFound, codeUserNotFound},
+	{repository.ErrPostNotFound, http.StatusNotFound, codePostNotFound},
+	{repository.ErrPostAuthorNotFound, http.StatusNotFound, codePostAuthorNotFound},
+	{repository.ErrAPITokenNotFound, http.StatusNotFound, codeAPITokenNotFound},
+	{repository.ErrJobNotFound, http.StatusNotFound, codeJobNotFound},
+	{repository.ErrWebhookNotFound, http.StatusNotFound, codeWebhookNotFound},
+	{repository.ErrRecordHistoryNotFound, http.StatusNotFound, codeRecordHistoryNotFound},
+	{repository.ErrOrganizationNotFound, http.StatusNotFound, codeOrganizationNotFound},
+	{repository.ErrMemberNotFound, http.StatusNotFound, codeMemberNotFound},
+	{repository.ErrReportNotFound, http.StatusNotFound, codeReportNotFound},
+	{repository.ErrUserAlreadyExists, http.StatusConflict, codeUserAlreadyExists},
+	{repository.ErrOrganizationAlreadyExists, http.StatusConflict, codeOrganizationAlreadyExists},
+	{repository.ErrReportAlreadyExists, http.StatusConflict, codeReportAlreadyExists},
+	{repository.ErrLastOrganizationAdmin, http.StatusConflict, codeLastOrganizationAdmin},
+	{ErrInvalidJSON, http.StatusBadRequest, codeInvalidJSON},
+	{ErrEmailRateLimited, http.StatusTooManyRequests, codeRateLimited},
+}
+
+func findKnownError(err error) (knownError, bool) {
+	for _, known := range knownErrors {
+		if errors.Is(err, known.err) {
+			return known, true
+		}
+	}
+
+	return knownError{}, false
+}
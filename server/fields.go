@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// fieldSet holds the fields a client asked for with the fields query parameter, e.g. ?fields=id,title,created_at.
+// It's nil if the client didn't ask for specific fields, in which case all of them are returned.
+type fieldSet map[string]bool
+
+// parseFields reads the fields query parameter, every field has to be one of the json fields of resource, the
+// struct which is returned for each item.
+func (s *Server) parseFields(c *gin.Context, resource any) (fieldSet, error) {
+	if c.Query("fields") == "" {
+		return nil, nil
+	}
+
+	allowed := jsonFieldNames(resource)
+
+	fields := fieldSet{}
+	for _, field := range strings.Split(c.Query("fields"), ",") {
+		field = strings.TrimSpace(field)
+		if !allowed[field] {
+			names := make([]string, 0, len(allowed))
+			for name := range allowed {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			return nil, fmt.Errorf("fields must only contain: %s", strings.Join(names, ", "))
+		}
+
+		fields[field] = true
+	}
+
+	return fields, nil
+}
+
+// filter returns the resource with only the fields in the set, or the resource itself if the set is nil.
+func (f fieldSet) filter(resource any) any {
+	if f == nil {
+		return resource
+	}
+
+	// the resources are plain structs, marshalling them can't fail
+	data, _ := json.Marshal(resource)
+
+	var filtered map[string]any
+	_ = json.Unmarshal(data, &filtered)
+
+	for field := range filtered {
+		if !f[field] {
+			delete(filtered, field)
+		}
+	}
+
+	return filtered
+}
+
+// filterList applies filter to each resource.
+func filterList[T any](f fieldSet, resources []T) []any {
+	filtered := make([]any, 0, len(resources))
+	for _, resource := range resources {
+		filtered = append(filtered, f.filter(resource))
+	}
+
+	return filtered
+}
+
+func jsonFieldNames(resource any) map[string]bool {
+	t := reflect.TypeOf(resource)
+
+	names := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			names[name] = true
+		}
+	}
+
+	return names
+}
@@ -1,20 +1,29 @@
 package server
 
 import (
+	cryptorand "crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/XiovV/blog-api/pkg/filter"
+	"github.com/XiovV/blog-api/pkg/repository"
 	"github.com/gin-gonic/gin"
-	"go.uber.org/zap"
+	"io"
+	"math"
 	"math/rand"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	MaxLimitValue            = 100
-	MinLimitValue            = 1
-	MinPageValue             = 1
+	MaxLimitValue = 100
+	MinLimitValue = 1
+	MinPageValue  = 1
+	// MaxPageValue keeps the offset of a page from overflowing.
+	MaxPageValue             = math.MaxInt32
 	RecoveryCodesAmount      = 16
 	RecoveryCodeLength       = 7
 	PasswordResetTokenLength = 20
@@ -23,38 +32,127 @@ const (
 func (s *Server) validatePageAndLimit(c *gin.Context) (int, int, error) {
 	page, err := strconv.Atoi(c.Query("page"))
 	if err != nil {
-		return 0, 0, fmt.Errorf("page must be an integer")
+		return 0, 0, ErrInvalidInput{"page must be an integer"}
 	}
 
 	limit, err := strconv.Atoi(c.Query("limit"))
 	if err != nil {
-		return 0, 0, fmt.Errorf("limit must be an integer")
+		return 0, 0, ErrInvalidInput{"limit must be an integer"}
 	}
 
-	if page < MinPageValue {
-		return 0, 0, fmt.Errorf("page must be greater than 0")
+	v := s.validator(c)
+	v.IntRange("page", page, MinPageValue, MaxPageValue)
+	v.IntRange("limit", limit, MinLimitValue, MaxLimitValue)
+
+	ok, errors := v.IsValid()
+	if !ok {
+		return 0, 0, ErrValidationFailed{errors}
+	}
+
+	return page, limit, nil
+}
+
+// parsePostListOptions reads the sort, created_after, created_before and updated_after query parameters and the filters.
+// sort is created_at or updated_at, prefixed with a minus for descending order, the others are RFC 3339 timestamps.
+// Filters are parsed with repository.PostFilters.
+func (s *Server) parsePostListOptions(c *gin.Context) (repository.PostListOptions, error) {
+	var options repository.PostListOptions
+
+	sort := c.Query("sort")
+	if strings.HasPrefix(sort, "-") {
+		options.Descending = true
+		sort = sort[1:]
 	}
 
-	if limit < MinLimitValue {
-		return 0, 0, fmt.Errorf("limit must be greater than 0")
+	switch sort {
+	case "", repository.PostSortCreatedAt, repository.PostSortUpdatedAt:
+		options.SortBy = sort
+	default:
+		return options, fmt.Errorf("sort must be one of: created_at, -created_at, updated_at, -updated_at")
 	}
 
-	if limit > MaxLimitValue {
-		return 0, 0, fmt.Errorf("maximum limit size is %d", MaxLimitValue)
+	timestamps := map[string]**time.Time{
+		"created_after":  &options.CreatedAfter,
+		"created_before": &options.CreatedBefore,
+		"updated_after":  &options.UpdatedAfter,
 	}
 
-	return page, limit, nil
+	for key, dst := range timestamps {
+		if c.Query(key) == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, c.Query(key))
+		if err != nil {
+			return options, fmt.Errorf("%s must be an RFC 3339 timestamp", key)
+		}
+
+		*dst = &t
+	}
+
+	filters, err := filter.Parse(c.Request.URL.Query(), repository.PostFilters)
+	if err != nil {
+		return options, err
+	}
+
+	options.Filters = filters
+
+	return options, nil
+}
+
+// publicIDPattern matches the UUIDs users and posts are exposed by.
+var publicIDPattern = regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// publicIDParam returns the public id in the path param, checking it's a UUID before it's passed on to Postgres,
+// which would reject it with an internal error otherwise.
+func (s *Server) publicIDParam(c *gin.Context, name string) (string, error) {
+	id := strings.ToLower(c.Param(name))
+	if !publicIDPattern.MatchString(id) {
+		return "", fmt.Errorf("%s must be a valid id", name)
+	}
+
+	return id, nil
+}
+
+// bindJSON decodes the request body into obj, rejecting unknown fields so typos in
+// field names don't get silently ignored.
+func (s *Server) bindJSON(c *gin.Context, obj any) error {
+	if c.Request.Body == nil {
+		return ErrInvalidInput{"request body must not be empty"}
+	}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(obj)
+	if err != nil {
+		var typeError *json.UnmarshalTypeError
+
+		switch {
+		case errors.As(err, &typeError) && typeError.Field != "":
+			return ErrInvalidInput{fmt.Sprintf("%s must be of type %s", typeError.Field, typeError.Type)}
+		case strings.HasPrefix(err.Error(), "json: unknown field "):
+			return ErrInvalidInput{fmt.Sprintf("unknown field %s", strings.TrimPrefix(err.Error(), "json: unknown field "))}
+		case errors.Is(err, io.EOF):
+			return ErrInvalidInput{"request body must not be empty"}
+		default:
+			return ErrInvalidJSON
+		}
+	}
+
+	if decoder.More() {
+		return ErrInvalidInput{"request body must only contain a single json object"}
+	}
+
+	return nil
 }
 
-func (s *Server) encryptMfaSecret(secret []byte) []byte {
-	nonce := make([]byte, s.gcm.NonceSize())
-	return s.gcm.Seal(nonce, nonce, secret, nil)
+func (s *Server) encryptMfaSecret(secret []byte) ([]byte, error) {
+	return s.keyring.encrypt(secret)
 }
 
 func (s *Server) decryptMfaSecret(encryptedSecret []byte) ([]byte, error) {
-	nonceSize := s.gcm.NonceSize()
-	nonce, cipherText := encryptedSecret[:nonceSize], encryptedSecret[nonceSize:]
-	return s.gcm.Open(nil, nonce, cipherText, nil)
+	return s.keyring.decrypt(encryptedSecret)
 }
 
 func generateRecoveryCodes() []string {
@@ -100,6 +198,43 @@ func randomString(length int) string {
 	return string(b)
 }
 
+// generateSecureToken returns n cryptographically random bytes encoded as hex.
+func generateSecureToken(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := cryptorand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, err := cryptorand.Read(b)
+	if err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+
+	return hex.EncodeToString(b)
+}
+
+// isValidRequestID only accepts short ids made of printable ascii characters, so
+// clients can't inject arbitrary content into logs.
+func isValidRequestID(requestId string) bool {
+	if len(requestId) == 0 || len(requestId) > maxRequestIdLength {
+		return false
+	}
+
+	for _, r := range requestId {
+		if r < '!' || r > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
 func removeRecoveryCode(s []string, r string) []string {
 	for i, v := range s {
 		if v == r {
@@ -118,14 +253,3 @@ func (s *Server) isRecoveryCodeValid(recoveryCode string, codes []string) bool {
 
 	return false
 }
-
-func (s *Server) enforcePermissions(c *gin.Context, role, object, action string) bool {
-	ok, err := s.CasbinEnforcer.Enforce(role, "user", "delete")
-	if err != nil {
-		s.Logger.Debug("couldn't enforce rules", zap.Error(err))
-		s.internalServerErrorResponse(c)
-		return false
-	}
-
-	return ok
-}
@@ -0,0 +1,150 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+var historyRecordTypes = map[string]bool{
+	objectUser: true,
+	objectPost: true,
+}
+
+// recordHistory stores the state of a record after a change, snapshot should be nil if the record
+// was deleted. actorId is nil for changes made by the system itself.
+func (s *Server) recordHistory(actorId *int, recordType string, recordId int, snapshot any) {
+	// Like audit entries, history is recorded regardless of whether the client is still connected.
+	err := s.HistoryRepository.InsertChange(context.Background(), repository.RecordChange{
+		RecordType: recordType,
+		RecordID:   recordId,
+		ActorID:    actorId,
+		Snapshot:   s.auditSnapshot(snapshot),
+	})
+	if err != nil {
+		s.Logger.Error("couldn't record history", zap.Error(err), zap.String("recordType", recordType), zap.Int("recordId", recordId))
+	}
+}
+
+// recordUserHistory reloads the user so the snapshot reflects what's actually stored.
+func (s *Server) recordUserHistory(actorId *int, userId int) {
+	user, err := s.UserRepository.FindUserByID(context.Background(), userId)
+	if err != nil {
+		s.Logger.Error("couldn't find user for history", zap.Error(err), zap.Int("userId", userId))
+		return
+	}
+
+	s.recordHistory(actorId, objectUser, userId, newAuditUser(user))
+}
+
+type recordChangeResponse struct {
+	ActorID   *int             `json:"actor_id"`
+	Deleted   bool             `json:"deleted"`
+	Record    *json.RawMessage `json:"record" swaggertype:"object"`
+	ChangedAt time.Time        `json:"changed_at"`
+}
+
+func newRecordChangeResponse(change repository.RecordChange) recordChangeResponse {
+	return recordChangeResponse{ActorID: change.ActorID, Deleted: change.Snapshot == nil, Record: change.Snapshot, ChangedAt: change.ChangedAt}
+}
+
+func (s *Server) parseHistoryRecord(c *gin.Context) (string, int, bool) {
+	recordType := c.Param("recordType")
+	if !historyRecordTypes[recordType] {
+		c.Error(ErrInvalidInput{"record type must be user or post"})
+		return "", 0, false
+	}
+
+	recordId, err := strconv.Atoi(c.Param("recordId"))
+	if err != nil {
+		s.logger(c).Debug("record id not an integer", zap.String("recordId", c.Param("recordId")))
+		c.Error(ErrInvalidInput{"record id must be an integer"})
+		return "", 0, false
+	}
+
+	return recordType, recordId, true
+}
+
+// @Summary Returns every recorded change of a user or post, newest first.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param recordType path string true "user or post"
+// @Param recordId path int true "record id"
+// @Param page query int32 true "page"
+// @Param limit query int32 true "limit"
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[recordChangeResponse]
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/history/{recordType}/{recordId} [get]
+func (s *Server) getRecordHistoryHandler(c *gin.Context) {
+	recordType, recordId, ok := s.parseHistoryRecord(c)
+	if !ok {
+		return
+	}
+
+	page, limit, err := s.validatePageAndLimit(c)
+	if err != nil {
+		s.logger(c).Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
+		return
+	}
+
+	changes, err := s.HistoryRepository.FindChanges(c.Request.Context(), recordType, recordId, page, limit)
+	if err != nil {
+		s.logger(c).Debug("couldn't find record history", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	data := []recordChangeResponse{}
+	for _, change := range changes {
+		data = append(data, newRecordChangeResponse(change))
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, c, data, page, limit))
+}
+
+// @Summary Returns a user or post as it was at the provided time, along with who made the last change before it.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param recordType path string true "user or post"
+// @Param recordId path int true "record id"
+// @Param at query string true "RFC 3339 timestamp, e.g. 2022-10-01T12:00:00Z"
+// @Security ApiKeyAuth
+// @Success 200 {object} recordChangeResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "There is no history of the record at the provided time"
+// @Failure 500 {object} errorResponse
+// @Router /admin/history/{recordType}/{recordId}/as-of [get]
+func (s *Server) getRecordAsOfHandler(c *gin.Context) {
+	recordType, recordId, ok := s.parseHistoryRecord(c)
+	if !ok {
+		return
+	}
+
+	at, err := time.Parse(time.RFC3339, c.Query("at"))
+	if err != nil {
+		s.logger(c).Debug("invalid timestamp", zap.String("at", c.Query("at")))
+		c.Error(ErrInvalidInput{"at must be an RFC 3339 timestamp"})
+		return
+	}
+
+	change, err := s.HistoryRepository.FindChangeAsOf(c.Request.Context(), recordType, recordId, at)
+	if err != nil {
+		s.logger(c).Debug("couldn't find record history", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newRecordChangeResponse(change))
+}
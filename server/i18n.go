@@ -0,0 +1,110 @@
+package server
+
+import (
+	"github.com/XiovV/blog-api/pkg/i18n"
+	"github.com/XiovV/blog-api/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strings"
+)
+
+func (s *Server) setupTranslations() error {
+	catalog, err := i18n.Load()
+	if err != nil {
+		return err
+	}
+
+	s.translations = catalog
+
+	return nil
+}
+
+// translator returns the translator for the language the client prefers, messages without a translation are
+// returned in English.
+func (s *Server) translator(c *gin.Context) i18n.Translator {
+	return s.translations.Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// translate translates a fixed message for the current request.
+func (s *Server) translate(c *gin.Context, message string) string {
+	return s.translator(c).Translate(message)
+}
+
+// validationLimits are the limits validate tags may refer to by name.
+var validationLimits = map[string]int{
+	"maxAPITokenName":             maxAPITokenName,
+	"maxCanonicalURLLength":       maxCanonicalURLLength,
+	"maxFooterTextLength":         maxFooterTextLength,
+	"maxLogoURLLength":            maxLogoURLLength,
+	"maxMaintenanceMessageLength": maxMaintenanceMessageLength,
+	"minModerationReasonLength":   minModerationReasonLength,
+	"maxModerationReasonLength":   maxModerationReasonLength,
+	"maxOrganizationNameLength":   maxOrganizationNameLength,
+	"minOrganizationSlugLength":   minOrganizationSlugLength,
+	"maxOrganizationSlugLength":   maxOrganizationSlugLength,
+	"minPasswordLength":           minPasswordLength,
+	"maxReportDetailsLength":      maxReportDetailsLength,
+	"maxReviewNoteLength":         maxReviewNoteLength,
+	"maxTitleLength":              maxTitleLength,
+	"totpCodeLength":              totpCodeLength,
+	"totpSecretLength":            totpSecretLength,
+	"minUsernameLength":           minUsernameLength,
+	"maxUsernameLength":           maxUsernameLength,
+	"verificationTokenLength":     verificationTokenLength,
+	"maxWebhookURL":               maxWebhookURL,
+}
+
+// validator returns a validator whose error messages are in the client's language.
+func (s *Server) validator(c *gin.Context) *validator.Validator {
+	return validator.NewLocalized(s.translator(c)).WithLimits(validationLimits)
+}
+
+type setLocaleRequest struct {
+	Locale string `json:"locale"`
+}
+
+// @Summary Changes the language the authenticated user is emailed in.
+// @Description The locale is one of the languages with a catalog in pkg/i18n, e.g. en, de or es. Users are emailed in the language of the Accept-Language header they registered with until they change it. Emails without a translation are sent in English.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body setLocaleRequest true "Locale body"
+// @Security ApiKeyAuth
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} errorResponse "Input is invalid or the language isn't supported"
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /users/me/locale [put]
+func (s *Server) setLocaleHandler(c *gin.Context) {
+	var request setLocaleRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	request.Locale = strings.ToLower(strings.TrimSpace(request.Locale))
+
+	v := s.validator(c)
+	v.OneOf("locale", request.Locale, s.translations.Languages()...)
+
+	ok, errors := v.IsValid()
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	user := s.getUserFromContext(c)
+
+	err := s.UserRepository.SetLocale(c.Request.Context(), user.ID, request.Locale)
+	if err != nil {
+		s.logger(c).Debug("couldn't set locale", zap.Error(err), zap.Int("userId", user.ID))
+		c.Error(err)
+		return
+	}
+
+	s.recordUserHistory(&user.ID, user.ID)
+
+	s.successResponse(c, "locale has been changed")
+}
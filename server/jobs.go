@@ -0,0 +1,183 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	jobTypeMfaReencrypt = "mfa.reencrypt"
+)
+
+// sensitiveJobTypes have payloads which aren't returned by the admin endpoints, emails
+// carry secrets like password reset tokens.
+var sensitiveJobTypes = map[string]bool{
+	jobTypeSendEmail: true,
+}
+
+var jobStatuses = []string{repository.JobStatusPending, repository.JobStatusRunning, repository.JobStatusCompleted, repository.JobStatusDead}
+
+func (s *Server) setupQueue() error {
+	if s.Config.QueueWorkers < 1 {
+		return fmt.Errorf("queue must have at least one worker")
+	}
+
+	if s.Config.QueueMaxAttempts < 1 {
+		return fmt.Errorf("queue max attempts must be positive")
+	}
+
+	if s.Config.QueuePollInterval <= 0 || s.Config.QueueStaleAfter <= 0 {
+		return fmt.Errorf("queue poll interval and stale timeout must be positive")
+	}
+
+	if s.Config.QueueJobTimeout <= 0 || s.Config.QueueJobTimeout >= s.Config.QueueStaleAfter {
+		return fmt.Errorf("queue job timeout must be positive and shorter than the stale timeout")
+	}
+
+	// the email rate limit counts the email jobs in its window, completed ones must be kept at least that long
+	if s.Config.QueueRetention < s.Config.EmailRateLimitWindow {
+		return fmt.Errorf("queue retention must not be shorter than the email rate limit window")
+	}
+
+	s.Queue.Register(jobTypeSendEmail, s.sendEmailJob)
+	s.Queue.Register(jobTypeWebhookDispatch, s.dispatchWebhookJob)
+	s.Queue.Register(jobTypeWebhookDeliver, s.deliverWebhookJob)
+	s.Queue.Register(jobTypeProcessMentions, s.processMentionsJob)
+	s.Queue.Register(jobTypeMfaReencrypt, func(ctx context.Context, payload json.RawMessage) error {
+		return s.reencryptMfaSecrets(ctx, s.Logger.With(zap.String("job", jobTypeMfaReencrypt)))
+	})
+
+	s.Queue.Start()
+
+	return nil
+}
+
+type jobResponse struct {
+	ID          int             `json:"id"`
+	Type        string          `json:"type"`
+	Payload     json.RawMessage `json:"payload" swaggertype:"object"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	LastError   string          `json:"last_error"`
+	RunAt       time.Time       `json:"run_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+}
+
+func newJobResponse(job repository.Job) jobResponse {
+	if sensitiveJobTypes[job.Type] {
+		job.Payload = nil
+	}
+
+	return jobResponse{
+		ID:          job.ID,
+		Type:        job.Type,
+		Payload:     job.Payload,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   job.LastError,
+		RunAt:       job.RunAt,
+		CreatedAt:   job.CreatedAt,
+		UpdatedAt:   job.UpdatedAt,
+	}
+}
+
+// @Summary Returns the jobs in the background job queue, most recently updated first, along with the amount of jobs in each state.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int32 true "page"
+// @Param limit query int32 true "limit"
+// @Param status query string false "only return jobs in this state: pending, running, completed or dead"
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[jobResponse]
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/jobs [get]
+func (s *Server) getJobsHandler(c *gin.Context) {
+	page, limit, err := s.validatePageAndLimit(c)
+	if err != nil {
+		s.logger(c).Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" {
+		v := s.validator(c)
+		v.OneOf("status", status, jobStatuses...)
+
+		ok, errors := v.IsValid()
+		if !ok {
+			s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+			s.validationErrorResponse(c, errors)
+			return
+		}
+	}
+
+	counts, err := s.JobRepository.CountJobsByStatus(c.Request.Context())
+	if err != nil {
+		s.logger(c).Debug("couldn't count jobs", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	jobs, err := s.JobRepository.FindJobs(c.Request.Context(), status, page, limit)
+	if err != nil {
+		s.logger(c).Debug("couldn't find jobs", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	data := []jobResponse{}
+	for _, job := range jobs {
+		data = append(data, newJobResponse(job))
+	}
+
+	response := newListResponse(s, c, data, page, limit)
+	response.Meta.Counts = counts
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Moves a dead job back into the queue with a fresh set of attempts.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param jobId path int true "job id"
+// @Security ApiKeyAuth
+// @Success 200 {object} jobResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "There is no dead job with the provided id"
+// @Failure 500 {object} errorResponse
+// @Router /admin/jobs/{jobId}/retry [post]
+func (s *Server) retryJobHandler(c *gin.Context) {
+	jobId, err := strconv.Atoi(c.Param("jobId"))
+	if err != nil {
+		s.logger(c).Debug("job id not an integer", zap.String("jobId", c.Param("jobId")))
+		s.badRequestResponse(c, "job id must be an integer")
+		return
+	}
+
+	job, err := s.JobRepository.ResurrectJob(c.Request.Context(), jobId)
+	if err != nil {
+		s.logger(c).Debug("couldn't retry job", zap.Error(err), zap.Int("jobId", jobId))
+		c.Error(err)
+		return
+	}
+
+	s.audit(c, auditActionJobRetry, objectJob, job.ID, nil, gin.H{"type": job.Type})
+
+	c.JSON(http.StatusOK, newJobResponse(job))
+}
@@ -2,8 +2,8 @@ package server
 
 import (
 	"errors"
+	"fmt"
 	"github.com/golang-jwt/jwt/v4"
-	"os"
 	"time"
 )
 
@@ -14,15 +14,51 @@ const (
 	RefreshTokenType   = "REFRESH"
 )
 
+// currentClaimsVersion is the version of the claims in newly issued tokens. Bump it whenever the claims
+// change and teach migrateClaims how to upgrade the previous version, so active sessions keep working
+// until they refresh their tokens.
+const currentClaimsVersion = 1
+
+var (
+	ErrClaimsVersionTooOld = errors.New("token claims version is no longer supported")
+	ErrClaimsVersionTooNew = errors.New("token claims version is newer than this server supports")
+)
+
 type tokenClaims struct {
-	ID   int    `json:"id"`
-	Type string `json:"type"`
+	// Version is the version of the claims schema, tokens issued before claims were versioned don't carry it.
+	Version int    `json:"claims_version,omitempty"`
+	ID      int    `json:"id"`
+	Type    string `json:"type"`
 	jwt.RegisteredClaims
 }
 
-func generateAccessToken(id int) (string, error) {
+// migrateClaims upgrades claims parsed from an older token to the current version. Tokens older than
+// MinClaimsVersion are rejected, which is how a transition window is closed once it's over.
+func (s *Server) migrateClaims(claims *tokenClaims) error {
+	if claims.Version < s.Config.MinClaimsVersion {
+		return ErrClaimsVersionTooOld
+	}
+
+	if claims.Version > currentClaimsVersion {
+		return ErrClaimsVersionTooNew
+	}
+
+	for claims.Version < currentClaimsVersion {
+		switch claims.Version {
+		case 0:
+			// version 1 only introduced the version claim itself
+		}
+
+		claims.Version++
+	}
+
+	return nil
+}
+
+func (s *Server) generateAccessToken(id int) (string, error) {
 	claims := tokenClaims{
-		ID: id,
+		Version: currentClaimsVersion,
+		ID:      id,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenExpiry * time.Minute)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -30,14 +66,15 @@ func generateAccessToken(id int) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ss, err := token.SignedString([]byte(os.Getenv("SIGNING_KEY")))
+	ss, err := token.SignedString([]byte(s.Config.SigningKey))
 	return ss, err
 }
 
-func generateRefreshToken(id int) (string, error) {
+func (s *Server) generateRefreshToken(id int) (string, error) {
 	claims := tokenClaims{
-		ID:   id,
-		Type: RefreshTokenType,
+		Version: currentClaimsVersion,
+		ID:      id,
+		Type:    RefreshTokenType,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenExpiry * time.Hour)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
@@ -45,29 +82,59 @@ func generateRefreshToken(id int) (string, error) {
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ss, err := token.SignedString([]byte(os.Getenv("SIGNING_KEY")))
+	ss, err := token.SignedString([]byte(s.Config.SigningKey))
 	return ss, err
 }
 
-func parseToken(tok string) (*tokenClaims, error) {
-	token, _ := jwt.ParseWithClaims(tok, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("SIGNING_KEY")), nil
-	})
+// parseWithSigningKeys verifies the token against the current signing key first and then against
+// the previous ones, so tokens signed before a key rotation stay valid until they expire.
+func (s *Server) parseWithSigningKeys(tok string) (*jwt.Token, error) {
+	keys := append([]string{s.Config.SigningKey}, s.Config.PreviousSigningKeys...)
+
+	var token *jwt.Token
+	var err error
+	for _, key := range keys {
+		token, err = jwt.ParseWithClaims(tok, &tokenClaims{}, signingKeyFunc(key))
+		if !errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			return token, err
+		}
+	}
+
+	return token, err
+}
+
+func signingKeyFunc(key string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+
+		return []byte(key), nil
+	}
+}
+
+func (s *Server) parseToken(tok string) (*tokenClaims, error) {
+	token, _ := s.parseWithSigningKeys(tok)
+	if token == nil {
+		return nil, errors.New("claims invalid")
+	}
 
 	claims, ok := token.Claims.(*tokenClaims)
 	if !ok {
 		return nil, errors.New("claims invalid")
 	}
 
+	if err := s.migrateClaims(claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
-func validateAccessToken(tok string) (*tokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tok, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("SIGNING_KEY")), nil
-	})
+func (s *Server) validateAccessToken(tok string) (*tokenClaims, error) {
+	token, err := s.parseWithSigningKeys(tok)
 
-	if !token.Valid {
+	if token == nil || !token.Valid {
 		return nil, err
 	}
 
@@ -76,15 +143,17 @@ func validateAccessToken(tok string) (*tokenClaims, error) {
 		return nil, errors.New("claims invalid")
 	}
 
+	if err := s.migrateClaims(claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
-func validateRefreshToken(tok string) (*tokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tok, &tokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("SIGNING_KEY")), nil
-	})
+func (s *Server) validateRefreshToken(tok string) (*tokenClaims, error) {
+	token, err := s.parseWithSigningKeys(tok)
 
-	if !token.Valid {
+	if token == nil || !token.Valid {
 		return nil, err
 	}
 
@@ -97,6 +166,10 @@ func validateRefreshToken(tok string) (*tokenClaims, error) {
 		return nil, errors.New("token is not a refresh token")
 	}
 
+	if err := s.migrateClaims(claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
@@ -0,0 +1,141 @@
+package server
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keyedCiphertextVersion marks ciphertexts which carry the id of the key they were encrypted with.
+// Ciphertexts created before key rotation was supported always start with a zeroed nonce.
+const keyedCiphertextVersion = 1
+
+var (
+	ErrUnknownKeyID     = errors.New("ciphertext was encrypted with an unknown key")
+	ErrCiphertextLength = errors.New("ciphertext is too short")
+)
+
+// keyring holds every AES key which can be used to decrypt MFA secrets. New secrets
+// are always encrypted with the current key.
+type keyring struct {
+	current byte
+	keys    map[byte]cipher.AEAD
+}
+
+// newKeyring parses the current key and previous keys, which are expected in the "id:key" format.
+func newKeyring(currentId int, currentKey string, previousKeys []string) (*keyring, error) {
+	k := &keyring{keys: map[byte]cipher.AEAD{}}
+
+	err := k.add(currentId, currentKey)
+	if err != nil {
+		return nil, err
+	}
+
+	k.current = byte(currentId)
+
+	for _, previousKey := range previousKeys {
+		idAndKey := strings.SplitN(previousKey, ":", 2)
+		if len(idAndKey) != 2 {
+			return nil, errors.New("previous aes keys must be in the id:key format")
+		}
+
+		id, err := strconv.Atoi(idAndKey[0])
+		if err != nil {
+			return nil, fmt.Errorf("aes key id must be an integer: %w", err)
+		}
+
+		err = k.add(id, idAndKey[1])
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+func (k *keyring) add(id int, key string) error {
+	if id < 1 || id > 255 {
+		return fmt.Errorf("aes key id %d must be between 1 and 255", id)
+	}
+
+	if _, exists := k.keys[byte(id)]; exists {
+		return fmt.Errorf("aes key id %d is used more than once", id)
+	}
+
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		return err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return err
+	}
+
+	k.keys[byte(id)] = gcm
+
+	return nil
+}
+
+// encrypt seals plaintext with the current key. The output is laid out as version | key id | nonce | ciphertext.
+func (k *keyring) encrypt(plaintext []byte) ([]byte, error) {
+	gcm := k.keys[k.current]
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err := rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []byte{keyedCiphertextVersion, k.current}
+	out = append(out, nonce...)
+
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
+
+// decrypt opens ciphertexts created by any key in the keyring, including ciphertexts
+// which don't carry a key id.
+func (k *keyring) decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) > 0 && ciphertext[0] == keyedCiphertextVersion {
+		if len(ciphertext) < 2 {
+			return nil, ErrCiphertextLength
+		}
+
+		gcm, ok := k.keys[ciphertext[1]]
+		if !ok {
+			return nil, ErrUnknownKeyID
+		}
+
+		return open(gcm, ciphertext[2:])
+	}
+
+	var err error
+	for _, gcm := range k.keys {
+		var plaintext []byte
+		plaintext, err = open(gcm, ciphertext)
+		if err == nil {
+			return plaintext, nil
+		}
+	}
+
+	return nil, err
+}
+
+// needsRotation reports whether the ciphertext was encrypted with a key other than the current one.
+func (k *keyring) needsRotation(ciphertext []byte) bool {
+	return len(ciphertext) < 2 || ciphertext[0] != keyedCiphertextVersion || ciphertext[1] != k.current
+}
+
+func open(gcm cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	nonceSize := gcm.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrCiphertextLength
+	}
+
+	nonce, sealed := ciphertext[:nonceSize], ciphertext[nonceSize:]
+	return gcm.Open(nil, nonce, sealed, nil)
+}
@@ -0,0 +1,172 @@
+package server
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"testing"
+)
+
+const (
+	testCurrentKey  = "SwtadOdxUI1oKhuNeAmBAHVJwXITRNk9"
+	testPreviousKey = "0123456789abcdef0123456789abcdef"
+)
+
+func newTestKeyring(t *testing.T, currentId int, currentKey string, previousKeys ...string) *keyring {
+	t.Helper()
+
+	k, err := newKeyring(currentId, currentKey, previousKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return k
+}
+
+// legacyCiphertext encrypts plaintext the way secrets were encrypted before keys had ids, with a zeroed nonce
+// prepended to the ciphertext.
+func legacyCiphertext(t *testing.T, key string, plaintext []byte) []byte {
+	t.Helper()
+
+	block, err := aes.NewCipher([]byte(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+
+	return gcm.Seal(nonce, nonce, plaintext, nil)
+}
+
+func TestKeyringRoundTrip(t *testing.T) {
+	k := newTestKeyring(t, 2, testCurrentKey, "1:"+testPreviousKey)
+	plaintext := []byte("JBSWY3DPEHPK3PXP")
+
+	ciphertext, err := k.encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ciphertext[0] != keyedCiphertextVersion || ciphertext[1] != 2 {
+		t.Errorf("ciphertext starts with %v, want version %d and key id 2", ciphertext[:2], keyedCiphertextVersion)
+	}
+
+	if k.needsRotation(ciphertext) {
+		t.Error("ciphertext encrypted with the current key needs rotation")
+	}
+
+	decrypted, err := k.decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("decrypt() = %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestKeyringDecrypt(t *testing.T) {
+	plaintext := []byte("JBSWY3DPEHPK3PXP")
+
+	// before rotation, key 1 was the current key
+	previous := newTestKeyring(t, 1, testPreviousKey)
+	rotated, err := previous.encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name         string
+		keyring      *keyring
+		ciphertext   []byte
+		wantErr      error
+		needRotation bool
+	}{
+		{
+			name:         "legacy ciphertext",
+			keyring:      newTestKeyring(t, 1, testCurrentKey),
+			ciphertext:   legacyCiphertext(t, testCurrentKey, plaintext),
+			needRotation: true,
+		},
+		{
+			name:         "legacy ciphertext of a previous key",
+			keyring:      newTestKeyring(t, 2, testCurrentKey, "1:"+testPreviousKey),
+			ciphertext:   legacyCiphertext(t, testPreviousKey, plaintext),
+			needRotation: true,
+		},
+		{
+			name:         "previous key after rotation",
+			keyring:      newTestKeyring(t, 2, testCurrentKey, "1:"+testPreviousKey),
+			ciphertext:   rotated,
+			needRotation: true,
+		},
+		{
+			name:         "unknown key id",
+			keyring:      newTestKeyring(t, 2, testCurrentKey),
+			ciphertext:   rotated,
+			wantErr:      ErrUnknownKeyID,
+			needRotation: true,
+		},
+		{
+			name:         "truncated ciphertext",
+			keyring:      newTestKeyring(t, 1, testCurrentKey),
+			ciphertext:   []byte{keyedCiphertextVersion},
+			wantErr:      ErrCiphertextLength,
+			needRotation: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.keyring.needsRotation(tt.ciphertext); got != tt.needRotation {
+				t.Errorf("needsRotation() = %v, want %v", got, tt.needRotation)
+			}
+
+			decrypted, err := tt.keyring.decrypt(tt.ciphertext)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("decrypt() error = %v, want %v", err, tt.wantErr)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(decrypted, plaintext) {
+				t.Errorf("decrypt() = %q, want %q", decrypted, plaintext)
+			}
+		})
+	}
+}
+
+func TestNewKeyringRejectsInvalidKeys(t *testing.T) {
+	tests := []struct {
+		name         string
+		currentId    int
+		previousKeys []string
+	}{
+		{"current id out of range", 0, nil},
+		{"previous key without id", 1, []string{testPreviousKey}},
+		{"previous key id not an integer", 1, []string{"one:" + testPreviousKey}},
+		{"previous key reuses the current id", 1, []string{"1:" + testPreviousKey}},
+		{"previous key of the wrong length", 1, []string{"2:short"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newKeyring(tt.currentId, testCurrentKey, tt.previousKeys)
+			if err == nil {
+				t.Error("newKeyring() returned no error")
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strings"
+	"sync"
+	"time"
+)
+
+// latencyBudgets holds the maximum expected latency per route and counts how often it was exceeded.
+type latencyBudgets struct {
+	defaultBudget time.Duration
+	routes        map[string]time.Duration
+
+	mu       sync.Mutex
+	exceeded map[string]uint64
+}
+
+// newLatencyBudgets parses budgets in the "METHOD /route=duration" format, e.g. "GET /v1/posts/:postId=200ms".
+func newLatencyBudgets(defaultBudget time.Duration, budgets []string) (*latencyBudgets, error) {
+	b := &latencyBudgets{
+		defaultBudget: defaultBudget,
+		routes:        map[string]time.Duration{},
+		exceeded:      map[string]uint64{},
+	}
+
+	for _, budget := range budgets {
+		routeAndDuration := strings.SplitN(strings.TrimSpace(budget), "=", 2)
+		if len(routeAndDuration) != 2 {
+			return nil, fmt.Errorf("latency budget %q must be in the \"METHOD /route=duration\" format", budget)
+		}
+
+		duration, err := time.ParseDuration(routeAndDuration[1])
+		if err != nil {
+			return nil, fmt.Errorf("latency budget %q has an invalid duration: %w", budget, err)
+		}
+
+		b.routes[routeAndDuration[0]] = duration
+	}
+
+	return b, nil
+}
+
+func (b *latencyBudgets) budget(route string) time.Duration {
+	budget, ok := b.routes[route]
+	if !ok {
+		return b.defaultBudget
+	}
+
+	return budget
+}
+
+func (b *latencyBudgets) recordExceeded(route string) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.exceeded[route]++
+	return b.exceeded[route]
+}
+
+func (s *Server) setupLatencyBudgets() error {
+	budgets, err := newLatencyBudgets(s.Config.DefaultLatencyBudget, s.Config.LatencyBudgets)
+	if err != nil {
+		return err
+	}
+
+	s.latencyBudgets = budgets
+
+	return nil
+}
+
+// checkLatencyBudget logs requests which took longer than their route's budget.
+func (s *Server) checkLatencyBudget(c *gin.Context, latency time.Duration) {
+	if c.FullPath() == "" {
+		return
+	}
+
+	route := c.Request.Method + " " + c.FullPath()
+
+	budget := s.latencyBudgets.budget(route)
+	if budget <= 0 || latency <= budget {
+		return
+	}
+
+	count := s.latencyBudgets.recordExceeded(route)
+
+	fields := []zap.Field{zap.Duration("latency", latency), zap.Duration("budget", budget), zap.Uint64("exceededCount", count)}
+	if queries := queriesFromContext(c.Request.Context()); queries != nil {
+		fields = append(fields, queries.fields()...)
+	}
+
+	s.logger(c).Warn("request exceeded its latency budget", fields...)
+}
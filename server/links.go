@@ -0,0 +1,86 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// postLinks point to the post itself and, where it's known, to the posts of its author.
+type postLinks struct {
+	Self   string `json:"self"`
+	Author string `json:"author,omitempty"`
+}
+
+// listLinks point to the current, next and previous page of a list. Next is left out if the page isn't full and
+// prev on the first page.
+type listLinks struct {
+	Self string `json:"self"`
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+// baseURL is EXTERNAL_URL, or the scheme and host the request was made to if it isn't set. Behind a proxy
+// EXTERNAL_URL has to be set, since the request's host is the proxy's upstream.
+func (s *Server) baseURL(c *gin.Context) string {
+	if s.Config.ExternalURL != "" {
+		return strings.TrimSuffix(s.Config.ExternalURL, "/")
+	}
+
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+
+	return scheme + "://" + c.Request.Host
+}
+
+// resourceURL returns the absolute URL of a path under /v1.
+func (s *Server) resourceURL(c *gin.Context, path string) string {
+	return s.baseURL(c) + "/v1" + path
+}
+
+// postLinks returns the links of a post, authorUsername may be empty if the author isn't known.
+func (s *Server) postLinks(c *gin.Context, postId, authorUsername string) *postLinks {
+	links := &postLinks{Self: s.resourceURL(c, "/posts/"+postId)}
+
+	if authorUsername != "" {
+		links.Author = s.resourceURL(c, "/posts/user/"+url.PathEscape(authorUsername))
+	}
+
+	return links
+}
+
+// listLinks returns the links of the current page of a list, count is the number of items on it. Lists which
+// aren't paginated, with a limit of 0, only link to themselves.
+func (s *Server) listLinks(c *gin.Context, page, limit, count int) listLinks {
+	if limit == 0 {
+		self := s.baseURL(c) + c.Request.URL.Path
+		if c.Request.URL.RawQuery != "" {
+			self += "?" + c.Request.URL.RawQuery
+		}
+
+		return listLinks{Self: self}
+	}
+
+	pageURL := func(page int) string {
+		query := c.Request.URL.Query()
+		query.Set("page", strconv.Itoa(page))
+		query.Set("limit", strconv.Itoa(limit))
+
+		return s.baseURL(c) + c.Request.URL.Path + "?" + query.Encode()
+	}
+
+	links := listLinks{Self: pageURL(page)}
+
+	if count == limit {
+		links.Next = pageURL(page + 1)
+	}
+
+	if page > 1 {
+		links.Prev = pageURL(page - 1)
+	}
+
+	return links
+}
@@ -0,0 +1,33 @@
+package server
+
+import "github.com/gin-gonic/gin"
+
+// listResponse is the envelope of every collection endpoint.
+type listResponse[T any] struct {
+	Data  []T       `json:"data"`
+	Meta  listMeta  `json:"meta"`
+	Links listLinks `json:"links"`
+}
+
+type listMeta struct {
+	// Page and Limit are left out of lists which aren't paginated.
+	Page  int `json:"page,omitempty"`
+	Limit int `json:"limit,omitempty"`
+	// Count is the number of items in data.
+	Count int `json:"count"`
+	// Counts holds totals some lists report next to the page, e.g. the amount of unread notifications.
+	Counts map[string]int `json:"counts,omitempty"`
+}
+
+// newListResponse wraps a page of items, page and limit are 0 if the list isn't paginated.
+func newListResponse[T any](s *Server, c *gin.Context, data []T, page, limit int) listResponse[T] {
+	if data == nil {
+		data = []T{}
+	}
+
+	return listResponse[T]{
+		Data:  data,
+		Meta:  listMeta{Page: page, Limit: limit, Count: len(data)},
+		Links: s.listLinks(c, page, limit, len(data)),
+	}
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxMaintenanceMessageLength = 500
+	maxMaintenanceRetryAfter    = 24 * 60 * 60
+)
+
+// maintenanceExemptRoutes stay available during maintenance so admins can still log in and turn it off.
+var maintenanceExemptRoutes = map[string]bool{
+	"/metrics":                 true,
+	"/v1/health":               true,
+	"/v1/users/login":          true,
+	"/v1/users/login/mfa":      true,
+	"/v1/users/login/recovery": true,
+	"/v1/users/token/refresh":  true,
+}
+
+// setupMaintenance loads the maintenance state and keeps it up to date, every instance reads it from the database
+// so toggling it on one instance affects all of them. Instances reload it as soon as they're notified of a change
+// and every MAINTENANCE_REFRESH_INTERVAL in case a notification was missed.
+func (s *Server) setupMaintenance() error {
+	if s.Config.MaintenanceRefreshInterval <= 0 {
+		return fmt.Errorf("maintenance refresh interval must be positive")
+	}
+
+	maintenance, err := s.SettingsRepository.GetMaintenance(context.Background())
+	if err != nil {
+		return err
+	}
+
+	s.maintenanceState.Store(&maintenance)
+
+	if s.Notifications != nil {
+		err = s.Notifications.Subscribe(repository.ChannelMaintenanceChanged, func(string) {
+			s.refreshMaintenance()
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	go func() {
+		ticker := time.NewTicker(s.Config.MaintenanceRefreshInterval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			s.refreshMaintenance()
+		}
+	}()
+
+	return nil
+}
+
+func (s *Server) refreshMaintenance() {
+	maintenance, err := s.SettingsRepository.GetMaintenance(context.Background())
+	if err != nil {
+		s.Logger.Error("couldn't refresh maintenance state", zap.Error(err))
+		return
+	}
+
+	s.maintenanceState.Store(&maintenance)
+}
+
+// maintenanceMode answers with 503 while maintenance is enabled, except for admin routes, the routes
+// admins need to log in and, if reads are allowed, safe methods.
+func (s *Server) maintenanceMode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		maintenance := s.maintenanceState.Load()
+		if maintenance == nil || !maintenance.Enabled {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		if maintenanceExemptRoutes[route] || strings.HasPrefix(route, "/v1/admin/") {
+			c.Next()
+			return
+		}
+
+		if maintenance.AllowReads && (c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead || c.Request.Method == http.MethodOptions) {
+			c.Next()
+			return
+		}
+
+		message := maintenance.Message
+		if message == "" {
+			message = "the service is undergoing maintenance"
+		}
+
+		c.Header("Retry-After", strconv.Itoa(maintenance.RetryAfter))
+		s.abortWithError(c, http.StatusServiceUnavailable, codeMaintenance, message)
+	}
+}
+
+type maintenanceResponse struct {
+	Enabled    bool      `json:"enabled"`
+	AllowReads bool      `json:"allow_reads"`
+	Message    string    `json:"message"`
+	RetryAfter int       `json:"retry_after"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func newMaintenanceResponse(maintenance repository.Maintenance) maintenanceResponse {
+	return maintenanceResponse{
+		Enabled:    maintenance.Enabled,
+		AllowReads: maintenance.AllowReads,
+		Message:    maintenance.Message,
+		RetryAfter: maintenance.RetryAfter,
+		UpdatedAt:  maintenance.UpdatedAt,
+	}
+}
+
+// @Summary Returns the maintenance mode settings.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} maintenanceResponse
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/maintenance [get]
+func (s *Server) getMaintenanceHandler(c *gin.Context) {
+	maintenance, err := s.SettingsRepository.GetMaintenance(c.Request.Context())
+	if err != nil {
+		s.logger(c).Debug("couldn't get maintenance state", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newMaintenanceResponse(maintenance))
+}
+
+type updateMaintenanceRequest struct {
+	Enabled    bool   `json:"enabled"`
+	AllowReads bool   `json:"allow_reads"`
+	Message    string `json:"message" validate:"max=maxMaintenanceMessageLength"`
+	RetryAfter int    `json:"retry_after"`
+}
+
+// @Summary Turns maintenance mode on or off.
+// @Description While it's on, every route except the admin and login routes answers with 503 and a Retry-After header
+// @Description of retry_after seconds. If allow_reads is set, GET requests are still served.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body updateMaintenanceRequest true "Update maintenance body"
+// @Security ApiKeyAuth
+// @Success 200 {object} maintenanceResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/maintenance [put]
+func (s *Server) updateMaintenanceHandler(c *gin.Context) {
+	var request updateMaintenanceRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	request.Message = strings.TrimSpace(request.Message)
+
+	v := s.validator(c)
+	v.Struct(request)
+	v.IntRange("retry_after", request.RetryAfter, 1, maxMaintenanceRetryAfter)
+
+	ok, errors := v.IsValid()
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	before, err := s.SettingsRepository.GetMaintenance(c.Request.Context())
+	if err != nil {
+		s.logger(c).Debug("couldn't get maintenance state", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	maintenance, err := s.SettingsRepository.UpdateMaintenance(c.Request.Context(), repository.Maintenance{
+		Enabled:    request.Enabled,
+		AllowReads: request.AllowReads,
+		Message:    request.Message,
+		RetryAfter: request.RetryAfter,
+	})
+	if err != nil {
+		s.logger(c).Debug("couldn't update maintenance state", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	s.maintenanceState.Store(&maintenance)
+
+	s.logger(c).Info("maintenance mode updated", zap.Bool("enabled", maintenance.Enabled), zap.Bool("allowReads", maintenance.AllowReads))
+	s.audit(c, auditActionMaintenanceUpdate, objectMaintenance, 0, newMaintenanceResponse(before), newMaintenanceResponse(maintenance))
+
+	c.JSON(http.StatusOK, newMaintenanceResponse(maintenance))
+}
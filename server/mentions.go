@@ -0,0 +1,170 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/XiovV/blog-api/pkg/mailer"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"go.uber.org/zap"
+	"regexp"
+	"strings"
+)
+
+const (
+	jobTypeProcessMentions = "post.mentions"
+
+	// maxMentionsPerPost keeps a single post from notifying an arbitrary number of users.
+	maxMentionsPerPost = 20
+)
+
+// mentionPattern matches @username where the @ isn't part of a word, so email addresses aren't mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\w@])@([\w.-]{3,50})`)
+
+type mentionsJob struct {
+	PostID int `json:"post_id"`
+}
+
+// parseMentions returns the usernames mentioned in the text, without duplicates and in the order they first appear.
+func parseMentions(text string) []string {
+	var usernames []string
+	seen := map[string]bool{}
+
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		// a mention at the end of a sentence isn't followed by a space
+		username := strings.TrimRight(match[1], ".-")
+		if len(username) < 3 || seen[username] {
+			continue
+		}
+
+		seen[username] = true
+		usernames = append(usernames, username)
+
+		if len(usernames) == maxMentionsPerPost {
+			break
+		}
+	}
+
+	return usernames
+}
+
+// enqueueMentions has the post's mentions processed by the queue, so the request doesn't wait on looking up the
+// mentioned users and notifying them. Failures are only logged, they must not fail the request.
+func (s *Server) enqueueMentions(ctx context.Context, logger *zap.Logger, post repository.Post) {
+	if post.Status != repository.PostStatusPublished {
+		return
+	}
+
+	_, err := s.Queue.Enqueue(ctx, jobTypeProcessMentions, mentionsJob{PostID: post.ID})
+	if err != nil {
+		logger.Error("couldn't enqueue mentions", zap.Error(err), zap.Int("postId", post.ID))
+	}
+}
+
+// processMentionsJob records who is mentioned in the post and notifies the users who weren't mentioned in it
+// before, as far as their notification preferences allow. Users who can't see the post, like non-members
+// mentioned in an organization's post, are skipped.
+func (s *Server) processMentionsJob(ctx context.Context, payload json.RawMessage) error {
+	var job mentionsJob
+	err := json.Unmarshal(payload, &job)
+	if err != nil {
+		return err
+	}
+
+	post, err := s.PostRepository.FindPostByPostID(ctx, job.PostID)
+	if err != nil {
+		if errors.Is(err, repository.ErrPostNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	if post.Status != repository.PostStatusPublished {
+		return nil
+	}
+
+	author, err := s.UserRepository.FindUserByID(ctx, post.UserID)
+	if err != nil {
+		return err
+	}
+
+	// notifying anyone would give away the post of a shadowbanned user
+	if author.Shadowbanned {
+		return nil
+	}
+
+	var users []repository.User
+	if usernames := parseMentions(post.Body); len(usernames) > 0 {
+		users, err = s.UserRepository.FindUsersByUsernames(ctx, usernames)
+		if err != nil {
+			return err
+		}
+	}
+
+	mentioned := map[int]repository.User{}
+	userIds := []int{}
+	for _, user := range users {
+		if user.ID == author.ID || !user.Active {
+			continue
+		}
+
+		if post.OrganizationID != nil {
+			ok, err := s.authorizeInOrganization(ctx, user, *post.OrganizationID, objectPost, actionRead, 0)
+			if err != nil {
+				return err
+			}
+
+			if !ok {
+				continue
+			}
+		}
+
+		mentioned[user.ID] = user
+		userIds = append(userIds, user.ID)
+	}
+
+	// the mentions, notifications and emails are saved together, so a retry doesn't notify anyone twice
+	return s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		newlyMentioned, err := s.PostRepository.SetMentions(ctx, post.ID, userIds)
+		if err != nil {
+			return err
+		}
+
+		for _, userId := range newlyMentioned {
+			preferences, err := s.NotificationRepository.FindNotificationPreferences(ctx, userId)
+			if err != nil {
+				return err
+			}
+
+			if preferences.MentionInApp {
+				_, err = s.NotificationRepository.InsertNotification(ctx, repository.Notification{
+					UserID:  userId,
+					Type:    repository.NotificationTypeMention,
+					ActorID: &author.ID,
+					PostID:  &post.ID,
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			user := mentioned[userId]
+			if !s.Config.MentionEmails || !preferences.MentionEmail || user.EmailVerifiedAt == nil {
+				continue
+			}
+
+			err = s.enqueueEmail(ctx, user, mailer.Mention{
+				Username:  user.Username,
+				Author:    author.Username,
+				PostID:    post.PublicID,
+				PostTitle: post.Title,
+			}, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"net/http"
+	"net/url"
+	"time"
 )
 
 func (s *Server) errorHandler() gin.HandlerFunc {
@@ -13,59 +15,146 @@ func (s *Server) errorHandler() gin.HandlerFunc {
 		c.Next()
 
 		err := c.Errors.Last()
+		if err == nil {
+			return
+		}
+
+		known, isKnown := findKnownError(err)
+		var errInvalidInput ErrInvalidInput
+		var errValidationFailed ErrValidationFailed
+
+		switch {
+		case errors.Is(err, repository.ErrCanceled), c.Request.Context().Err() != nil:
+			// the client went away, there's nobody to respond to
+			s.logger(c).Debug("request canceled", zap.Error(err))
+			c.Status(statusClientClosedRequest)
+		case isKnown:
+			s.errorResponse(c, known.status, known.code, known.err.Error())
+		case errors.As(err, &errInvalidInput):
+			s.errorResponse(c, http.StatusBadRequest, codeInvalidInput, errInvalidInput.Message)
+		case errors.As(err, &errValidationFailed):
+			s.validationErrorResponse(c, errValidationFailed.Fields)
+		case errors.Is(err, repository.ErrUniqueViolation), errors.Is(err, repository.ErrForeignKeyViolation):
+			s.logger(c).Debug("constraint violated", zap.Error(err))
+			s.errorResponse(c, http.StatusConflict, codeConflict, "the request conflicts with existing data")
+		case errors.Is(err, repository.ErrSerializationFailure):
+			s.logger(c).Warn("transaction conflict", zap.Error(err))
+			s.errorResponse(c, http.StatusConflict, codeTransactionConflict, "the request conflicted with a concurrent request, please retry")
+		case errors.Is(err, repository.ErrTimeout):
+			s.logger(c).Error("query timed out", zap.Error(err))
+			s.errorResponse(c, http.StatusServiceUnavailable, codeServiceUnavailable, "the service is temporarily unavailable, please retry")
+		default:
+			s.logger(c).Error("uncaught error", zap.Error(err))
+			s.internalServerErrorResponse(c)
+		}
+	}
+}
+
+// statusClientClosedRequest is nginx's non-standard status for requests the client gave up on.
+const statusClientClosedRequest = 499
+
+const (
+	requestIdHeader    = "X-Request-ID"
+	requestIdKey       = "requestId"
+	maxRequestIdLength = 128
+)
 
-		if err != nil {
-			var errInvalidInput ErrInvalidInput
-
-			switch {
-			case errors.Is(err, repository.ErrUserAlreadyExists):
-				c.JSON(http.StatusConflict, err)
-			case errors.Is(err, repository.ErrUserNotFound), errors.Is(err, repository.ErrPostNotFound):
-				c.JSON(http.StatusNotFound, err)
-			case errors.Is(err, ErrInvalidJSON):
-				c.JSON(http.StatusBadRequest, err)
-			case errors.As(err, &errInvalidInput):
-				c.JSON(http.StatusBadRequest, gin.H{"error": errInvalidInput.Message})
-			default:
-				s.Logger.Error("uncaught error", zap.Error(err))
-				s.internalServerErrorResponse(c)
-			}
+// requestID propagates the client's X-Request-ID, or generates a new one, and returns it in the response.
+func (s *Server) requestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestId := c.GetHeader(requestIdHeader)
+		if !isValidRequestID(requestId) {
+			requestId = newRequestID()
 		}
 
+		c.Set(requestIdKey, requestId)
+		c.Header(requestIdHeader, requestId)
+		s.addLoggerFields(c, zap.String("requestId", requestId))
+
+		c.Next()
+	}
+}
+
+func (s *Server) requestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s.addLoggerFields(c, zap.String("method", c.Request.Method), zap.String("route", c.FullPath()))
+
+		c.Next()
 	}
 }
 
+// accessLogger logs every request once it's been handled.
+func (s *Server) accessLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		latency := time.Since(start)
+		s.checkLatencyBudget(c, latency)
+
+		status := c.Writer.Status()
+		fields := []zap.Field{
+			zap.String("path", c.Request.URL.Path),
+			zap.String("query", redactQuery(c.Request.URL.Query())),
+			zap.Int("status", status),
+			zap.Duration("latency", latency),
+			zap.String("clientIp", c.ClientIP()),
+			zap.Int("size", c.Writer.Size()),
+		}
+
+		logger := s.logger(c)
+		if status >= http.StatusInternalServerError {
+			logger.Error("request handled", fields...)
+			return
+		}
+
+		logger.Info("request handled", fields...)
+	}
+}
+
+func redactQuery(query url.Values) string {
+	for _, param := range redactedFields {
+		if query.Has(param) {
+			query.Set(param, "REDACTED")
+		}
+	}
+
+	return query.Encode()
+}
+
 func (s *Server) userAuth(c *gin.Context) {
 	authToken, err := s.validateAuthorizationHeader(c)
 	if err != nil {
-		s.Logger.Debug("authorization header validation error", zap.Error(err))
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err})
+		s.logger(c).Debug("authorization header validation error", zap.Error(err))
+		s.abortWithError(c, http.StatusForbidden, codeInvalidToken, err.Error())
 		return
 	}
 
-	token, err := validateAccessToken(authToken)
+	token, err := s.validateAccessToken(authToken)
 	if err != nil {
-		s.Logger.Debug("invalid token", zap.Error(err))
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "invalid token"})
+		s.logger(c).Debug("invalid token", zap.Error(err))
+		s.abortWithError(c, http.StatusForbidden, codeInvalidToken, "invalid token")
 		return
 	}
 
 	userId := token.ID
 
-	user, err := s.UserRepository.FindUserByID(userId)
+	user, err := s.UserRepository.FindUserByID(c.Request.Context(), userId)
 	if err != nil {
-		s.Logger.Debug("couldn't find user", zap.Error(err), zap.Int("userId", userId))
-		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.Int("userId", userId))
+		s.abortWithError(c, http.StatusNotFound, codeUserNotFound, "user not found")
 		return
 	}
 
 	if !user.Active {
-		s.Logger.Debug("user is inactive", zap.String("username", user.Username))
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "user inactive"})
+		s.logger(c).Debug("user is inactive", zap.String("username", user.Username))
+		s.abortWithError(c, http.StatusForbidden, codeUserInactive, "user inactive")
 		return
 	}
 
 	c.Set("user", user)
+	s.addLoggerFields(c, zap.Int("userId", user.ID), zap.String("username", user.Username))
 
 	c.Next()
 }
@@ -74,7 +163,8 @@ func (s *Server) CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
 
 		if c.Request.Method == "OPTIONS" {
@@ -0,0 +1,1891 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: stores.go
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	repository "github.com/XiovV/blog-api/pkg/repository"
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockUserStore is a mock of UserStore interface.
+type MockUserStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockUserStoreMockRecorder
+}
+
+// MockUserStoreMockRecorder is the mock recorder for MockUserStore.
+type MockUserStoreMockRecorder struct {
+	mock *MockUserStore
+}
+
+// NewMockUserStore creates a new mock instance.
+func NewMockUserStore(ctrl *gomock.Controller) *MockUserStore {
+	mock := &MockUserStore{ctrl: ctrl}
+	mock.recorder = &MockUserStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockUserStore) EXPECT() *MockUserStoreMockRecorder {
+	return m.recorder
+}
+
+// CountUsersWithRole mocks base method.
+func (m *MockUserStore) CountUsersWithRole(ctx context.Context, name string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUsersWithRole", ctx, name)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUsersWithRole indicates an expected call of CountUsersWithRole.
+func (mr *MockUserStoreMockRecorder) CountUsersWithRole(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUsersWithRole", reflect.TypeOf((*MockUserStore)(nil).CountUsersWithRole), ctx, name)
+}
+
+// DeleteAPIToken mocks base method.
+func (m *MockUserStore) DeleteAPIToken(ctx context.Context, userId, tokenId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIToken", ctx, userId, tokenId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIToken indicates an expected call of DeleteAPIToken.
+func (mr *MockUserStoreMockRecorder) DeleteAPIToken(ctx, userId, tokenId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIToken", reflect.TypeOf((*MockUserStore)(nil).DeleteAPIToken), ctx, userId, tokenId)
+}
+
+// DeleteAllPasswordResetTokensForUser mocks base method.
+func (m *MockUserStore) DeleteAllPasswordResetTokensForUser(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAllPasswordResetTokensForUser", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAllPasswordResetTokensForUser indicates an expected call of DeleteAllPasswordResetTokensForUser.
+func (mr *MockUserStoreMockRecorder) DeleteAllPasswordResetTokensForUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAllPasswordResetTokensForUser", reflect.TypeOf((*MockUserStore)(nil).DeleteAllPasswordResetTokensForUser), ctx, userId)
+}
+
+// DeleteExpiredPasswordResetTokens mocks base method.
+func (m *MockUserStore) DeleteExpiredPasswordResetTokens(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredPasswordResetTokens", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredPasswordResetTokens indicates an expected call of DeleteExpiredPasswordResetTokens.
+func (mr *MockUserStoreMockRecorder) DeleteExpiredPasswordResetTokens(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredPasswordResetTokens", reflect.TypeOf((*MockUserStore)(nil).DeleteExpiredPasswordResetTokens), ctx)
+}
+
+// DeleteExpiredRefreshTokens mocks base method.
+func (m *MockUserStore) DeleteExpiredRefreshTokens(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteExpiredRefreshTokens", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteExpiredRefreshTokens indicates an expected call of DeleteExpiredRefreshTokens.
+func (mr *MockUserStoreMockRecorder) DeleteExpiredRefreshTokens(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteExpiredRefreshTokens", reflect.TypeOf((*MockUserStore)(nil).DeleteExpiredRefreshTokens), ctx)
+}
+
+// DeletePasswordResetToken mocks base method.
+func (m *MockUserStore) DeletePasswordResetToken(ctx context.Context, token string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePasswordResetToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePasswordResetToken indicates an expected call of DeletePasswordResetToken.
+func (mr *MockUserStoreMockRecorder) DeletePasswordResetToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePasswordResetToken", reflect.TypeOf((*MockUserStore)(nil).DeletePasswordResetToken), ctx, token)
+}
+
+// DeleteUnverifiedUsers mocks base method.
+func (m *MockUserStore) DeleteUnverifiedUsers(ctx context.Context, createdBefore time.Time, remindedBefore *time.Time) ([]repository.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUnverifiedUsers", ctx, createdBefore, remindedBefore)
+	ret0, _ := ret[0].([]repository.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteUnverifiedUsers indicates an expected call of DeleteUnverifiedUsers.
+func (mr *MockUserStoreMockRecorder) DeleteUnverifiedUsers(ctx, createdBefore, remindedBefore interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUnverifiedUsers", reflect.TypeOf((*MockUserStore)(nil).DeleteUnverifiedUsers), ctx, createdBefore, remindedBefore)
+}
+
+// DeleteUserByID mocks base method.
+func (m *MockUserStore) DeleteUserByID(ctx context.Context, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUserByID", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUserByID indicates an expected call of DeleteUserByID.
+func (mr *MockUserStoreMockRecorder) DeleteUserByID(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserByID", reflect.TypeOf((*MockUserStore)(nil).DeleteUserByID), ctx, userId)
+}
+
+// EnsureRole mocks base method.
+func (m *MockUserStore) EnsureRole(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EnsureRole", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EnsureRole indicates an expected call of EnsureRole.
+func (mr *MockUserStoreMockRecorder) EnsureRole(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EnsureRole", reflect.TypeOf((*MockUserStore)(nil).EnsureRole), ctx, name)
+}
+
+// FindAPITokensByUserID mocks base method.
+func (m *MockUserStore) FindAPITokensByUserID(ctx context.Context, userId int) ([]repository.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindAPITokensByUserID", ctx, userId)
+	ret0, _ := ret[0].([]repository.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindAPITokensByUserID indicates an expected call of FindAPITokensByUserID.
+func (mr *MockUserStoreMockRecorder) FindAPITokensByUserID(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindAPITokensByUserID", reflect.TypeOf((*MockUserStore)(nil).FindAPITokensByUserID), ctx, userId)
+}
+
+// FindUserByEmail mocks base method.
+func (m *MockUserStore) FindUserByEmail(ctx context.Context, email string) (repository.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByEmail", ctx, email)
+	ret0, _ := ret[0].(repository.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByEmail indicates an expected call of FindUserByEmail.
+func (mr *MockUserStoreMockRecorder) FindUserByEmail(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByEmail", reflect.TypeOf((*MockUserStore)(nil).FindUserByEmail), ctx, email)
+}
+
+// FindUserByID mocks base method.
+func (m *MockUserStore) FindUserByID(ctx context.Context, id int) (repository.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByID", ctx, id)
+	ret0, _ := ret[0].(repository.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByID indicates an expected call of FindUserByID.
+func (mr *MockUserStoreMockRecorder) FindUserByID(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByID", reflect.TypeOf((*MockUserStore)(nil).FindUserByID), ctx, id)
+}
+
+// FindUserByPublicID mocks base method.
+func (m *MockUserStore) FindUserByPublicID(ctx context.Context, publicId string) (repository.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByPublicID", ctx, publicId)
+	ret0, _ := ret[0].(repository.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByPublicID indicates an expected call of FindUserByPublicID.
+func (mr *MockUserStoreMockRecorder) FindUserByPublicID(ctx, publicId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByPublicID", reflect.TypeOf((*MockUserStore)(nil).FindUserByPublicID), ctx, publicId)
+}
+
+// FindUserByUsername mocks base method.
+func (m *MockUserStore) FindUserByUsername(ctx context.Context, username string) (repository.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByUsername", ctx, username)
+	ret0, _ := ret[0].(repository.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByUsername indicates an expected call of FindUserByUsername.
+func (mr *MockUserStoreMockRecorder) FindUserByUsername(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByUsername", reflect.TypeOf((*MockUserStore)(nil).FindUserByUsername), ctx, username)
+}
+
+// FindUsersByUsernames mocks base method.
+func (m *MockUserStore) FindUsersByUsernames(ctx context.Context, usernames []string) ([]repository.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUsersByUsernames", ctx, usernames)
+	ret0, _ := ret[0].([]repository.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUsersByUsernames indicates an expected call of FindUsersByUsernames.
+func (mr *MockUserStoreMockRecorder) FindUsersByUsernames(ctx, usernames interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUsersByUsernames", reflect.TypeOf((*MockUserStore)(nil).FindUsersByUsernames), ctx, usernames)
+}
+
+// FindUsersToRemind mocks base method.
+func (m *MockUserStore) FindUsersToRemind(ctx context.Context, createdBefore time.Time, limit int) ([]repository.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUsersToRemind", ctx, createdBefore, limit)
+	ret0, _ := ret[0].([]repository.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUsersToRemind indicates an expected call of FindUsersToRemind.
+func (mr *MockUserStoreMockRecorder) FindUsersToRemind(ctx, createdBefore, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUsersToRemind", reflect.TypeOf((*MockUserStore)(nil).FindUsersToRemind), ctx, createdBefore, limit)
+}
+
+// FindUsersWithMfaSecret mocks base method.
+func (m *MockUserStore) FindUsersWithMfaSecret(ctx context.Context) ([]repository.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUsersWithMfaSecret", ctx)
+	ret0, _ := ret[0].([]repository.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUsersWithMfaSecret indicates an expected call of FindUsersWithMfaSecret.
+func (mr *MockUserStoreMockRecorder) FindUsersWithMfaSecret(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUsersWithMfaSecret", reflect.TypeOf((*MockUserStore)(nil).FindUsersWithMfaSecret), ctx)
+}
+
+// GetPasswordResetToken mocks base method.
+func (m *MockUserStore) GetPasswordResetToken(ctx context.Context, token string) (repository.PasswordResetToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPasswordResetToken", ctx, token)
+	ret0, _ := ret[0].(repository.PasswordResetToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPasswordResetToken indicates an expected call of GetPasswordResetToken.
+func (mr *MockUserStoreMockRecorder) GetPasswordResetToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPasswordResetToken", reflect.TypeOf((*MockUserStore)(nil).GetPasswordResetToken), ctx, token)
+}
+
+// GetUserRecoveryCodes mocks base method.
+func (m *MockUserStore) GetUserRecoveryCodes(ctx context.Context, username string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRecoveryCodes", ctx, username)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRecoveryCodes indicates an expected call of GetUserRecoveryCodes.
+func (mr *MockUserStoreMockRecorder) GetUserRecoveryCodes(ctx, username interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRecoveryCodes", reflect.TypeOf((*MockUserStore)(nil).GetUserRecoveryCodes), ctx, username)
+}
+
+// GetVerificationToken mocks base method.
+func (m *MockUserStore) GetVerificationToken(ctx context.Context, tokenHash string) (repository.EmailVerificationToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVerificationToken", ctx, tokenHash)
+	ret0, _ := ret[0].(repository.EmailVerificationToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVerificationToken indicates an expected call of GetVerificationToken.
+func (mr *MockUserStoreMockRecorder) GetVerificationToken(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVerificationToken", reflect.TypeOf((*MockUserStore)(nil).GetVerificationToken), ctx, tokenHash)
+}
+
+// InsertAPIToken mocks base method.
+func (m *MockUserStore) InsertAPIToken(ctx context.Context, token repository.APIToken) (repository.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertAPIToken", ctx, token)
+	ret0, _ := ret[0].(repository.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertAPIToken indicates an expected call of InsertAPIToken.
+func (mr *MockUserStoreMockRecorder) InsertAPIToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertAPIToken", reflect.TypeOf((*MockUserStore)(nil).InsertAPIToken), ctx, token)
+}
+
+// InsertMfaSecret mocks base method.
+func (m *MockUserStore) InsertMfaSecret(ctx context.Context, userId int, secret []byte, recoveryCodes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertMfaSecret", ctx, userId, secret, recoveryCodes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertMfaSecret indicates an expected call of InsertMfaSecret.
+func (mr *MockUserStoreMockRecorder) InsertMfaSecret(ctx, userId, secret, recoveryCodes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertMfaSecret", reflect.TypeOf((*MockUserStore)(nil).InsertMfaSecret), ctx, userId, secret, recoveryCodes)
+}
+
+// InsertPasswordResetToken mocks base method.
+func (m *MockUserStore) InsertPasswordResetToken(ctx context.Context, token repository.PasswordResetToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPasswordResetToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertPasswordResetToken indicates an expected call of InsertPasswordResetToken.
+func (mr *MockUserStoreMockRecorder) InsertPasswordResetToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPasswordResetToken", reflect.TypeOf((*MockUserStore)(nil).InsertPasswordResetToken), ctx, token)
+}
+
+// InsertRefreshToken mocks base method.
+func (m *MockUserStore) InsertRefreshToken(ctx context.Context, token repository.RefreshToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertRefreshToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertRefreshToken indicates an expected call of InsertRefreshToken.
+func (mr *MockUserStoreMockRecorder) InsertRefreshToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertRefreshToken", reflect.TypeOf((*MockUserStore)(nil).InsertRefreshToken), ctx, token)
+}
+
+// InsertUser mocks base method.
+func (m *MockUserStore) InsertUser(ctx context.Context, user repository.User) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertUser", ctx, user)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertUser indicates an expected call of InsertUser.
+func (mr *MockUserStoreMockRecorder) InsertUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertUser", reflect.TypeOf((*MockUserStore)(nil).InsertUser), ctx, user)
+}
+
+// InsertVerificationToken mocks base method.
+func (m *MockUserStore) InsertVerificationToken(ctx context.Context, token repository.EmailVerificationToken) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertVerificationToken", ctx, token)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertVerificationToken indicates an expected call of InsertVerificationToken.
+func (mr *MockUserStoreMockRecorder) InsertVerificationToken(ctx, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertVerificationToken", reflect.TypeOf((*MockUserStore)(nil).InsertVerificationToken), ctx, token)
+}
+
+// IsEmailUndeliverable mocks base method.
+func (m *MockUserStore) IsEmailUndeliverable(ctx context.Context, email string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEmailUndeliverable", ctx, email)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsEmailUndeliverable indicates an expected call of IsEmailUndeliverable.
+func (mr *MockUserStoreMockRecorder) IsEmailUndeliverable(ctx, email interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmailUndeliverable", reflect.TypeOf((*MockUserStore)(nil).IsEmailUndeliverable), ctx, email)
+}
+
+// IsRefreshTokenBlacklisted mocks base method.
+func (m *MockUserStore) IsRefreshTokenBlacklisted(ctx context.Context, userId int, token string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRefreshTokenBlacklisted", ctx, userId, token)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRefreshTokenBlacklisted indicates an expected call of IsRefreshTokenBlacklisted.
+func (mr *MockUserStoreMockRecorder) IsRefreshTokenBlacklisted(ctx, userId, token interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRefreshTokenBlacklisted", reflect.TypeOf((*MockUserStore)(nil).IsRefreshTokenBlacklisted), ctx, userId, token)
+}
+
+// MarkEmailUndeliverable mocks base method.
+func (m *MockUserStore) MarkEmailUndeliverable(ctx context.Context, email, reason string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailUndeliverable", ctx, email, reason)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkEmailUndeliverable indicates an expected call of MarkEmailUndeliverable.
+func (mr *MockUserStoreMockRecorder) MarkEmailUndeliverable(ctx, email, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailUndeliverable", reflect.TypeOf((*MockUserStore)(nil).MarkEmailUndeliverable), ctx, email, reason)
+}
+
+// MarkEmailVerified mocks base method.
+func (m *MockUserStore) MarkEmailVerified(ctx context.Context, userId int) (time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEmailVerified", ctx, userId)
+	ret0, _ := ret[0].(time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkEmailVerified indicates an expected call of MarkEmailVerified.
+func (mr *MockUserStoreMockRecorder) MarkEmailVerified(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEmailVerified", reflect.TypeOf((*MockUserStore)(nil).MarkEmailVerified), ctx, userId)
+}
+
+// MarkVerificationReminderSent mocks base method.
+func (m *MockUserStore) MarkVerificationReminderSent(ctx context.Context, userId int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkVerificationReminderSent", ctx, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkVerificationReminderSent indicates an expected call of MarkVerificationReminderSent.
+func (mr *MockUserStoreMockRecorder) MarkVerificationReminderSent(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkVerificationReminderSent", reflect.TypeOf((*MockUserStore)(nil).MarkVerificationReminderSent), ctx, userId)
+}
+
+// PromoteUsers mocks base method.
+func (m *MockUserStore) PromoteUsers(ctx context.Context, fromRole, toRole string, minPosts int) ([]repository.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoteUsers", ctx, fromRole, toRole, minPosts)
+	ret0, _ := ret[0].([]repository.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PromoteUsers indicates an expected call of PromoteUsers.
+func (mr *MockUserStoreMockRecorder) PromoteUsers(ctx, fromRole, toRole, minPosts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoteUsers", reflect.TypeOf((*MockUserStore)(nil).PromoteUsers), ctx, fromRole, toRole, minPosts)
+}
+
+// ReplaceMfaSecret mocks base method.
+func (m *MockUserStore) ReplaceMfaSecret(ctx context.Context, userId int, oldSecret, secret []byte) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReplaceMfaSecret", ctx, userId, oldSecret, secret)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReplaceMfaSecret indicates an expected call of ReplaceMfaSecret.
+func (mr *MockUserStoreMockRecorder) ReplaceMfaSecret(ctx, userId, oldSecret, secret interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReplaceMfaSecret", reflect.TypeOf((*MockUserStore)(nil).ReplaceMfaSecret), ctx, userId, oldSecret, secret)
+}
+
+// RoleExists mocks base method.
+func (m *MockUserStore) RoleExists(ctx context.Context, name string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RoleExists", ctx, name)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RoleExists indicates an expected call of RoleExists.
+func (mr *MockUserStoreMockRecorder) RoleExists(ctx, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RoleExists", reflect.TypeOf((*MockUserStore)(nil).RoleExists), ctx, name)
+}
+
+// SetActiveState mocks base method.
+func (m *MockUserStore) SetActiveState(ctx context.Context, userId int, active bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetActiveState", ctx, userId, active)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetActiveState indicates an expected call of SetActiveState.
+func (mr *MockUserStoreMockRecorder) SetActiveState(ctx, userId, active interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActiveState", reflect.TypeOf((*MockUserStore)(nil).SetActiveState), ctx, userId, active)
+}
+
+// SetLocale mocks base method.
+func (m *MockUserStore) SetLocale(ctx context.Context, userId int, locale string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLocale", ctx, userId, locale)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLocale indicates an expected call of SetLocale.
+func (mr *MockUserStoreMockRecorder) SetLocale(ctx, userId, locale interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLocale", reflect.TypeOf((*MockUserStore)(nil).SetLocale), ctx, userId, locale)
+}
+
+// SetPassword mocks base method.
+func (m *MockUserStore) SetPassword(ctx context.Context, userId int, password string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetPassword", ctx, userId, password)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetPassword indicates an expected call of SetPassword.
+func (mr *MockUserStoreMockRecorder) SetPassword(ctx, userId, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPassword", reflect.TypeOf((*MockUserStore)(nil).SetPassword), ctx, userId, password)
+}
+
+// SetRecoveryCodes mocks base method.
+func (m *MockUserStore) SetRecoveryCodes(ctx context.Context, userId int, recoveryCodes []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRecoveryCodes", ctx, userId, recoveryCodes)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRecoveryCodes indicates an expected call of SetRecoveryCodes.
+func (mr *MockUserStoreMockRecorder) SetRecoveryCodes(ctx, userId, recoveryCodes interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRecoveryCodes", reflect.TypeOf((*MockUserStore)(nil).SetRecoveryCodes), ctx, userId, recoveryCodes)
+}
+
+// SetRole mocks base method.
+func (m *MockUserStore) SetRole(ctx context.Context, userId int, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetRole", ctx, userId, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetRole indicates an expected call of SetRole.
+func (mr *MockUserStoreMockRecorder) SetRole(ctx, userId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetRole", reflect.TypeOf((*MockUserStore)(nil).SetRole), ctx, userId, role)
+}
+
+// SetShadowbanned mocks base method.
+func (m *MockUserStore) SetShadowbanned(ctx context.Context, userId int, shadowbanned bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetShadowbanned", ctx, userId, shadowbanned)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetShadowbanned indicates an expected call of SetShadowbanned.
+func (mr *MockUserStoreMockRecorder) SetShadowbanned(ctx, userId, shadowbanned interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetShadowbanned", reflect.TypeOf((*MockUserStore)(nil).SetShadowbanned), ctx, userId, shadowbanned)
+}
+
+// SetVerified mocks base method.
+func (m *MockUserStore) SetVerified(ctx context.Context, userId int, verified bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVerified", ctx, userId, verified)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetVerified indicates an expected call of SetVerified.
+func (mr *MockUserStoreMockRecorder) SetVerified(ctx, userId, verified interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVerified", reflect.TypeOf((*MockUserStore)(nil).SetVerified), ctx, userId, verified)
+}
+
+// UseAPIToken mocks base method.
+func (m *MockUserStore) UseAPIToken(ctx context.Context, tokenHash string) (repository.APIToken, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UseAPIToken", ctx, tokenHash)
+	ret0, _ := ret[0].(repository.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UseAPIToken indicates an expected call of UseAPIToken.
+func (mr *MockUserStoreMockRecorder) UseAPIToken(ctx, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UseAPIToken", reflect.TypeOf((*MockUserStore)(nil).UseAPIToken), ctx, tokenHash)
+}
+
+// MockPostStore is a mock of PostStore interface.
+type MockPostStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockPostStoreMockRecorder
+}
+
+// MockPostStoreMockRecorder is the mock recorder for MockPostStore.
+type MockPostStoreMockRecorder struct {
+	mock *MockPostStore
+}
+
+// NewMockPostStore creates a new mock instance.
+func NewMockPostStore(ctrl *gomock.Controller) *MockPostStore {
+	mock := &MockPostStore{ctrl: ctrl}
+	mock.recorder = &MockPostStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPostStore) EXPECT() *MockPostStoreMockRecorder {
+	return m.recorder
+}
+
+// CountByUserID mocks base method.
+func (m *MockPostStore) CountByUserID(ctx context.Context, userId int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountByUserID", ctx, userId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountByUserID indicates an expected call of CountByUserID.
+func (mr *MockPostStoreMockRecorder) CountByUserID(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountByUserID", reflect.TypeOf((*MockPostStore)(nil).CountByUserID), ctx, userId)
+}
+
+// CountCoAuthors mocks base method.
+func (m *MockPostStore) CountCoAuthors(ctx context.Context, postId int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountCoAuthors", ctx, postId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountCoAuthors indicates an expected call of CountCoAuthors.
+func (mr *MockPostStoreMockRecorder) CountCoAuthors(ctx, postId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountCoAuthors", reflect.TypeOf((*MockPostStore)(nil).CountCoAuthors), ctx, postId)
+}
+
+// DeleteCoAuthor mocks base method.
+func (m *MockPostStore) DeleteCoAuthor(ctx context.Context, postId, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCoAuthor", ctx, postId, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCoAuthor indicates an expected call of DeleteCoAuthor.
+func (mr *MockPostStoreMockRecorder) DeleteCoAuthor(ctx, postId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCoAuthor", reflect.TypeOf((*MockPostStore)(nil).DeleteCoAuthor), ctx, postId, userId)
+}
+
+// DeletePostByPostID mocks base method.
+func (m *MockPostStore) DeletePostByPostID(ctx context.Context, postId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePostByPostID", ctx, postId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePostByPostID indicates an expected call of DeletePostByPostID.
+func (mr *MockPostStoreMockRecorder) DeletePostByPostID(ctx, postId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePostByPostID", reflect.TypeOf((*MockPostStore)(nil).DeletePostByPostID), ctx, postId)
+}
+
+// FindByOrganizationID mocks base method.
+func (m *MockPostStore) FindByOrganizationID(ctx context.Context, organizationId int, options repository.PostListOptions, page, limit int) ([]repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByOrganizationID", ctx, organizationId, options, page, limit)
+	ret0, _ := ret[0].([]repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByOrganizationID indicates an expected call of FindByOrganizationID.
+func (mr *MockPostStoreMockRecorder) FindByOrganizationID(ctx, organizationId, options, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByOrganizationID", reflect.TypeOf((*MockPostStore)(nil).FindByOrganizationID), ctx, organizationId, options, page, limit)
+}
+
+// FindByUserID mocks base method.
+func (m *MockPostStore) FindByUserID(ctx context.Context, userId int, options repository.PostListOptions, page, limit int) ([]repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindByUserID", ctx, userId, options, page, limit)
+	ret0, _ := ret[0].([]repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindByUserID indicates an expected call of FindByUserID.
+func (mr *MockPostStoreMockRecorder) FindByUserID(ctx, userId, options, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindByUserID", reflect.TypeOf((*MockPostStore)(nil).FindByUserID), ctx, userId, options, page, limit)
+}
+
+// FindHeldPosts mocks base method.
+func (m *MockPostStore) FindHeldPosts(ctx context.Context, page, limit int) ([]repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindHeldPosts", ctx, page, limit)
+	ret0, _ := ret[0].([]repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindHeldPosts indicates an expected call of FindHeldPosts.
+func (mr *MockPostStoreMockRecorder) FindHeldPosts(ctx, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindHeldPosts", reflect.TypeOf((*MockPostStore)(nil).FindHeldPosts), ctx, page, limit)
+}
+
+// FindLatestByUserID mocks base method.
+func (m *MockPostStore) FindLatestByUserID(ctx context.Context, userId, page, limit int) ([]repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLatestByUserID", ctx, userId, page, limit)
+	ret0, _ := ret[0].([]repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindLatestByUserID indicates an expected call of FindLatestByUserID.
+func (mr *MockPostStoreMockRecorder) FindLatestByUserID(ctx, userId, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLatestByUserID", reflect.TypeOf((*MockPostStore)(nil).FindLatestByUserID), ctx, userId, page, limit)
+}
+
+// FindPostAuthors mocks base method.
+func (m *MockPostStore) FindPostAuthors(ctx context.Context, postId int) ([]repository.PostAuthor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPostAuthors", ctx, postId)
+	ret0, _ := ret[0].([]repository.PostAuthor)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPostAuthors indicates an expected call of FindPostAuthors.
+func (mr *MockPostStoreMockRecorder) FindPostAuthors(ctx, postId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPostAuthors", reflect.TypeOf((*MockPostStore)(nil).FindPostAuthors), ctx, postId)
+}
+
+// FindPostByPostID mocks base method.
+func (m *MockPostStore) FindPostByPostID(ctx context.Context, postId int) (repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPostByPostID", ctx, postId)
+	ret0, _ := ret[0].(repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPostByPostID indicates an expected call of FindPostByPostID.
+func (mr *MockPostStoreMockRecorder) FindPostByPostID(ctx, postId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPostByPostID", reflect.TypeOf((*MockPostStore)(nil).FindPostByPostID), ctx, postId)
+}
+
+// FindPostByPublicID mocks base method.
+func (m *MockPostStore) FindPostByPublicID(ctx context.Context, publicId string) (repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPostByPublicID", ctx, publicId)
+	ret0, _ := ret[0].(repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPostByPublicID indicates an expected call of FindPostByPublicID.
+func (mr *MockPostStoreMockRecorder) FindPostByPublicID(ctx, publicId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPostByPublicID", reflect.TypeOf((*MockPostStore)(nil).FindPostByPublicID), ctx, publicId)
+}
+
+// InsertCoAuthor mocks base method.
+func (m *MockPostStore) InsertCoAuthor(ctx context.Context, postId, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertCoAuthor", ctx, postId, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertCoAuthor indicates an expected call of InsertCoAuthor.
+func (mr *MockPostStoreMockRecorder) InsertCoAuthor(ctx, postId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertCoAuthor", reflect.TypeOf((*MockPostStore)(nil).InsertCoAuthor), ctx, postId, userId)
+}
+
+// InsertPost mocks base method.
+func (m *MockPostStore) InsertPost(ctx context.Context, post repository.Post) (repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPost", ctx, post)
+	ret0, _ := ret[0].(repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPost indicates an expected call of InsertPost.
+func (mr *MockPostStoreMockRecorder) InsertPost(ctx, post interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPost", reflect.TypeOf((*MockPostStore)(nil).InsertPost), ctx, post)
+}
+
+// InsertPosts mocks base method.
+func (m *MockPostStore) InsertPosts(ctx context.Context, posts []repository.Post) ([]repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertPosts", ctx, posts)
+	ret0, _ := ret[0].([]repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertPosts indicates an expected call of InsertPosts.
+func (mr *MockPostStoreMockRecorder) InsertPosts(ctx, posts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertPosts", reflect.TypeOf((*MockPostStore)(nil).InsertPosts), ctx, posts)
+}
+
+// IsCoAuthor mocks base method.
+func (m *MockPostStore) IsCoAuthor(ctx context.Context, postId, userId int) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsCoAuthor", ctx, postId, userId)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsCoAuthor indicates an expected call of IsCoAuthor.
+func (mr *MockPostStoreMockRecorder) IsCoAuthor(ctx, postId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsCoAuthor", reflect.TypeOf((*MockPostStore)(nil).IsCoAuthor), ctx, postId, userId)
+}
+
+// PublishHeldPost mocks base method.
+func (m *MockPostStore) PublishHeldPost(ctx context.Context, postId int) (repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishHeldPost", ctx, postId)
+	ret0, _ := ret[0].(repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PublishHeldPost indicates an expected call of PublishHeldPost.
+func (mr *MockPostStoreMockRecorder) PublishHeldPost(ctx, postId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishHeldPost", reflect.TypeOf((*MockPostStore)(nil).PublishHeldPost), ctx, postId)
+}
+
+// SetMentions mocks base method.
+func (m *MockPostStore) SetMentions(ctx context.Context, postId int, userIds []int) ([]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMentions", ctx, postId, userIds)
+	ret0, _ := ret[0].([]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetMentions indicates an expected call of SetMentions.
+func (mr *MockPostStoreMockRecorder) SetMentions(ctx, postId, userIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMentions", reflect.TypeOf((*MockPostStore)(nil).SetMentions), ctx, postId, userIds)
+}
+
+// UpdatePost mocks base method.
+func (m *MockPostStore) UpdatePost(ctx context.Context, post repository.Post) (repository.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePost", ctx, post)
+	ret0, _ := ret[0].(repository.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePost indicates an expected call of UpdatePost.
+func (mr *MockPostStoreMockRecorder) UpdatePost(ctx, post interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePost", reflect.TypeOf((*MockPostStore)(nil).UpdatePost), ctx, post)
+}
+
+// MockOrganizationStore is a mock of OrganizationStore interface.
+type MockOrganizationStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockOrganizationStoreMockRecorder
+}
+
+// MockOrganizationStoreMockRecorder is the mock recorder for MockOrganizationStore.
+type MockOrganizationStoreMockRecorder struct {
+	mock *MockOrganizationStore
+}
+
+// NewMockOrganizationStore creates a new mock instance.
+func NewMockOrganizationStore(ctrl *gomock.Controller) *MockOrganizationStore {
+	mock := &MockOrganizationStore{ctrl: ctrl}
+	mock.recorder = &MockOrganizationStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOrganizationStore) EXPECT() *MockOrganizationStoreMockRecorder {
+	return m.recorder
+}
+
+// DeleteMember mocks base method.
+func (m *MockOrganizationStore) DeleteMember(ctx context.Context, organizationId, userId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteMember", ctx, organizationId, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteMember indicates an expected call of DeleteMember.
+func (mr *MockOrganizationStoreMockRecorder) DeleteMember(ctx, organizationId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteMember", reflect.TypeOf((*MockOrganizationStore)(nil).DeleteMember), ctx, organizationId, userId)
+}
+
+// DeleteOrganization mocks base method.
+func (m *MockOrganizationStore) DeleteOrganization(ctx context.Context, organizationId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteOrganization", ctx, organizationId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteOrganization indicates an expected call of DeleteOrganization.
+func (mr *MockOrganizationStoreMockRecorder) DeleteOrganization(ctx, organizationId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOrganization", reflect.TypeOf((*MockOrganizationStore)(nil).DeleteOrganization), ctx, organizationId)
+}
+
+// FindMemberRole mocks base method.
+func (m *MockOrganizationStore) FindMemberRole(ctx context.Context, organizationId, userId int) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMemberRole", ctx, organizationId, userId)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMemberRole indicates an expected call of FindMemberRole.
+func (mr *MockOrganizationStoreMockRecorder) FindMemberRole(ctx, organizationId, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMemberRole", reflect.TypeOf((*MockOrganizationStore)(nil).FindMemberRole), ctx, organizationId, userId)
+}
+
+// FindMembers mocks base method.
+func (m *MockOrganizationStore) FindMembers(ctx context.Context, organizationId int) ([]repository.OrganizationMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindMembers", ctx, organizationId)
+	ret0, _ := ret[0].([]repository.OrganizationMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindMembers indicates an expected call of FindMembers.
+func (mr *MockOrganizationStoreMockRecorder) FindMembers(ctx, organizationId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindMembers", reflect.TypeOf((*MockOrganizationStore)(nil).FindMembers), ctx, organizationId)
+}
+
+// FindOrganizationByPublicID mocks base method.
+func (m *MockOrganizationStore) FindOrganizationByPublicID(ctx context.Context, publicId string) (repository.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrganizationByPublicID", ctx, publicId)
+	ret0, _ := ret[0].(repository.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrganizationByPublicID indicates an expected call of FindOrganizationByPublicID.
+func (mr *MockOrganizationStoreMockRecorder) FindOrganizationByPublicID(ctx, publicId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrganizationByPublicID", reflect.TypeOf((*MockOrganizationStore)(nil).FindOrganizationByPublicID), ctx, publicId)
+}
+
+// FindOrganizationsByUserID mocks base method.
+func (m *MockOrganizationStore) FindOrganizationsByUserID(ctx context.Context, userId int) ([]repository.UserOrganization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindOrganizationsByUserID", ctx, userId)
+	ret0, _ := ret[0].([]repository.UserOrganization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindOrganizationsByUserID indicates an expected call of FindOrganizationsByUserID.
+func (mr *MockOrganizationStoreMockRecorder) FindOrganizationsByUserID(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindOrganizationsByUserID", reflect.TypeOf((*MockOrganizationStore)(nil).FindOrganizationsByUserID), ctx, userId)
+}
+
+// InsertOrganization mocks base method.
+func (m *MockOrganizationStore) InsertOrganization(ctx context.Context, organization repository.Organization, creatorId int) (repository.Organization, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertOrganization", ctx, organization, creatorId)
+	ret0, _ := ret[0].(repository.Organization)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertOrganization indicates an expected call of InsertOrganization.
+func (mr *MockOrganizationStoreMockRecorder) InsertOrganization(ctx, organization, creatorId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertOrganization", reflect.TypeOf((*MockOrganizationStore)(nil).InsertOrganization), ctx, organization, creatorId)
+}
+
+// SetMember mocks base method.
+func (m *MockOrganizationStore) SetMember(ctx context.Context, organizationId, userId int, role string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMember", ctx, organizationId, userId, role)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMember indicates an expected call of SetMember.
+func (mr *MockOrganizationStoreMockRecorder) SetMember(ctx, organizationId, userId, role interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMember", reflect.TypeOf((*MockOrganizationStore)(nil).SetMember), ctx, organizationId, userId, role)
+}
+
+// MockNotificationStore is a mock of NotificationStore interface.
+type MockNotificationStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationStoreMockRecorder
+}
+
+// MockNotificationStoreMockRecorder is the mock recorder for MockNotificationStore.
+type MockNotificationStoreMockRecorder struct {
+	mock *MockNotificationStore
+}
+
+// NewMockNotificationStore creates a new mock instance.
+func NewMockNotificationStore(ctrl *gomock.Controller) *MockNotificationStore {
+	mock := &MockNotificationStore{ctrl: ctrl}
+	mock.recorder = &MockNotificationStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationStore) EXPECT() *MockNotificationStoreMockRecorder {
+	return m.recorder
+}
+
+// CountUnreadNotifications mocks base method.
+func (m *MockNotificationStore) CountUnreadNotifications(ctx context.Context, userId int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUnreadNotifications", ctx, userId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUnreadNotifications indicates an expected call of CountUnreadNotifications.
+func (mr *MockNotificationStoreMockRecorder) CountUnreadNotifications(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUnreadNotifications", reflect.TypeOf((*MockNotificationStore)(nil).CountUnreadNotifications), ctx, userId)
+}
+
+// FindNotificationPreferences mocks base method.
+func (m *MockNotificationStore) FindNotificationPreferences(ctx context.Context, userId int) (repository.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindNotificationPreferences", ctx, userId)
+	ret0, _ := ret[0].(repository.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindNotificationPreferences indicates an expected call of FindNotificationPreferences.
+func (mr *MockNotificationStoreMockRecorder) FindNotificationPreferences(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindNotificationPreferences", reflect.TypeOf((*MockNotificationStore)(nil).FindNotificationPreferences), ctx, userId)
+}
+
+// FindNotifications mocks base method.
+func (m *MockNotificationStore) FindNotifications(ctx context.Context, userId int, unreadOnly bool, page, limit int) ([]repository.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindNotifications", ctx, userId, unreadOnly, page, limit)
+	ret0, _ := ret[0].([]repository.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindNotifications indicates an expected call of FindNotifications.
+func (mr *MockNotificationStoreMockRecorder) FindNotifications(ctx, userId, unreadOnly, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindNotifications", reflect.TypeOf((*MockNotificationStore)(nil).FindNotifications), ctx, userId, unreadOnly, page, limit)
+}
+
+// InsertNotification mocks base method.
+func (m *MockNotificationStore) InsertNotification(ctx context.Context, notification repository.Notification) (repository.Notification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertNotification", ctx, notification)
+	ret0, _ := ret[0].(repository.Notification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertNotification indicates an expected call of InsertNotification.
+func (mr *MockNotificationStoreMockRecorder) InsertNotification(ctx, notification interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertNotification", reflect.TypeOf((*MockNotificationStore)(nil).InsertNotification), ctx, notification)
+}
+
+// MarkNotificationsRead mocks base method.
+func (m *MockNotificationStore) MarkNotificationsRead(ctx context.Context, userId int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationsRead", ctx, userId)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNotificationsRead indicates an expected call of MarkNotificationsRead.
+func (mr *MockNotificationStoreMockRecorder) MarkNotificationsRead(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationsRead", reflect.TypeOf((*MockNotificationStore)(nil).MarkNotificationsRead), ctx, userId)
+}
+
+// UpdateNotificationPreferences mocks base method.
+func (m *MockNotificationStore) UpdateNotificationPreferences(ctx context.Context, preferences repository.NotificationPreferences) (repository.NotificationPreferences, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateNotificationPreferences", ctx, preferences)
+	ret0, _ := ret[0].(repository.NotificationPreferences)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateNotificationPreferences indicates an expected call of UpdateNotificationPreferences.
+func (mr *MockNotificationStoreMockRecorder) UpdateNotificationPreferences(ctx, preferences interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateNotificationPreferences", reflect.TypeOf((*MockNotificationStore)(nil).UpdateNotificationPreferences), ctx, preferences)
+}
+
+// MockReportStore is a mock of ReportStore interface.
+type MockReportStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportStoreMockRecorder
+}
+
+// MockReportStoreMockRecorder is the mock recorder for MockReportStore.
+type MockReportStoreMockRecorder struct {
+	mock *MockReportStore
+}
+
+// NewMockReportStore creates a new mock instance.
+func NewMockReportStore(ctrl *gomock.Controller) *MockReportStore {
+	mock := &MockReportStore{ctrl: ctrl}
+	mock.recorder = &MockReportStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportStore) EXPECT() *MockReportStoreMockRecorder {
+	return m.recorder
+}
+
+// CountOpenReports mocks base method.
+func (m *MockReportStore) CountOpenReports(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountOpenReports", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountOpenReports indicates an expected call of CountOpenReports.
+func (mr *MockReportStoreMockRecorder) CountOpenReports(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountOpenReports", reflect.TypeOf((*MockReportStore)(nil).CountOpenReports), ctx)
+}
+
+// FindReports mocks base method.
+func (m *MockReportStore) FindReports(ctx context.Context, status string, page, limit int) ([]repository.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReports", ctx, status, page, limit)
+	ret0, _ := ret[0].([]repository.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindReports indicates an expected call of FindReports.
+func (mr *MockReportStoreMockRecorder) FindReports(ctx, status, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReports", reflect.TypeOf((*MockReportStore)(nil).FindReports), ctx, status, page, limit)
+}
+
+// InsertReport mocks base method.
+func (m *MockReportStore) InsertReport(ctx context.Context, report repository.Report) (repository.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertReport", ctx, report)
+	ret0, _ := ret[0].(repository.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertReport indicates an expected call of InsertReport.
+func (mr *MockReportStoreMockRecorder) InsertReport(ctx, report interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertReport", reflect.TypeOf((*MockReportStore)(nil).InsertReport), ctx, report)
+}
+
+// ReviewReport mocks base method.
+func (m *MockReportStore) ReviewReport(ctx context.Context, reportId int, status string, reviewerId int, note string) (repository.Report, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewReport", ctx, reportId, status, reviewerId, note)
+	ret0, _ := ret[0].(repository.Report)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewReport indicates an expected call of ReviewReport.
+func (mr *MockReportStoreMockRecorder) ReviewReport(ctx, reportId, status, reviewerId, note interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewReport", reflect.TypeOf((*MockReportStore)(nil).ReviewReport), ctx, reportId, status, reviewerId, note)
+}
+
+// MockAuditLogStore is a mock of AuditLogStore interface.
+type MockAuditLogStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLogStoreMockRecorder
+}
+
+// MockAuditLogStoreMockRecorder is the mock recorder for MockAuditLogStore.
+type MockAuditLogStoreMockRecorder struct {
+	mock *MockAuditLogStore
+}
+
+// NewMockAuditLogStore creates a new mock instance.
+func NewMockAuditLogStore(ctrl *gomock.Controller) *MockAuditLogStore {
+	mock := &MockAuditLogStore{ctrl: ctrl}
+	mock.recorder = &MockAuditLogStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLogStore) EXPECT() *MockAuditLogStoreMockRecorder {
+	return m.recorder
+}
+
+// FindEntries mocks base method.
+func (m *MockAuditLogStore) FindEntries(ctx context.Context, filter repository.AuditLogFilter, page, limit int) ([]repository.AuditLogEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindEntries", ctx, filter, page, limit)
+	ret0, _ := ret[0].([]repository.AuditLogEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindEntries indicates an expected call of FindEntries.
+func (mr *MockAuditLogStoreMockRecorder) FindEntries(ctx, filter, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindEntries", reflect.TypeOf((*MockAuditLogStore)(nil).FindEntries), ctx, filter, page, limit)
+}
+
+// InsertEntry mocks base method.
+func (m *MockAuditLogStore) InsertEntry(ctx context.Context, entry repository.AuditLogEntry) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertEntry", ctx, entry)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertEntry indicates an expected call of InsertEntry.
+func (mr *MockAuditLogStoreMockRecorder) InsertEntry(ctx, entry interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertEntry", reflect.TypeOf((*MockAuditLogStore)(nil).InsertEntry), ctx, entry)
+}
+
+// MockJobStore is a mock of JobStore interface.
+type MockJobStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockJobStoreMockRecorder
+}
+
+// MockJobStoreMockRecorder is the mock recorder for MockJobStore.
+type MockJobStoreMockRecorder struct {
+	mock *MockJobStore
+}
+
+// NewMockJobStore creates a new mock instance.
+func NewMockJobStore(ctrl *gomock.Controller) *MockJobStore {
+	mock := &MockJobStore{ctrl: ctrl}
+	mock.recorder = &MockJobStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockJobStore) EXPECT() *MockJobStoreMockRecorder {
+	return m.recorder
+}
+
+// CountEmailsSince mocks base method.
+func (m *MockJobStore) CountEmailsSince(ctx context.Context, jobType, recipient, template string, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountEmailsSince", ctx, jobType, recipient, template, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountEmailsSince indicates an expected call of CountEmailsSince.
+func (mr *MockJobStoreMockRecorder) CountEmailsSince(ctx, jobType, recipient, template, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountEmailsSince", reflect.TypeOf((*MockJobStore)(nil).CountEmailsSince), ctx, jobType, recipient, template, since)
+}
+
+// CountJobsByStatus mocks base method.
+func (m *MockJobStore) CountJobsByStatus(ctx context.Context) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountJobsByStatus", ctx)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountJobsByStatus indicates an expected call of CountJobsByStatus.
+func (mr *MockJobStoreMockRecorder) CountJobsByStatus(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountJobsByStatus", reflect.TypeOf((*MockJobStore)(nil).CountJobsByStatus), ctx)
+}
+
+// CountJobsSince mocks base method.
+func (m *MockJobStore) CountJobsSince(ctx context.Context, jobType, status string, since time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountJobsSince", ctx, jobType, status, since)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountJobsSince indicates an expected call of CountJobsSince.
+func (mr *MockJobStoreMockRecorder) CountJobsSince(ctx, jobType, status, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountJobsSince", reflect.TypeOf((*MockJobStore)(nil).CountJobsSince), ctx, jobType, status, since)
+}
+
+// FindJobs mocks base method.
+func (m *MockJobStore) FindJobs(ctx context.Context, status string, page, limit int) ([]repository.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindJobs", ctx, status, page, limit)
+	ret0, _ := ret[0].([]repository.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindJobs indicates an expected call of FindJobs.
+func (mr *MockJobStoreMockRecorder) FindJobs(ctx, status, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindJobs", reflect.TypeOf((*MockJobStore)(nil).FindJobs), ctx, status, page, limit)
+}
+
+// ResurrectJob mocks base method.
+func (m *MockJobStore) ResurrectJob(ctx context.Context, jobId int) (repository.Job, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResurrectJob", ctx, jobId)
+	ret0, _ := ret[0].(repository.Job)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResurrectJob indicates an expected call of ResurrectJob.
+func (mr *MockJobStoreMockRecorder) ResurrectJob(ctx, jobId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResurrectJob", reflect.TypeOf((*MockJobStore)(nil).ResurrectJob), ctx, jobId)
+}
+
+// MockWebhookStore is a mock of WebhookStore interface.
+type MockWebhookStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookStoreMockRecorder
+}
+
+// MockWebhookStoreMockRecorder is the mock recorder for MockWebhookStore.
+type MockWebhookStoreMockRecorder struct {
+	mock *MockWebhookStore
+}
+
+// NewMockWebhookStore creates a new mock instance.
+func NewMockWebhookStore(ctrl *gomock.Controller) *MockWebhookStore {
+	mock := &MockWebhookStore{ctrl: ctrl}
+	mock.recorder = &MockWebhookStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookStore) EXPECT() *MockWebhookStoreMockRecorder {
+	return m.recorder
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookStore) DeleteWebhook(ctx context.Context, webhookId int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, webhookId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookStoreMockRecorder) DeleteWebhook(ctx, webhookId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookStore)(nil).DeleteWebhook), ctx, webhookId)
+}
+
+// FindDeliveries mocks base method.
+func (m *MockWebhookStore) FindDeliveries(ctx context.Context, webhookId int, status string, page, limit int) ([]repository.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeliveries", ctx, webhookId, status, page, limit)
+	ret0, _ := ret[0].([]repository.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeliveries indicates an expected call of FindDeliveries.
+func (mr *MockWebhookStoreMockRecorder) FindDeliveries(ctx, webhookId, status, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeliveries", reflect.TypeOf((*MockWebhookStore)(nil).FindDeliveries), ctx, webhookId, status, page, limit)
+}
+
+// FindDeliveryByID mocks base method.
+func (m *MockWebhookStore) FindDeliveryByID(ctx context.Context, deliveryId int) (repository.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeliveryByID", ctx, deliveryId)
+	ret0, _ := ret[0].(repository.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeliveryByID indicates an expected call of FindDeliveryByID.
+func (mr *MockWebhookStoreMockRecorder) FindDeliveryByID(ctx, deliveryId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeliveryByID", reflect.TypeOf((*MockWebhookStore)(nil).FindDeliveryByID), ctx, deliveryId)
+}
+
+// FindWebhookByID mocks base method.
+func (m *MockWebhookStore) FindWebhookByID(ctx context.Context, webhookId int) (repository.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhookByID", ctx, webhookId)
+	ret0, _ := ret[0].(repository.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhookByID indicates an expected call of FindWebhookByID.
+func (mr *MockWebhookStoreMockRecorder) FindWebhookByID(ctx, webhookId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhookByID", reflect.TypeOf((*MockWebhookStore)(nil).FindWebhookByID), ctx, webhookId)
+}
+
+// FindWebhooks mocks base method.
+func (m *MockWebhookStore) FindWebhooks(ctx context.Context) ([]repository.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhooks", ctx)
+	ret0, _ := ret[0].([]repository.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhooks indicates an expected call of FindWebhooks.
+func (mr *MockWebhookStoreMockRecorder) FindWebhooks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhooks", reflect.TypeOf((*MockWebhookStore)(nil).FindWebhooks), ctx)
+}
+
+// FindWebhooksForEvent mocks base method.
+func (m *MockWebhookStore) FindWebhooksForEvent(ctx context.Context, event string) ([]repository.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindWebhooksForEvent", ctx, event)
+	ret0, _ := ret[0].([]repository.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindWebhooksForEvent indicates an expected call of FindWebhooksForEvent.
+func (mr *MockWebhookStoreMockRecorder) FindWebhooksForEvent(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindWebhooksForEvent", reflect.TypeOf((*MockWebhookStore)(nil).FindWebhooksForEvent), ctx, event)
+}
+
+// InsertDelivery mocks base method.
+func (m *MockWebhookStore) InsertDelivery(ctx context.Context, delivery repository.WebhookDelivery) (repository.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertDelivery", ctx, delivery)
+	ret0, _ := ret[0].(repository.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertDelivery indicates an expected call of InsertDelivery.
+func (mr *MockWebhookStoreMockRecorder) InsertDelivery(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertDelivery", reflect.TypeOf((*MockWebhookStore)(nil).InsertDelivery), ctx, delivery)
+}
+
+// InsertWebhook mocks base method.
+func (m *MockWebhookStore) InsertWebhook(ctx context.Context, webhook repository.Webhook) (repository.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertWebhook", ctx, webhook)
+	ret0, _ := ret[0].(repository.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InsertWebhook indicates an expected call of InsertWebhook.
+func (mr *MockWebhookStoreMockRecorder) InsertWebhook(ctx, webhook interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertWebhook", reflect.TypeOf((*MockWebhookStore)(nil).InsertWebhook), ctx, webhook)
+}
+
+// RecordDeliveryAttempt mocks base method.
+func (m *MockWebhookStore) RecordDeliveryAttempt(ctx context.Context, deliveryId int, status string, responseStatus *int, lastError string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDeliveryAttempt", ctx, deliveryId, status, responseStatus, lastError)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDeliveryAttempt indicates an expected call of RecordDeliveryAttempt.
+func (mr *MockWebhookStoreMockRecorder) RecordDeliveryAttempt(ctx, deliveryId, status, responseStatus, lastError interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDeliveryAttempt", reflect.TypeOf((*MockWebhookStore)(nil).RecordDeliveryAttempt), ctx, deliveryId, status, responseStatus, lastError)
+}
+
+// MockSettingsStore is a mock of SettingsStore interface.
+type MockSettingsStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockSettingsStoreMockRecorder
+}
+
+// MockSettingsStoreMockRecorder is the mock recorder for MockSettingsStore.
+type MockSettingsStoreMockRecorder struct {
+	mock *MockSettingsStore
+}
+
+// NewMockSettingsStore creates a new mock instance.
+func NewMockSettingsStore(ctrl *gomock.Controller) *MockSettingsStore {
+	mock := &MockSettingsStore{ctrl: ctrl}
+	mock.recorder = &MockSettingsStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSettingsStore) EXPECT() *MockSettingsStoreMockRecorder {
+	return m.recorder
+}
+
+// GetAppearance mocks base method.
+func (m *MockSettingsStore) GetAppearance(ctx context.Context) (repository.Appearance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAppearance", ctx)
+	ret0, _ := ret[0].(repository.Appearance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAppearance indicates an expected call of GetAppearance.
+func (mr *MockSettingsStoreMockRecorder) GetAppearance(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAppearance", reflect.TypeOf((*MockSettingsStore)(nil).GetAppearance), ctx)
+}
+
+// GetMaintenance mocks base method.
+func (m *MockSettingsStore) GetMaintenance(ctx context.Context) (repository.Maintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaintenance", ctx)
+	ret0, _ := ret[0].(repository.Maintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaintenance indicates an expected call of GetMaintenance.
+func (mr *MockSettingsStoreMockRecorder) GetMaintenance(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaintenance", reflect.TypeOf((*MockSettingsStore)(nil).GetMaintenance), ctx)
+}
+
+// UpdateAppearance mocks base method.
+func (m *MockSettingsStore) UpdateAppearance(ctx context.Context, appearance repository.Appearance) (repository.Appearance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateAppearance", ctx, appearance)
+	ret0, _ := ret[0].(repository.Appearance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateAppearance indicates an expected call of UpdateAppearance.
+func (mr *MockSettingsStoreMockRecorder) UpdateAppearance(ctx, appearance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateAppearance", reflect.TypeOf((*MockSettingsStore)(nil).UpdateAppearance), ctx, appearance)
+}
+
+// UpdateMaintenance mocks base method.
+func (m *MockSettingsStore) UpdateMaintenance(ctx context.Context, maintenance repository.Maintenance) (repository.Maintenance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateMaintenance", ctx, maintenance)
+	ret0, _ := ret[0].(repository.Maintenance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateMaintenance indicates an expected call of UpdateMaintenance.
+func (mr *MockSettingsStoreMockRecorder) UpdateMaintenance(ctx, maintenance interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMaintenance", reflect.TypeOf((*MockSettingsStore)(nil).UpdateMaintenance), ctx, maintenance)
+}
+
+// MockHistoryStore is a mock of HistoryStore interface.
+type MockHistoryStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockHistoryStoreMockRecorder
+}
+
+// MockHistoryStoreMockRecorder is the mock recorder for MockHistoryStore.
+type MockHistoryStoreMockRecorder struct {
+	mock *MockHistoryStore
+}
+
+// NewMockHistoryStore creates a new mock instance.
+func NewMockHistoryStore(ctrl *gomock.Controller) *MockHistoryStore {
+	mock := &MockHistoryStore{ctrl: ctrl}
+	mock.recorder = &MockHistoryStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHistoryStore) EXPECT() *MockHistoryStoreMockRecorder {
+	return m.recorder
+}
+
+// FindChangeAsOf mocks base method.
+func (m *MockHistoryStore) FindChangeAsOf(ctx context.Context, recordType string, recordId int, at time.Time) (repository.RecordChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChangeAsOf", ctx, recordType, recordId, at)
+	ret0, _ := ret[0].(repository.RecordChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChangeAsOf indicates an expected call of FindChangeAsOf.
+func (mr *MockHistoryStoreMockRecorder) FindChangeAsOf(ctx, recordType, recordId, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChangeAsOf", reflect.TypeOf((*MockHistoryStore)(nil).FindChangeAsOf), ctx, recordType, recordId, at)
+}
+
+// FindChanges mocks base method.
+func (m *MockHistoryStore) FindChanges(ctx context.Context, recordType string, recordId, page, limit int) ([]repository.RecordChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindChanges", ctx, recordType, recordId, page, limit)
+	ret0, _ := ret[0].([]repository.RecordChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindChanges indicates an expected call of FindChanges.
+func (mr *MockHistoryStoreMockRecorder) FindChanges(ctx, recordType, recordId, page, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindChanges", reflect.TypeOf((*MockHistoryStore)(nil).FindChanges), ctx, recordType, recordId, page, limit)
+}
+
+// InsertChange mocks base method.
+func (m *MockHistoryStore) InsertChange(ctx context.Context, change repository.RecordChange) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertChange", ctx, change)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertChange indicates an expected call of InsertChange.
+func (mr *MockHistoryStoreMockRecorder) InsertChange(ctx, change interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertChange", reflect.TypeOf((*MockHistoryStore)(nil).InsertChange), ctx, change)
+}
+
+// MockStatsStore is a mock of StatsStore interface.
+type MockStatsStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsStoreMockRecorder
+}
+
+// MockStatsStoreMockRecorder is the mock recorder for MockStatsStore.
+type MockStatsStoreMockRecorder struct {
+	mock *MockStatsStore
+}
+
+// NewMockStatsStore creates a new mock instance.
+func NewMockStatsStore(ctrl *gomock.Controller) *MockStatsStore {
+	mock := &MockStatsStore{ctrl: ctrl}
+	mock.recorder = &MockStatsStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsStore) EXPECT() *MockStatsStoreMockRecorder {
+	return m.recorder
+}
+
+// DeleteLoginAttemptsBefore mocks base method.
+func (m *MockStatsStore) DeleteLoginAttemptsBefore(ctx context.Context, before time.Time) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteLoginAttemptsBefore", ctx, before)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteLoginAttemptsBefore indicates an expected call of DeleteLoginAttemptsBefore.
+func (mr *MockStatsStoreMockRecorder) DeleteLoginAttemptsBefore(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteLoginAttemptsBefore", reflect.TypeOf((*MockStatsStore)(nil).DeleteLoginAttemptsBefore), ctx, before)
+}
+
+// FindLoginDevice mocks base method.
+func (m *MockStatsStore) FindLoginDevice(ctx context.Context, userId int, userAgent string) (bool, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindLoginDevice", ctx, userId, userAgent)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FindLoginDevice indicates an expected call of FindLoginDevice.
+func (mr *MockStatsStoreMockRecorder) FindLoginDevice(ctx, userId, userAgent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindLoginDevice", reflect.TypeOf((*MockStatsStore)(nil).FindLoginDevice), ctx, userId, userAgent)
+}
+
+// FindPlatformStats mocks base method.
+func (m *MockStatsStore) FindPlatformStats(ctx context.Context, since time.Time, interval string) (repository.PlatformStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindPlatformStats", ctx, since, interval)
+	ret0, _ := ret[0].(repository.PlatformStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindPlatformStats indicates an expected call of FindPlatformStats.
+func (mr *MockStatsStoreMockRecorder) FindPlatformStats(ctx, since, interval interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindPlatformStats", reflect.TypeOf((*MockStatsStore)(nil).FindPlatformStats), ctx, since, interval)
+}
+
+// InsertLoginAttempt mocks base method.
+func (m *MockStatsStore) InsertLoginAttempt(ctx context.Context, userId *int, succeeded bool, userAgent string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InsertLoginAttempt", ctx, userId, succeeded, userAgent)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InsertLoginAttempt indicates an expected call of InsertLoginAttempt.
+func (mr *MockStatsStoreMockRecorder) InsertLoginAttempt(ctx, userId, succeeded, userAgent interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InsertLoginAttempt", reflect.TypeOf((*MockStatsStore)(nil).InsertLoginAttempt), ctx, userId, succeeded, userAgent)
+}
+
+// MockTransactor is a mock of Transactor interface.
+type MockTransactor struct {
+	ctrl     *gomock.Controller
+	recorder *MockTransactorMockRecorder
+}
+
+// MockTransactorMockRecorder is the mock recorder for MockTransactor.
+type MockTransactorMockRecorder struct {
+	mock *MockTransactor
+}
+
+// NewMockTransactor creates a new mock instance.
+func NewMockTransactor(ctrl *gomock.Controller) *MockTransactor {
+	mock := &MockTransactor{ctrl: ctrl}
+	mock.recorder = &MockTransactorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTransactor) EXPECT() *MockTransactorMockRecorder {
+	return m.recorder
+}
+
+// WithinTx mocks base method.
+func (m *MockTransactor) WithinTx(ctx context.Context, fn func(context.Context) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithinTx", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithinTx indicates an expected call of WithinTx.
+func (mr *MockTransactorMockRecorder) WithinTx(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithinTx", reflect.TypeOf((*MockTransactor)(nil).WithinTx), ctx, fn)
+}
+
+// MockNotificationSubscriber is a mock of NotificationSubscriber interface.
+type MockNotificationSubscriber struct {
+	ctrl     *gomock.Controller
+	recorder *MockNotificationSubscriberMockRecorder
+}
+
+// MockNotificationSubscriberMockRecorder is the mock recorder for MockNotificationSubscriber.
+type MockNotificationSubscriberMockRecorder struct {
+	mock *MockNotificationSubscriber
+}
+
+// NewMockNotificationSubscriber creates a new mock instance.
+func NewMockNotificationSubscriber(ctrl *gomock.Controller) *MockNotificationSubscriber {
+	mock := &MockNotificationSubscriber{ctrl: ctrl}
+	mock.recorder = &MockNotificationSubscriberMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockNotificationSubscriber) EXPECT() *MockNotificationSubscriberMockRecorder {
+	return m.recorder
+}
+
+// Subscribe mocks base method.
+func (m *MockNotificationSubscriber) Subscribe(channel string, handler func(string)) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", channel, handler)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockNotificationSubscriberMockRecorder) Subscribe(channel, handler interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockNotificationSubscriber)(nil).Subscribe), channel, handler)
+}
@@ -0,0 +1,375 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/mailer"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/alexedwards/argon2id"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	bulkActionBan           = "ban"
+	bulkActionChangeRole    = "change_role"
+	bulkActionResetPassword = "reset_password"
+
+	maxBulkUsers = 100
+
+	minModerationReasonLength = 3
+	maxModerationReasonLength = 500
+
+	bulkResultOk     = "ok"
+	bulkResultFailed = "failed"
+)
+
+type bulkModerationRequest struct {
+	Action  string `json:"action" enums:"ban,change_role,reset_password" validate:"required,oneof=ban change_role reset_password"`
+	// UserIDs are the public ids of the users.
+	UserIDs []string `json:"user_ids"`
+	// Role is the role users are moved to, it's only used by change_role.
+	Role   string `json:"role"`
+	Reason string `json:"reason" validate:"min=minModerationReasonLength,max=maxModerationReasonLength"`
+}
+
+type bulkModerationResult struct {
+	UserID string `json:"user_id"`
+	Status string `json:"status" enums:"ok,failed"`
+	Error  string `json:"error,omitempty"`
+}
+
+type bulkModerationResponse struct {
+	Succeeded int                    `json:"succeeded"`
+	Failed    int                    `json:"failed"`
+	Results   []bulkModerationResult `json:"results"`
+}
+
+// @Summary Bans users, changes their role or forces them to reset their password.
+// @Description Every user is moderated on their own, so the response reports a result per user instead of failing
+// @Description the whole request. The reason is recorded in the audit log entry of every moderated user.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body bulkModerationRequest true "Bulk moderation body"
+// @Security ApiKeyAuth
+// @Success 200 {object} bulkModerationResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/users/bulk [post]
+func (s *Server) bulkModerateUsersHandler(c *gin.Context) {
+	var request bulkModerationRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	request.Reason = strings.TrimSpace(request.Reason)
+
+	ok, errors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input invalid", zap.Any("err", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	if len(request.UserIDs) == 0 || len(request.UserIDs) > maxBulkUsers {
+		c.Error(ErrInvalidInput{fmt.Sprintf("user_ids must contain between 1 and %d ids", maxBulkUsers)})
+		return
+	}
+
+	for i, userId := range request.UserIDs {
+		request.UserIDs[i] = strings.ToLower(userId)
+		if !publicIDPattern.MatchString(request.UserIDs[i]) {
+			c.Error(ErrInvalidInput{fmt.Sprintf("user_ids must only contain valid ids, %q isn't one", userId)})
+			return
+		}
+	}
+
+	if request.Action == bulkActionChangeRole {
+		exists, err := s.UserRepository.RoleExists(c.Request.Context(), request.Role)
+		if err != nil {
+			s.logger(c).Debug("couldn't check if role exists", zap.Error(err), zap.String("role", request.Role))
+			c.Error(err)
+			return
+		}
+
+		if !exists {
+			c.Error(ErrInvalidInput{"role doesn't exist"})
+			return
+		}
+	}
+
+	response := bulkModerationResponse{Results: []bulkModerationResult{}}
+	seen := map[string]bool{}
+	for _, userId := range request.UserIDs {
+		if seen[userId] {
+			continue
+		}
+		seen[userId] = true
+
+		result := bulkModerationResult{UserID: userId, Status: bulkResultOk}
+
+		err := s.moderateUser(c, request, userId)
+		if err != nil {
+			s.logger(c).Debug("couldn't moderate user", zap.Error(err), zap.String("userId", userId), zap.String("action", request.Action))
+			result.Status = bulkResultFailed
+			result.Error = err.Error()
+			response.Failed++
+		} else {
+			response.Succeeded++
+		}
+
+		response.Results = append(response.Results, result)
+	}
+
+	s.logger(c).Info("users moderated", zap.String("action", request.Action), zap.Int("succeeded", response.Succeeded), zap.Int("failed", response.Failed))
+
+	c.JSON(http.StatusOK, response)
+}
+
+var errModerateSelf = errors.New("you can't moderate yourself")
+
+// moderateUser applies the action to a single user, the returned error is shown to the moderator so it mustn't leak internals.
+func (s *Server) moderateUser(c *gin.Context, request bulkModerationRequest, publicId string) error {
+	ctx := c.Request.Context()
+
+	user, err := s.UserRepository.FindUserByPublicID(ctx, publicId)
+	if errors.Is(err, repository.ErrUserNotFound) {
+		return err
+	}
+	if err != nil {
+		s.logger(c).Error("couldn't find user", zap.Error(err), zap.String("userId", publicId))
+		return errors.New("couldn't find user")
+	}
+
+	actor := s.getUserFromContext(c)
+	if actor.ID == user.ID {
+		return errModerateSelf
+	}
+
+	switch request.Action {
+	case bulkActionBan:
+		err = s.UserRepository.SetActiveState(ctx, user.ID, false)
+		if err != nil {
+			s.logger(c).Error("couldn't deactivate user", zap.Error(err), zap.Int("userId", user.ID))
+			return errors.New("couldn't ban user")
+		}
+
+		s.auditWithReason(c, request.Reason, auditActionUserBan, objectUser, user.ID, gin.H{"active": user.Active}, gin.H{"active": false})
+
+		// the request was made by the moderator, so it's left out of the alert
+		s.sendSecurityAlert(c, user, mailer.SecurityAlert{Username: user.Username, Event: mailer.SecurityEventAccountDeactivated, Time: time.Now().UTC()})
+
+	case bulkActionChangeRole:
+		err = s.UserRepository.SetRole(ctx, user.ID, request.Role)
+		if err != nil {
+			s.logger(c).Error("couldn't change role", zap.Error(err), zap.Int("userId", user.ID))
+			return errors.New("couldn't change role")
+		}
+
+		s.auditWithReason(c, request.Reason, auditActionUserRoleChange, objectUser, user.ID, gin.H{"role": user.Role}, gin.H{"role": request.Role})
+
+	case bulkActionResetPassword:
+		err = s.forcePasswordReset(ctx, user)
+		if err != nil {
+			s.logger(c).Error("couldn't force password reset", zap.Error(err), zap.Int("userId", user.ID))
+			return errors.New("couldn't reset password")
+		}
+
+		s.auditWithReason(c, request.Reason, auditActionUserPasswordReset, objectUser, user.ID, nil, nil)
+	}
+
+	s.recordUserHistory(&actor.ID, user.ID)
+
+	return nil
+}
+
+// forcePasswordReset replaces the user's password with a random one nobody knows, so they can only log in again
+// after following the password reset email. The password is kept if the email couldn't be queued.
+func (s *Server) forcePasswordReset(ctx context.Context, user repository.User) error {
+	password, err := generateSecureToken(32)
+	if err != nil {
+		return err
+	}
+
+	hash, err := argon2id.CreateHash(password, s.argon2Params())
+	if err != nil {
+		return err
+	}
+
+	return s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.UserRepository.SetPassword(ctx, user.ID, hash)
+		if err != nil {
+			return err
+		}
+
+		return s.sendPasswordResetEmail(ctx, user)
+	})
+}
+
+type setVerifiedBadgeRequest struct {
+	Verified *bool `json:"verified" validate:"required"`
+}
+
+// @Summary Gives a user the verified badge or takes it away, the badge is shown next to the user's name on their posts.
+// @Description This is unrelated to email verification, see PUT /admin/users/{userId}/verify for that.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param userId path string true "user id"
+// @Param request body setVerifiedBadgeRequest true "Verified badge body"
+// @Security ApiKeyAuth
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "A user with the provided id doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /admin/users/{userId}/badge [put]
+func (s *Server) setVerifiedBadgeHandler(c *gin.Context) {
+	publicId, err := s.publicIDParam(c, "userId")
+	if err != nil {
+		s.logger(c).Debug("userId param is invalid", zap.Error(err), zap.String("userId", c.Param("userId")))
+		s.badRequestResponse(c, err.Error())
+		return
+	}
+
+	var request setVerifiedBadgeRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	ok, errors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input invalid", zap.Any("err", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	user, err := s.UserRepository.FindUserByPublicID(c.Request.Context(), publicId)
+	if err != nil {
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("userId", publicId))
+		c.Error(err)
+		return
+	}
+
+	err = s.UserRepository.SetVerified(c.Request.Context(), user.ID, *request.Verified)
+	if err != nil {
+		s.logger(c).Debug("couldn't set verified badge", zap.Error(err), zap.Int("userId", user.ID))
+		c.Error(err)
+		return
+	}
+
+	actor := s.getUserFromContext(c)
+	s.recordUserHistory(&actor.ID, user.ID)
+	s.audit(c, auditActionUserBadge, objectUser, user.ID, gin.H{"verified": user.Verified}, gin.H{"verified": *request.Verified})
+
+	if *request.Verified {
+		s.successResponse(c, "user has been verified")
+		return
+	}
+
+	s.successResponse(c, "user is no longer verified")
+}
+
+// isPostHidden reports whether the post is hidden from the viewer, which is the case for posts held for moderation
+// and posts of shadowbanned users, unless the viewer is the author. A viewerId of 0 is an anonymous viewer.
+func (s *Server) isPostHidden(ctx context.Context, post repository.Post, viewerId int) (bool, error) {
+	if post.UserID == viewerId {
+		return false, nil
+	}
+
+	if post.Status == repository.PostStatusHeld {
+		return true, nil
+	}
+
+	author, err := s.UserRepository.FindUserByID(ctx, post.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	return author.Shadowbanned, nil
+}
+
+type shadowbanUserRequest struct {
+	Shadowbanned *bool  `json:"shadowbanned" validate:"required"`
+	Reason       string `json:"reason" validate:"min=minModerationReasonLength,max=maxModerationReasonLength"`
+}
+
+// @Summary Shadowbans a user or lifts the shadowban.
+// @Description A shadowbanned user keeps seeing their own posts, while everyone else gets a 404 for them and doesn't
+// @Description find them in any list or widget. Their mentions don't notify anyone. The reason is recorded in the audit log entry.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param userId path string true "user id"
+// @Param request body shadowbanUserRequest true "Shadowban body"
+// @Security ApiKeyAuth
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "A user with the provided id doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /admin/users/{userId}/shadowban [put]
+func (s *Server) shadowbanUserHandler(c *gin.Context) {
+	publicId, err := s.publicIDParam(c, "userId")
+	if err != nil {
+		s.logger(c).Debug("userId param is invalid", zap.Error(err), zap.String("userId", c.Param("userId")))
+		s.badRequestResponse(c, err.Error())
+		return
+	}
+
+	var request shadowbanUserRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	request.Reason = strings.TrimSpace(request.Reason)
+
+	ok, errors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input invalid", zap.Any("err", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	user, err := s.UserRepository.FindUserByPublicID(c.Request.Context(), publicId)
+	if err != nil {
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("userId", publicId))
+		c.Error(err)
+		return
+	}
+
+	actor := s.getUserFromContext(c)
+	if user.ID == actor.ID {
+		c.Error(ErrInvalidInput{"you can't shadowban yourself"})
+		return
+	}
+
+	err = s.UserRepository.SetShadowbanned(c.Request.Context(), user.ID, *request.Shadowbanned)
+	if err != nil {
+		s.logger(c).Debug("couldn't set shadowban", zap.Error(err), zap.Int("userId", user.ID))
+		c.Error(err)
+		return
+	}
+
+	s.recordUserHistory(&actor.ID, user.ID)
+	s.auditWithReason(c, request.Reason, auditActionUserShadowban, objectUser, user.ID, gin.H{"shadowbanned": user.Shadowbanned}, gin.H{"shadowbanned": *request.Shadowbanned})
+
+	if *request.Shadowbanned {
+		s.successResponse(c, "user has been shadowbanned")
+		return
+	}
+
+	s.successResponse(c, "user is no longer shadowbanned")
+}
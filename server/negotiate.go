@@ -0,0 +1,92 @@
+package server
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/gin-gonic/gin/render"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var offeredFormats = []string{
+	binding.MIMEJSON,
+	binding.MIMEMSGPACK,
+	binding.MIMEMSGPACK2,
+	binding.MIMEPROTOBUF,
+}
+
+// protoMarshaler is implemented by responses which have a schema in proto/.
+type protoMarshaler interface {
+	MarshalProto() []byte
+}
+
+// respond writes obj in the format negotiated through the Accept header, JSON is used
+// when the client doesn't ask for anything else or the response has no protobuf schema.
+func (s *Server) respond(c *gin.Context, code int, obj any) {
+	switch c.NegotiateFormat(offeredFormats...) {
+	case binding.MIMEMSGPACK, binding.MIMEMSGPACK2:
+		c.Render(code, render.MsgPack{Data: obj})
+	case binding.MIMEPROTOBUF:
+		message, ok := marshalProto(obj)
+		if !ok {
+			c.JSON(code, obj)
+			return
+		}
+
+		c.Data(code, binding.MIMEPROTOBUF, message)
+	default:
+		c.JSON(code, obj)
+	}
+}
+
+// marshalProto encodes responses which have a schema in proto/, lists of posts are encoded as a blogapi.v1.PostList
+// message.
+func marshalProto(obj any) ([]byte, bool) {
+	switch response := obj.(type) {
+	case protoMarshaler:
+		return response.MarshalProto(), true
+	case listResponse[personalPosts]:
+		var b []byte
+		for _, post := range response.Data {
+			b = protowire.AppendTag(b, 1, protowire.BytesType)
+			b = protowire.AppendBytes(b, post.MarshalProto())
+		}
+
+		return b, true
+	default:
+		return nil, false
+	}
+}
+
+// appendPostProto encodes a blogapi.v1.Post message.
+func appendPostProto(b []byte, id, title, body, status, canonicalURL, license string) []byte {
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendString(b, title)
+	b = protowire.AppendTag(b, 3, protowire.BytesType)
+	b = protowire.AppendString(b, body)
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendString(b, id)
+	b = protowire.AppendTag(b, 5, protowire.BytesType)
+	b = protowire.AppendString(b, status)
+	b = protowire.AppendTag(b, 6, protowire.BytesType)
+	b = protowire.AppendString(b, canonicalURL)
+	b = protowire.AppendTag(b, 7, protowire.BytesType)
+	b = protowire.AppendString(b, license)
+	return b
+}
+
+func (r createPostResponse) MarshalProto() []byte {
+	return appendPostProto(nil, r.ID, r.Title, r.Body, r.Status, r.CanonicalURL, r.License)
+}
+
+func (r getPostResponse) MarshalProto() []byte {
+	return appendPostProto(nil, r.ID, r.Title, r.Body, r.Status, r.CanonicalURL, r.License)
+}
+
+func (r updatePostResponse) MarshalProto() []byte {
+	return appendPostProto(nil, r.ID, r.Title, r.Body, r.Status, r.CanonicalURL, r.License)
+}
+
+// MarshalProto encodes the post as a blogapi.v1.Post message.
+func (p personalPosts) MarshalProto() []byte {
+	return appendPostProto(nil, p.ID, p.Title, p.Body, p.Status, p.CanonicalURL, p.License)
+}
@@ -0,0 +1,210 @@
+package server
+
+import (
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type notificationResponse struct {
+	ID   int    `json:"id"`
+	Type string `json:"type" enums:"mention"`
+	// Actor is the username of the user who caused the notification, it's null once they have been deleted.
+	Actor     *string    `json:"actor"`
+	PostID    *string    `json:"post_id,omitempty"`
+	PostTitle *string    `json:"post_title,omitempty"`
+	ReadAt    *time.Time `json:"read_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// @Summary Returns the user's notifications, newest first, along with the amount of unread ones.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param page query int32 true "page"
+// @Param limit query int32 true "limit"
+// @Param unread query bool false "only return unread notifications"
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[notificationResponse]
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /users/me/notifications [get]
+func (s *Server) getNotificationsHandler(c *gin.Context) {
+	page, limit, err := s.validatePageAndLimit(c)
+	if err != nil {
+		s.logger(c).Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
+		return
+	}
+
+	unreadOnly := false
+	if c.Query("unread") != "" {
+		unreadOnly, err = strconv.ParseBool(c.Query("unread"))
+		if err != nil {
+			c.Error(ErrInvalidInput{"unread must be true or false"})
+			return
+		}
+	}
+
+	user := s.getUserFromContext(c)
+
+	unread, err := s.NotificationRepository.CountUnreadNotifications(c.Request.Context(), user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't count unread notifications", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	notifications, err := s.NotificationRepository.FindNotifications(c.Request.Context(), user.ID, unreadOnly, page, limit)
+	if err != nil {
+		s.logger(c).Debug("couldn't find notifications", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	data := []notificationResponse{}
+	for _, notification := range notifications {
+		data = append(data, newNotificationResponse(notification))
+	}
+
+	response := newListResponse(s, c, data, page, limit)
+	response.Meta.Counts = map[string]int{"unread": unread}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func newNotificationResponse(notification repository.Notification) notificationResponse {
+	return notificationResponse{
+		ID:        notification.ID,
+		Type:      notification.Type,
+		Actor:     notification.ActorUsername,
+		PostID:    notification.PostPublicID,
+		PostTitle: notification.PostTitle,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+// @Summary Marks all of the user's notifications as read.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 "Notifications marked as read"
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /users/me/notifications/read [post]
+func (s *Server) markNotificationsReadHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	marked, err := s.NotificationRepository.MarkNotificationsRead(c.Request.Context(), user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't mark notifications as read", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	s.logger(c).Debug("marked notifications as read", zap.Int("count", marked))
+
+	c.Status(http.StatusOK)
+}
+
+type preferencesResponse struct {
+	MentionInApp bool `json:"mention_in_app"`
+	MentionEmail bool `json:"mention_email"`
+	// SecurityEmail is whether the user is emailed about logins from new devices and changes to their account.
+	SecurityEmail bool      `json:"security_email"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func newPreferencesResponse(preferences repository.NotificationPreferences) preferencesResponse {
+	return preferencesResponse{
+		MentionInApp:  preferences.MentionInApp,
+		MentionEmail:  preferences.MentionEmail,
+		SecurityEmail: preferences.SecurityEmail,
+		UpdatedAt:     preferences.UpdatedAt,
+	}
+}
+
+// @Summary Returns which events notify the user in the app and which by email.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} preferencesResponse
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /users/me/preferences [get]
+func (s *Server) getPreferencesHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	preferences, err := s.NotificationRepository.FindNotificationPreferences(c.Request.Context(), user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't find notification preferences", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newPreferencesResponse(preferences))
+}
+
+// updatePreferencesRequest only changes the preferences which are set.
+type updatePreferencesRequest struct {
+	MentionInApp  *bool `json:"mention_in_app"`
+	MentionEmail  *bool `json:"mention_email"`
+	SecurityEmail *bool `json:"security_email"`
+}
+
+// @Summary Changes which events notify the user in the app and which by email.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body updatePreferencesRequest true "Preferences body"
+// @Security ApiKeyAuth
+// @Success 200 {object} preferencesResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /users/me/preferences [put]
+func (s *Server) updatePreferencesHandler(c *gin.Context) {
+	var request updatePreferencesRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	user := s.getUserFromContext(c)
+
+	preferences, err := s.NotificationRepository.FindNotificationPreferences(c.Request.Context(), user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't find notification preferences", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	if request.MentionInApp != nil {
+		preferences.MentionInApp = *request.MentionInApp
+	}
+
+	if request.MentionEmail != nil {
+		preferences.MentionEmail = *request.MentionEmail
+	}
+
+	if request.SecurityEmail != nil {
+		preferences.SecurityEmail = *request.SecurityEmail
+	}
+
+	updated, err := s.NotificationRepository.UpdateNotificationPreferences(c.Request.Context(), preferences)
+	if err != nil {
+		s.logger(c).Debug("couldn't update notification preferences", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newPreferencesResponse(updated))
+}
@@ -0,0 +1,485 @@
+package server
+
+import (
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	maxOrganizationNameLength = 100
+	minOrganizationSlugLength = 3
+	maxOrganizationSlugLength = 50
+)
+
+var organizationSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+var organizationRoles = []string{repository.OrganizationRoleAdmin, repository.OrganizationRoleEditor, repository.OrganizationRoleReader}
+
+type createOrganizationRequest struct {
+	Name string `json:"name" validate:"max=maxOrganizationNameLength"`
+	Slug string `json:"slug" validate:"min=minOrganizationSlugLength,max=maxOrganizationSlugLength"`
+}
+
+type organizationResponse struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+	// Role is the requesting user's role in the organization.
+	Role      string    `json:"role" enums:"admin,editor,reader"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type organizationMemberResponse struct {
+	UserID    string    `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role" enums:"admin,editor,reader"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type setOrganizationMemberRequest struct {
+	Role string `json:"role" enums:"admin,editor,reader"`
+}
+
+func newOrganizationResponse(organization repository.Organization, role string) organizationResponse {
+	return organizationResponse{
+		ID:        organization.PublicID,
+		Name:      organization.Name,
+		Slug:      organization.Slug,
+		Role:      role,
+		CreatedAt: organization.CreatedAt,
+	}
+}
+
+// organizationFromParam looks up the organization in the organizationId path param and writes an error
+// response if it can't be found, in which case the handler should return.
+func (s *Server) organizationFromParam(c *gin.Context) (repository.Organization, bool) {
+	organizationId, err := s.publicIDParam(c, "organizationId")
+	if err != nil {
+		s.logger(c).Debug("organization id is invalid", zap.String("organizationId", c.Param("organizationId")))
+		s.badRequestResponse(c, err.Error())
+		return repository.Organization{}, false
+	}
+
+	organization, err := s.OrganizationRepository.FindOrganizationByPublicID(c.Request.Context(), organizationId)
+	if err != nil {
+		s.logger(c).Debug("couldn't find organization", zap.Error(err), zap.String("organizationId", organizationId))
+		c.Error(err)
+		return repository.Organization{}, false
+	}
+
+	return organization, true
+}
+
+// @Summary Creates an organization, the user creating it becomes its first admin.
+// @Tags organization
+// @Accept json
+// @Produce json
+// @Param request body createOrganizationRequest true "Create organization body"
+// @Security ApiKeyAuth
+// @Success 201 {object} organizationResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 409 {object} errorResponse "An organization with this slug already exists"
+// @Failure 500 {object} errorResponse
+// @Router /organizations [post]
+func (s *Server) createOrganizationHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	var request createOrganizationRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+	request.Slug = strings.TrimSpace(request.Slug)
+
+	v := s.validator(c)
+	v.Struct(request)
+	v.Matches("slug", request.Slug, organizationSlugPattern, "may only contain lowercase letters and digits separated by dashes")
+
+	ok, errors := v.IsValid()
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	organization, err := s.OrganizationRepository.InsertOrganization(c.Request.Context(), repository.Organization{Name: request.Name, Slug: request.Slug}, user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't insert organization", zap.Error(err), zap.String("slug", request.Slug))
+		c.Error(err)
+		return
+	}
+
+	s.logger(c).Info("organization created", zap.Int("organizationId", organization.ID), zap.String("slug", organization.Slug))
+
+	c.JSON(http.StatusCreated, newOrganizationResponse(organization, repository.OrganizationRoleAdmin))
+}
+
+// @Summary Returns the organizations the user is a member of.
+// @Tags organization
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[organizationResponse]
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /organizations [get]
+func (s *Server) getOrganizationsHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	organizations, err := s.OrganizationRepository.FindOrganizationsByUserID(c.Request.Context(), user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't find organizations", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	data := []organizationResponse{}
+	for _, organization := range organizations {
+		data = append(data, newOrganizationResponse(organization.Organization, organization.Role))
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, c, data, 0, 0))
+}
+
+// @Summary Returns an organization the user is a member of.
+// @Tags organization
+// @Accept json
+// @Produce json
+// @Param organizationId path string true "organization id"
+// @Security ApiKeyAuth
+// @Success 200 {object} organizationResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the user isn't a member of the organization"
+// @Failure 404 {object} errorResponse "An organization with the provided id doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /organizations/{organizationId} [get]
+func (s *Server) getOrganizationHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	organization, ok := s.organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	if !s.enforceOrganizationPermissions(c, user, organization.ID, objectOrganization, actionRead, 0) {
+		return
+	}
+
+	role, err := s.OrganizationRepository.FindMemberRole(c.Request.Context(), organization.ID, user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't find member role", zap.Error(err), zap.Int("organizationId", organization.ID))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newOrganizationResponse(organization, role))
+}
+
+// @Summary Deletes an organization along with its posts.
+// @Tags organization
+// @Accept json
+// @Produce json
+// @Param organizationId path string true "organization id"
+// @Security ApiKeyAuth
+// @Success 200 "Organization deleted successfully"
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "An organization with the provided id doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /organizations/{organizationId} [delete]
+func (s *Server) deleteOrganizationHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	organization, ok := s.organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	if !s.enforceOrganizationPermissions(c, user, organization.ID, objectOrganization, actionDelete, 0) {
+		return
+	}
+
+	err := s.OrganizationRepository.DeleteOrganization(c.Request.Context(), organization.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't delete organization", zap.Error(err), zap.Int("organizationId", organization.ID))
+		c.Error(err)
+		return
+	}
+
+	s.logger(c).Info("organization deleted", zap.Int("organizationId", organization.ID), zap.String("slug", organization.Slug))
+
+	c.Status(http.StatusOK)
+}
+
+// @Summary Returns the members of an organization.
+// @Tags organization
+// @Accept json
+// @Produce json
+// @Param organizationId path string true "organization id"
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[organizationMemberResponse]
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the user isn't a member of the organization"
+// @Failure 404 {object} errorResponse "An organization with the provided id doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /organizations/{organizationId}/members [get]
+func (s *Server) getOrganizationMembersHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	organization, ok := s.organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	if !s.enforceOrganizationPermissions(c, user, organization.ID, objectMember, actionRead, 0) {
+		return
+	}
+
+	members, err := s.OrganizationRepository.FindMembers(c.Request.Context(), organization.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't find members", zap.Error(err), zap.Int("organizationId", organization.ID))
+		c.Error(err)
+		return
+	}
+
+	data := []organizationMemberResponse{}
+	for _, member := range members {
+		data = append(data, organizationMemberResponse{
+			UserID:    member.UserPublicID,
+			Username:  member.Username,
+			Role:      member.Role,
+			CreatedAt: member.CreatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, c, data, 0, 0))
+}
+
+// @Summary Adds a user to an organization or changes their role.
+// @Tags organization
+// @Accept json
+// @Produce json
+// @Param organizationId path string true "organization id"
+// @Param username path string true "username"
+// @Param request body setOrganizationMemberRequest true "Member body"
+// @Security ApiKeyAuth
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "The organization or user doesn't exist"
+// @Failure 409 {object} errorResponse "The organization's last admin can't be demoted"
+// @Failure 500 {object} errorResponse
+// @Router /organizations/{organizationId}/members/{username} [put]
+func (s *Server) setOrganizationMemberHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	organization, ok := s.organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	if !s.enforceOrganizationPermissions(c, user, organization.ID, objectMember, actionWrite, 0) {
+		return
+	}
+
+	var request setOrganizationMemberRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	v := s.validator(c)
+	v.OneOf("role", request.Role, organizationRoles...)
+
+	ok, errors := v.IsValid()
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	member, err := s.UserRepository.FindUserByUsername(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("username", c.Param("username")))
+		c.Error(err)
+		return
+	}
+
+	err = s.OrganizationRepository.SetMember(c.Request.Context(), organization.ID, member.ID, request.Role)
+	if err != nil {
+		s.logger(c).Debug("couldn't set member", zap.Error(err), zap.Int("organizationId", organization.ID), zap.Int("userId", member.ID))
+		c.Error(err)
+		return
+	}
+
+	s.logger(c).Info("organization member set", zap.Int("organizationId", organization.ID), zap.Int("userId", member.ID), zap.String("role", request.Role))
+
+	s.successResponse(c, "member has been updated")
+}
+
+// @Summary Removes a user from an organization, members can remove themselves to leave it.
+// @Tags organization
+// @Accept json
+// @Produce json
+// @Param organizationId path string true "organization id"
+// @Param username path string true "username"
+// @Security ApiKeyAuth
+// @Success 200 "Member removed successfully"
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "The organization or member doesn't exist"
+// @Failure 409 {object} errorResponse "The organization's last admin can't be removed"
+// @Failure 500 {object} errorResponse
+// @Router /organizations/{organizationId}/members/{username} [delete]
+func (s *Server) deleteOrganizationMemberHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	organization, ok := s.organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	member, err := s.UserRepository.FindUserByUsername(c.Request.Context(), c.Param("username"))
+	if err != nil {
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("username", c.Param("username")))
+		c.Error(err)
+		return
+	}
+
+	if !s.enforceOrganizationPermissions(c, user, organization.ID, objectMember, actionDelete, member.ID) {
+		return
+	}
+
+	err = s.OrganizationRepository.DeleteMember(c.Request.Context(), organization.ID, member.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't delete member", zap.Error(err), zap.Int("organizationId", organization.ID), zap.Int("userId", member.ID))
+		c.Error(err)
+		return
+	}
+
+	s.logger(c).Info("organization member removed", zap.Int("organizationId", organization.ID), zap.Int("userId", member.ID))
+
+	c.Status(http.StatusOK)
+}
+
+// @Summary Returns the posts published in an organization.
+// @Tags organization
+// @Accept json
+// @Produce json
+// @Param organizationId path string true "organization id"
+// @Param page query int32 true "page"
+// @Param limit query int32 true "limit"
+// @Param fields query string false "comma separated fields to return for each post, e.g. id,title,created_at"
+// @Param sort query string false "created_at or updated_at, prefixed with - for descending order" Enums(created_at, -created_at, updated_at, -updated_at)
+// @Param created_after query string false "only return posts created after this RFC 3339 timestamp"
+// @Param created_before query string false "only return posts created before this RFC 3339 timestamp"
+// @Param updated_after query string false "only return posts updated after this RFC 3339 timestamp"
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[personalPosts]
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the user isn't a member of the organization"
+// @Failure 404 {object} errorResponse "An organization with the provided id doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /organizations/{organizationId}/posts [get]
+func (s *Server) getOrganizationPostsHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	page, limit, err := s.validatePageAndLimit(c)
+	if err != nil {
+		s.logger(c).Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
+		return
+	}
+
+	fields, err := s.parseFields(c, personalPosts{})
+	if err != nil {
+		s.logger(c).Debug("invalid fields", zap.Error(err), zap.String("fields", c.Query("fields")))
+		c.Error(ErrInvalidInput{err.Error()})
+		return
+	}
+
+	options, err := s.parsePostListOptions(c)
+	if err != nil {
+		s.logger(c).Debug("invalid sort or filter", zap.Error(err))
+		c.Error(ErrInvalidInput{err.Error()})
+		return
+	}
+
+	organization, ok := s.organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	if !s.enforceOrganizationPermissions(c, user, organization.ID, objectPost, actionRead, 0) {
+		return
+	}
+
+	options.ViewerID = user.ID
+
+	organizationPosts, err := s.PostRepository.FindByOrganizationID(c.Request.Context(), organization.ID, options, page, limit)
+	if err != nil {
+		s.logger(c).Debug("couldn't find organization posts", zap.Error(err), zap.Int("organizationId", organization.ID))
+		c.Error(err)
+		return
+	}
+
+	posts := []personalPosts{}
+	for _, post := range organizationPosts {
+		posts = append(posts, personalPosts{
+			ID:           post.PublicID,
+			Title:        post.Title,
+			Body:         post.Body,
+			Status:       post.Status,
+			CanonicalURL: post.CanonicalURL,
+			License:      post.License,
+			CreatedAt:    post.CreatedAt,
+			UpdatedAt:    post.UpdatedAt,
+			Links:        s.postLinks(c, post.PublicID, ""),
+		})
+	}
+
+	if fields != nil {
+		s.respond(c, http.StatusOK, newListResponse(s, c, filterList(fields, posts), page, limit))
+		return
+	}
+
+	s.respond(c, http.StatusOK, newListResponse(s, c, posts, page, limit))
+}
+
+// @Summary Publishes a post in an organization.
+// @Tags organization
+// @Accept json
+// @Produce json
+// @Param organizationId path string true "organization id"
+// @Param request body createPostRequest true "Create post body"
+// @Security ApiKeyAuth
+// @Success 201 {object} createPostResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "An organization with the provided id doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /organizations/{organizationId}/posts [post]
+func (s *Server) createOrganizationPostHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	organization, ok := s.organizationFromParam(c)
+	if !ok {
+		return
+	}
+
+	if !s.enforceOrganizationPermissions(c, user, organization.ID, objectPost, actionCreate, 0) {
+		return
+	}
+
+	s.createPost(c, user, &organization.ID)
+}
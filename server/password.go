@@ -0,0 +1,60 @@
+package server
+
+import (
+	"context"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/alexedwards/argon2id"
+	"go.uber.org/zap"
+)
+
+func (s *Server) argon2Params() *argon2id.Params {
+	return &argon2id.Params{
+		Memory:      s.Config.Argon2Memory,
+		Iterations:  s.Config.Argon2Iterations,
+		Parallelism: s.Config.Argon2Parallelism,
+		SaltLength:  s.Config.Argon2SaltLength,
+		KeyLength:   s.Config.Argon2KeyLength,
+	}
+}
+
+// comparePassword checks the password against the user's hash. If the hash was created
+// with weaker parameters than the configured ones, the password gets rehashed with the
+// current parameters.
+func (s *Server) comparePassword(ctx context.Context, user repository.User, password string) (bool, error) {
+	ok, params, err := argon2id.CheckHash(password, user.Password)
+	if err != nil || !ok {
+		return ok, err
+	}
+
+	if s.isArgon2ParamsOutdated(params) {
+		s.rehashPassword(ctx, user, password)
+	}
+
+	return true, nil
+}
+
+func (s *Server) isArgon2ParamsOutdated(params *argon2id.Params) bool {
+	current := s.argon2Params()
+
+	return params.Memory < current.Memory ||
+		params.Iterations < current.Iterations ||
+		params.Parallelism < current.Parallelism ||
+		params.SaltLength < current.SaltLength ||
+		params.KeyLength < current.KeyLength
+}
+
+func (s *Server) rehashPassword(ctx context.Context, user repository.User, password string) {
+	hash, err := argon2id.CreateHash(password, s.argon2Params())
+	if err != nil {
+		s.Logger.Error("couldn't rehash password", zap.Error(err), zap.Int("userId", user.ID))
+		return
+	}
+
+	err = s.UserRepository.SetPassword(ctx, user.ID, hash)
+	if err != nil {
+		s.Logger.Error("couldn't update rehashed password", zap.Error(err), zap.Int("userId", user.ID))
+		return
+	}
+
+	s.Logger.Info("password rehashed with the current argon2 parameters", zap.Int("userId", user.ID))
+}
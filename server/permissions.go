@@ -0,0 +1,161 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	objectUser        = "user"
+	objectPost        = "post"
+	objectAuditLog    = "audit_log"
+	objectJob         = "job"
+	objectWebhook     = "webhook"
+	objectAppearance  = "appearance"
+	objectMaintenance = "maintenance"
+	objectHistory     = "history"
+	objectReport      = "report"
+	objectStats       = "stats"
+	objectShadowban   = "shadowban"
+
+	objectOrganization = "organization"
+	objectMember       = "member"
+
+	actionRead   = "read"
+	actionCreate = "create"
+	actionWrite  = "write"
+	actionDelete = "delete"
+)
+
+// authorize reports whether the user is allowed to perform the action on the object.
+// Owners are always allowed to act on their own resources, everyone else has to be
+// granted the permission through the casbin policy.
+func (s *Server) authorize(user repository.User, object, action string, ownerId int) (bool, error) {
+	if ownerId != 0 && ownerId == user.ID {
+		return true, nil
+	}
+
+	return s.CasbinEnforcer.Enforce(user.Role, object, action)
+}
+
+// enforcePermissions checks the user's permissions for the object and writes an
+// error response if they are insufficient, in which case the handler should return.
+func (s *Server) enforcePermissions(c *gin.Context, user repository.User, object, action string, ownerId int) bool {
+	ok, err := s.authorize(user, object, action, ownerId)
+	if err != nil {
+		s.logger(c).Error("couldn't enforce rules", zap.Error(err), zap.String("object", object), zap.String("action", action))
+		s.internalServerErrorResponse(c)
+		return false
+	}
+
+	if !ok {
+		s.logger(c).Debug("user has insufficient permissions", zap.String("username", user.Username), zap.String("role", user.Role), zap.String("object", object), zap.String("action", action))
+		s.forbiddenResponse(c, "insufficient permissions")
+		return false
+	}
+
+	return true
+}
+
+// requirePermission declares the permission a route needs, it has to be used after userAuth.
+func (s *Server) requirePermission(object, action string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user := s.getUserFromContext(c)
+
+		if !s.enforcePermissions(c, user, object, action, 0) {
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// authorizeInOrganization reports whether the user is allowed to perform the action on the object within the
+// organization. Only members are allowed anything, owners may always act on their own resources and everyone else
+// needs the permission granted to their role in rbac/organization_policy.csv.
+func (s *Server) authorizeInOrganization(ctx context.Context, user repository.User, organizationId int, object, action string, ownerId int) (bool, error) {
+	role, err := s.OrganizationRepository.FindMemberRole(ctx, organizationId, user.ID)
+	if errors.Is(err, repository.ErrMemberNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if ownerId != 0 && ownerId == user.ID {
+		return true, nil
+	}
+
+	return s.OrganizationEnforcer.Enforce(role, object, action)
+}
+
+// enforceOrganizationPermissions is enforcePermissions for actions within an organization.
+func (s *Server) enforceOrganizationPermissions(c *gin.Context, user repository.User, organizationId int, object, action string, ownerId int) bool {
+	ok, err := s.authorizeInOrganization(c.Request.Context(), user, organizationId, object, action, ownerId)
+	if err != nil {
+		s.logger(c).Error("couldn't enforce organization rules", zap.Error(err), zap.Int("organizationId", organizationId), zap.String("object", object), zap.String("action", action))
+		s.internalServerErrorResponse(c)
+		return false
+	}
+
+	if !ok {
+		s.logger(c).Debug("user has insufficient permissions in organization", zap.String("username", user.Username), zap.Int("organizationId", organizationId), zap.String("object", object), zap.String("action", action))
+		s.forbiddenResponse(c, "insufficient permissions")
+		return false
+	}
+
+	return true
+}
+
+// enforcePostPermissions checks the permissions for a personal post against the casbin policy, and for a post
+// published in an organization against the organization's policy. Users allowed to moderate every post through
+// the casbin policy may moderate posts in organizations they aren't members of too. Co-authors may read and edit
+// the post like its owner, but only its owner may delete it.
+func (s *Server) enforcePostPermissions(c *gin.Context, user repository.User, post repository.Post, action string) bool {
+	if post.UserID != user.ID && (action == actionRead || action == actionWrite) {
+		ok, err := s.isCoAuthor(c.Request.Context(), user, post)
+		if err != nil {
+			s.logger(c).Error("couldn't check co-authors", zap.Error(err), zap.Int("postId", post.ID))
+			s.internalServerErrorResponse(c)
+			return false
+		}
+
+		if ok {
+			return true
+		}
+	}
+
+	if post.OrganizationID == nil {
+		return s.enforcePermissions(c, user, objectPost, action, post.UserID)
+	}
+
+	if action != actionRead {
+		ok, err := s.authorize(user, objectPost, action, 0)
+		if err != nil {
+			s.logger(c).Error("couldn't enforce rules", zap.Error(err), zap.String("object", objectPost), zap.String("action", action))
+			s.internalServerErrorResponse(c)
+			return false
+		}
+
+		if ok {
+			return true
+		}
+	}
+
+	return s.enforceOrganizationPermissions(c, user, *post.OrganizationID, objectPost, action, post.UserID)
+}
+
+// isCoAuthor reports whether the user is a co-author of the post. Co-authors of a post published in an organization
+// lose access to it once they leave the organization.
+func (s *Server) isCoAuthor(ctx context.Context, user repository.User, post repository.Post) (bool, error) {
+	ok, err := s.PostRepository.IsCoAuthor(ctx, post.ID, user.ID)
+	if err != nil || !ok || post.OrganizationID == nil {
+		return ok, err
+	}
+
+	return s.authorizeInOrganization(ctx, user, *post.OrganizationID, objectPost, actionRead, 0)
+}
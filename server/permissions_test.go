@@ -0,0 +1,287 @@
+package server
+
+import (
+	"context"
+	"github.com/XiovV/blog-api/config"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/XiovV/blog-api/server/mocks"
+	"github.com/casbin/casbin/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+
+	enforcer, err := casbin.NewEnforcer("../rbac/rbac_model.conf", "../rbac/rbac_policy.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	organizationEnforcer, err := casbin.NewEnforcer("../rbac/rbac_model.conf", "../rbac/organization_policy.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &Server{
+		Config:               &config.Config{SigningKey: "test-signing-key", DefaultRole: "user"},
+		Logger:               zap.NewNop(),
+		CasbinEnforcer:       enforcer,
+		OrganizationEnforcer: organizationEnforcer,
+	}
+}
+
+func newTestContext(method, target string) (*gin.Context, *httptest.ResponseRecorder) {
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(method, target, nil)
+
+	return c, recorder
+}
+
+func TestAuthorize(t *testing.T) {
+	s := newTestServer(t)
+
+	user := repository.User{ID: 1, Username: "user", Role: "user", Active: true}
+	shadowbanned := repository.User{ID: 2, Username: "shadowbanned", Role: "user", Active: true, Shadowbanned: true}
+	moderator := repository.User{ID: 3, Username: "moderator", Role: "moderator", Active: true}
+	admin := repository.User{ID: 4, Username: "admin", Role: "admin", Active: true}
+
+	tests := []struct {
+		name    string
+		user    repository.User
+		object  string
+		action  string
+		ownerId int
+		want    bool
+	}{
+		{"owner writes own post", user, objectPost, actionWrite, user.ID, true},
+		{"owner deletes own post", user, objectPost, actionDelete, user.ID, true},
+		{"non-owner writes post", user, objectPost, actionWrite, admin.ID, false},
+		{"non-owner deletes post", user, objectPost, actionDelete, admin.ID, false},
+		{"user reads audit log", user, objectAuditLog, actionRead, 0, false},
+		{"shadowbanned owner writes own post", shadowbanned, objectPost, actionWrite, shadowbanned.ID, true},
+		{"shadowbanned non-owner writes post", shadowbanned, objectPost, actionWrite, user.ID, false},
+		{"moderator writes someone's post", moderator, objectPost, actionWrite, user.ID, true},
+		{"moderator deletes someone's post", moderator, objectPost, actionDelete, user.ID, true},
+		{"moderator reads reports", moderator, objectReport, actionRead, 0, true},
+		{"moderator deletes user", moderator, objectUser, actionDelete, 0, false},
+		{"moderator reads jobs", moderator, objectJob, actionRead, 0, false},
+		{"admin deletes user", admin, objectUser, actionDelete, 0, true},
+		{"admin reads jobs", admin, objectJob, actionRead, 0, true},
+		{"admin writes maintenance", admin, objectMaintenance, actionWrite, 0, true},
+		{"admin writes someone's post", admin, objectPost, actionWrite, user.ID, true},
+		{"unknown role", repository.User{ID: 5, Role: "nobody"}, objectPost, actionRead, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.authorize(tt.user, tt.object, tt.action, tt.ownerId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("authorize() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthorizeInOrganization(t *testing.T) {
+	const organizationId = 10
+
+	member := repository.User{ID: 1, Username: "member", Role: "user", Active: true}
+	other := repository.User{ID: 2, Username: "other", Role: "user", Active: true}
+
+	tests := []struct {
+		name    string
+		role    string
+		object  string
+		action  string
+		ownerId int
+		want    bool
+	}{
+		{"non-member reads post", "", objectPost, actionRead, 0, false},
+		{"non-member writes own post", "", objectPost, actionWrite, member.ID, false},
+		{"reader reads post", "reader", objectPost, actionRead, 0, true},
+		{"reader creates post", "reader", objectPost, actionCreate, 0, false},
+		{"reader writes someone's post", "reader", objectPost, actionWrite, other.ID, false},
+		{"reader writes own post", "reader", objectPost, actionWrite, member.ID, true},
+		{"editor creates post", "editor", objectPost, actionCreate, 0, true},
+		{"editor deletes someone's post", "editor", objectPost, actionDelete, other.ID, false},
+		{"admin deletes someone's post", "admin", objectPost, actionDelete, other.ID, true},
+		{"admin writes member", "admin", objectMember, actionWrite, 0, true},
+		{"editor writes member", "editor", objectMember, actionWrite, 0, false},
+		{"admin deletes organization", "admin", objectOrganization, actionDelete, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			organizations := mocks.NewMockOrganizationStore(ctrl)
+			if tt.role == "" {
+				organizations.EXPECT().FindMemberRole(gomock.Any(), organizationId, member.ID).Return("", repository.ErrMemberNotFound)
+			} else {
+				organizations.EXPECT().FindMemberRole(gomock.Any(), organizationId, member.ID).Return(tt.role, nil)
+			}
+
+			s := newTestServer(t)
+			s.OrganizationRepository = organizations
+
+			got, err := s.authorizeInOrganization(context.Background(), member, organizationId, tt.object, tt.action, tt.ownerId)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got != tt.want {
+				t.Errorf("authorizeInOrganization() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnforcePostPermissionsCoAuthor(t *testing.T) {
+	organizationId := 10
+
+	owner := repository.User{ID: 1, Username: "owner", Role: "user", Active: true}
+	coAuthor := repository.User{ID: 2, Username: "coauthor", Role: "user", Active: true}
+
+	personalPost := repository.Post{ID: 100, UserID: owner.ID}
+	organizationPost := repository.Post{ID: 101, UserID: owner.ID, OrganizationID: &organizationId}
+
+	tests := []struct {
+		name       string
+		post       repository.Post
+		action     string
+		isCoAuthor bool
+		// memberRole is the co-author's role in the post's organization, empty if they aren't a member
+		memberRole string
+		want       bool
+	}{
+		{"co-author writes personal post", personalPost, actionWrite, true, "", true},
+		{"co-author reads personal post", personalPost, actionRead, true, "", true},
+		{"co-author deletes personal post", personalPost, actionDelete, true, "", false},
+		{"stranger writes personal post", personalPost, actionWrite, false, "", false},
+		{"co-author member writes organization post", organizationPost, actionWrite, true, "reader", true},
+		{"co-author who left writes organization post", organizationPost, actionWrite, true, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			posts := mocks.NewMockPostStore(ctrl)
+			posts.EXPECT().IsCoAuthor(gomock.Any(), tt.post.ID, coAuthor.ID).Return(tt.isCoAuthor, nil).AnyTimes()
+
+			organizations := mocks.NewMockOrganizationStore(ctrl)
+			if tt.memberRole == "" {
+				organizations.EXPECT().FindMemberRole(gomock.Any(), organizationId, coAuthor.ID).Return("", repository.ErrMemberNotFound).AnyTimes()
+			} else {
+				organizations.EXPECT().FindMemberRole(gomock.Any(), organizationId, coAuthor.ID).Return(tt.memberRole, nil).AnyTimes()
+			}
+
+			s := newTestServer(t)
+			s.PostRepository = posts
+			s.OrganizationRepository = organizations
+
+			c, recorder := newTestContext(http.MethodPut, "/v1/posts/1")
+
+			got := s.enforcePostPermissions(c, coAuthor, tt.post, tt.action)
+			if got != tt.want {
+				t.Errorf("enforcePostPermissions() = %v, want %v", got, tt.want)
+			}
+
+			if !tt.want && recorder.Code != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", recorder.Code, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		role   string
+		want   int
+		object string
+		action string
+	}{
+		{"user deletes user", "user", http.StatusForbidden, objectUser, actionDelete},
+		{"moderator deletes user", "moderator", http.StatusForbidden, objectUser, actionDelete},
+		{"admin deletes user", "admin", http.StatusOK, objectUser, actionDelete},
+		{"moderator reads reports", "moderator", http.StatusOK, objectReport, actionRead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				c.Set("user", repository.User{ID: 1, Username: tt.role, Role: tt.role, Active: true})
+			}, s.requirePermission(tt.object, tt.action), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestUserAuthRejectsInactiveUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name   string
+		active bool
+		want   int
+	}{
+		{"active user", true, http.StatusOK},
+		{"inactive user", false, http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			users := mocks.NewMockUserStore(ctrl)
+			users.EXPECT().FindUserByID(gomock.Any(), 1).Return(repository.User{ID: 1, Username: "user", Role: "admin", Active: tt.active}, nil)
+
+			s := newTestServer(t)
+			s.UserRepository = users
+
+			token, err := s.generateAccessToken(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			router := gin.New()
+			router.GET("/", s.userAuth, s.requirePermission(objectUser, actionDelete), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.Header.Set("Authorization", "Bearer "+token)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+		})
+	}
+}
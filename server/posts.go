@@ -2,30 +2,42 @@ package server
 
 import (
 	"github.com/XiovV/blog-api/pkg/repository"
-	"github.com/XiovV/blog-api/pkg/validator"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 	"net/http"
-	"strconv"
 	"strings"
+	"time"
 )
 
 const (
-	maxTitleLength = 256
+	maxTitleLength        = 256
+	maxCanonicalURLLength = 2048
 )
 
 type createPostRequest struct {
-	Title string `json:"title"`
+	Title string `json:"title" validate:"max=maxTitleLength"`
 	Body  string `json:"body"`
+	// CanonicalURL is the original location of a post cross-posted from another platform.
+	CanonicalURL string `json:"canonical_url" validate:"max=maxCanonicalURLLength,url"`
+	// License is empty if the post's license is unspecified.
+	License string `json:"license" enums:"all-rights-reserved,CC-BY-4.0,CC-BY-SA-4.0,CC-BY-ND-4.0,CC-BY-NC-4.0,CC-BY-NC-SA-4.0,CC-BY-NC-ND-4.0,CC0-1.0" validate:"oneof=all-rights-reserved CC-BY-4.0 CC-BY-SA-4.0 CC-BY-ND-4.0 CC-BY-NC-4.0 CC-BY-NC-SA-4.0 CC-BY-NC-ND-4.0 CC0-1.0"`
 }
 
 type createPostResponse struct {
-	ID    int    `json:"id"`
-	Title string `json:"title"`
-	Body  string `json:"body"`
+	ID           string     `json:"id"`
+	Title        string     `json:"title"`
+	Body         string     `json:"body"`
+	Status       string     `json:"status" enums:"published,held"`
+	CanonicalURL string     `json:"canonical_url,omitempty"`
+	License      string     `json:"license,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Links        *postLinks `json:"links,omitempty"`
 }
 
 // @Summary Creates a post
+// @Description Posts flagged as spam are held for moderation, their status is "held" and they're only visible to
+// @Description their author until a moderator approves them.
 // @Tags post
 // @Accept json
 // @Produce json
@@ -37,61 +49,94 @@ type createPostResponse struct {
 // @Failure 500 {object} errorResponse
 // @Router /posts/ [post]
 func (s *Server) createPostHandler(c *gin.Context) {
-	user := s.getUserFromContext(c)
+	s.createPost(c, s.getUserFromContext(c), nil)
+}
 
+// createPost creates a post from the request body, as a personal post if organizationId is nil and in the
+// organization otherwise. The caller has to check the user may publish in the organization.
+func (s *Server) createPost(c *gin.Context, user repository.User, organizationId *int) {
 	var request createPostRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		s.Logger.Debug("json is invalid", zap.Error(err))
-		c.Error(ErrInvalidJSON)
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
 	request.Title = strings.TrimSpace(request.Title)
 
-	v := validator.New()
-	v.RequiredMax("title", request.Title, maxTitleLength)
+	request.CanonicalURL = strings.TrimSpace(request.CanonicalURL)
 
-	ok, errors := v.IsValid()
+	ok, errors := s.validator(c).ValidateStruct(request)
 	if !ok {
-		s.Logger.Debug("input is invalid", zap.Strings("error", errors))
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors})
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
 		return
 	}
 
 	post := repository.Post{
-		UserID: user.ID,
-		Title:  request.Title,
-		Body:   request.Body,
+		UserID:         user.ID,
+		OrganizationID: organizationId,
+		Title:          request.Title,
+		Body:           request.Body,
+		CanonicalURL:   request.CanonicalURL,
+		License:        request.License,
+	}
+
+	if s.isSpam(c, user, post) {
+		post.Status = repository.PostStatusHeld
 	}
 
-	newPost, err := s.PostRepository.InsertPost(post)
+	newPost, err := s.PostRepository.InsertPost(c.Request.Context(), post)
 	if err != nil {
-		s.Logger.Error("couldn't insert post", zap.Error(err))
-		s.internalServerErrorResponse(c)
+		s.logger(c).Debug("couldn't insert post", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
+	s.recordHistory(&user.ID, objectPost, newPost.ID, newAuditPost(newPost))
+
+	if newPost.Status == repository.PostStatusHeld {
+		s.logger(c).Info("post held for moderation", zap.Int("postId", newPost.ID), zap.String("username", user.Username))
+	} else {
+		s.publishWebhookEvent(c.Request.Context(), s.logger(c), webhookEventPostPublished, webhookPost{ID: newPost.PublicID, UserID: user.PublicID, Title: newPost.Title, Body: newPost.Body})
+		s.enqueueMentions(c.Request.Context(), s.logger(c), newPost)
+	}
+
 	response := createPostResponse{
-		ID:    newPost.ID,
-		Title: newPost.Title,
-		Body:  newPost.Body,
+		ID:           newPost.PublicID,
+		Title:        newPost.Title,
+		Body:         newPost.Body,
+		Status:       newPost.Status,
+		CanonicalURL: newPost.CanonicalURL,
+		License:      newPost.License,
+		CreatedAt:    newPost.CreatedAt,
+		UpdatedAt:    newPost.UpdatedAt,
+		Links:        s.postLinks(c, newPost.PublicID, user.Username),
 	}
 
-	c.JSON(http.StatusCreated, response)
+	s.respond(c, http.StatusCreated, response)
 }
 
-// TODO: add author info here
 type getPostResponse struct {
-	ID    int    `json:"id"`
-	Title string `json:"title"`
-	Body  string `json:"body"`
+	ID           string    `json:"id"`
+	Title        string    `json:"title"`
+	Body         string    `json:"body"`
+	Status       string    `json:"status" enums:"published,held"`
+	CanonicalURL string    `json:"canonical_url,omitempty"`
+	License      string    `json:"license,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	// Authors lists the post's owner followed by its co-authors, it's only returned for a single post.
+	Authors []postAuthorResponse `json:"authors,omitempty"`
+	Links   *postLinks           `json:"links,omitempty"`
 }
 
 // @Summary Gets a post
 // @Tags post
 // @Accept json
 // @Produce json
-// @Param postId path int true "post id"
+// @Param postId path string true "post id"
+// @Param fields query string false "comma separated fields to return, e.g. id,title,created_at"
 // @Security ApiKeyAuth
 // @Success 200 {object} getPostResponse
 // @Failure 400 {object} errorResponse "Input is invalid"
@@ -100,32 +145,76 @@ type getPostResponse struct {
 // @Failure 500 {object} errorResponse
 // @Router /posts/{postId} [get]
 func (s *Server) getPostHandler(c *gin.Context) {
-	postId, err := strconv.Atoi(c.Param("postId"))
+	postId, err := s.publicIDParam(c, "postId")
+	if err != nil {
+		s.logger(c).Debug("post id is invalid", zap.String("postId", c.Param("postId")))
+		s.badRequestResponse(c, err.Error())
+		return
+	}
+
+	fields, err := s.parseFields(c, getPostResponse{})
 	if err != nil {
-		s.Logger.Debug("post id not an integer", zap.String("postId", c.Param("postId")))
-		s.badRequestResponse(c, "post id must be an integer")
+		s.logger(c).Debug("invalid fields", zap.Error(err), zap.String("fields", c.Query("fields")))
+		c.Error(ErrInvalidInput{err.Error()})
 		return
 	}
 
-	post, err := s.PostRepository.FindPostByPostID(postId)
+	post, err := s.PostRepository.FindPostByPublicID(c.Request.Context(), postId)
 	if err != nil {
-		s.Logger.Debug("post could not be found", zap.Error(err), zap.Int("postId", postId))
+		s.logger(c).Debug("post could not be found", zap.Error(err), zap.String("postId", postId))
 		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, getPostResponse{
-		ID:    post.ID,
-		Title: post.Title,
-		Body:  post.Body,
-	})
+	user := s.getUserFromContext(c)
+
+	hidden, err := s.isPostHidden(c.Request.Context(), post, user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't check the post's visibility", zap.Error(err), zap.String("postId", postId))
+		c.Error(err)
+		return
+	}
+
+	if hidden {
+		s.logger(c).Debug("post is hidden from the user", zap.String("postId", postId))
+		c.Error(repository.ErrPostNotFound)
+		return
+	}
+
+	if post.OrganizationID != nil && !s.enforcePostPermissions(c, user, post, actionRead) {
+		return
+	}
+
+	// the owner, who comes first, is needed for the author link
+	var authors []repository.PostAuthor
+	if fields == nil || fields["authors"] || fields["links"] {
+		authors, err = s.PostRepository.FindPostAuthors(c.Request.Context(), post.ID)
+		if err != nil {
+			s.logger(c).Debug("couldn't find post authors", zap.Error(err), zap.Int("postId", post.ID))
+			c.Error(err)
+			return
+		}
+	}
+
+	s.respond(c, http.StatusOK, fields.filter(getPostResponse{
+		ID:           post.PublicID,
+		Title:        post.Title,
+		Body:         post.Body,
+		Status:       post.Status,
+		CanonicalURL: post.CanonicalURL,
+		License:      post.License,
+		CreatedAt:    post.CreatedAt,
+		UpdatedAt:    post.UpdatedAt,
+		Authors:      newPostAuthorsResponse(authors),
+		Links:        s.postLinks(c, post.PublicID, ownerUsername(authors)),
+	}))
 }
 
 // @Summary Deletes a post
 // @Tags post
 // @Accept json
 // @Produce json
-// @Param postId path int true "post id"
+// @Param postId path string true "post id"
 // @Security ApiKeyAuth
 // @Success 200 "Post deleted successfully"
 // @Failure 400 {object} errorResponse "Input is invalid"
@@ -136,36 +225,37 @@ func (s *Server) getPostHandler(c *gin.Context) {
 func (s *Server) deletePostHandler(c *gin.Context) {
 	user := s.getUserFromContext(c)
 
-	postId, err := strconv.Atoi(c.Param("postId"))
+	postId, err := s.publicIDParam(c, "postId")
 	if err != nil {
-		s.Logger.Debug("post id not an integer", zap.String("postId", c.Param("postId")))
-		s.badRequestResponse(c, "post id must be an integer")
+		s.logger(c).Debug("post id is invalid", zap.String("postId", c.Param("postId")))
+		s.badRequestResponse(c, err.Error())
 		return
 	}
 
-	post, err := s.PostRepository.FindPostByPostID(postId)
+	post, err := s.PostRepository.FindPostByPublicID(c.Request.Context(), postId)
 	if err != nil {
-		s.Logger.Debug("post could not be found", zap.Error(err), zap.Int("postId", postId))
+		s.logger(c).Debug("post could not be found", zap.Error(err), zap.String("postId", postId))
 		c.Error(err)
 		return
 	}
 
-	if post.UserID != user.ID {
-		ok := s.enforcePermissions(c, user.Role, "post", "delete")
-		if !ok {
-			s.Logger.Debug("user has insufficient permissions", zap.String("username", user.Username), zap.String("role", user.Role))
-			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
-			return
-		}
+	if !s.enforcePostPermissions(c, user, post, actionDelete) {
+		return
 	}
 
-	err = s.PostRepository.DeletePostByPostID(postId)
+	err = s.PostRepository.DeletePostByPostID(c.Request.Context(), post.ID)
 	if err != nil {
-		s.Logger.Error("couldn't delete post", zap.Error(err), zap.Int("postId", postId))
-		s.internalServerErrorResponse(c)
+		s.logger(c).Debug("couldn't delete post", zap.Error(err), zap.Int("postId", post.ID))
+		c.Error(err)
 		return
 	}
 
+	s.recordHistory(&user.ID, objectPost, post.ID, nil)
+
+	if post.UserID != user.ID {
+		s.audit(c, auditActionPostDelete, objectPost, post.ID, newAuditPost(post), nil)
+	}
+
 	c.Status(http.StatusOK)
 }
 
@@ -176,8 +266,13 @@ func (s *Server) deletePostHandler(c *gin.Context) {
 // @Param username path string true "username"
 // @Param page query int32 true "page"
 // @Param limit query int32 true "limit"
+// @Param fields query string false "comma separated fields to return for each post, e.g. id,title,created_at"
+// @Param sort query string false "created_at or updated_at, prefixed with - for descending order" Enums(created_at, -created_at, updated_at, -updated_at)
+// @Param created_after query string false "only return posts created after this RFC 3339 timestamp"
+// @Param created_before query string false "only return posts created before this RFC 3339 timestamp"
+// @Param updated_after query string false "only return posts updated after this RFC 3339 timestamp"
 // @Security ApiKeyAuth
-// @Success 200 {object} getPersonalPostsResponse
+// @Success 200 {object} listResponse[personalPosts]
 // @Failure 400 {object} errorResponse "Input is invalid"
 // @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
 // @Failure 404 {object} errorResponse "User has no posts"
@@ -186,65 +281,93 @@ func (s *Server) deletePostHandler(c *gin.Context) {
 func (s *Server) getUserPostsHandler(c *gin.Context) {
 	page, limit, err := s.validatePageAndLimit(c)
 	if err != nil {
-		s.Logger.Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
-		s.badRequestResponse(c, err.Error())
+		s.logger(c).Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
 		return
 	}
 
-	username := c.Param("username")
+	fields, err := s.parseFields(c, personalPosts{})
+	if err != nil {
+		s.logger(c).Debug("invalid fields", zap.Error(err), zap.String("fields", c.Query("fields")))
+		c.Error(ErrInvalidInput{err.Error()})
+		return
+	}
 
-	user, err := s.UserRepository.FindUserByUsername(username)
+	options, err := s.parsePostListOptions(c)
 	if err != nil {
-		s.Logger.Debug("couldn't find user", zap.Error(err), zap.String("username", username))
-		c.Error(err)
+		s.logger(c).Debug("invalid sort or filter", zap.Error(err))
+		c.Error(ErrInvalidInput{err.Error()})
 		return
 	}
 
-	userPosts, err := s.PostRepository.FindByUserID(user.ID, page, limit)
+	username := c.Param("username")
+
+	user, err := s.UserRepository.FindUserByUsername(c.Request.Context(), username)
 	if err != nil {
-		s.Logger.Debug("couldn't find user posts", zap.Error(err), zap.String("username", username))
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("username", username))
 		c.Error(err)
 		return
 	}
 
-	type personalPosts struct {
-		ID    int    `json:"id"`
-		Title string `json:"title"`
-		Body  string `json:"body"`
+	// authors see their own posts held for moderation
+	options.IncludeHeld = user.ID == s.getUserFromContext(c).ID
+	options.ViewerID = s.getUserFromContext(c).ID
+
+	userPosts, err := s.PostRepository.FindByUserID(c.Request.Context(), user.ID, options, page, limit)
+	if err != nil {
+		s.logger(c).Debug("couldn't find user posts", zap.Error(err), zap.String("username", username))
+		c.Error(err)
+		return
 	}
 
 	var posts []personalPosts
 	for _, post := range userPosts {
 		posts = append(posts, personalPosts{
-			ID:    post.ID,
-			Title: post.Title,
-			Body:  post.Body,
+			ID:           post.PublicID,
+			Title:        post.Title,
+			Body:         post.Body,
+			Status:       post.Status,
+			CanonicalURL: post.CanonicalURL,
+			License:      post.License,
+			CreatedAt:    post.CreatedAt,
+			UpdatedAt:    post.UpdatedAt,
+			Links:        s.postLinks(c, post.PublicID, username),
 		})
 	}
 
-	type getPersonalPostsResponse struct {
-		Posts []personalPosts
+	if fields != nil {
+		s.respond(c, http.StatusOK, newListResponse(s, c, filterList(fields, posts), page, limit))
+		return
 	}
 
-	c.JSON(http.StatusOK, getPersonalPostsResponse{posts})
+	s.respond(c, http.StatusOK, newListResponse(s, c, posts, page, limit))
 }
 
+// updatePostRequest only changes the fields which are set, an empty canonical_url or license removes it.
 type updatePostRequest struct {
-	Title *string `json:"title"`
-	Body  *string `json:"body"`
+	Title        *string `json:"title"`
+	Body         *string `json:"body"`
+	CanonicalURL *string `json:"canonical_url"`
+	License      *string `json:"license" enums:"all-rights-reserved,CC-BY-4.0,CC-BY-SA-4.0,CC-BY-ND-4.0,CC-BY-NC-4.0,CC-BY-NC-SA-4.0,CC-BY-NC-ND-4.0,CC0-1.0"`
 }
 
 type updatePostResponse struct {
-	ID    int    `json:"id"`
-	Title string `json:"title"`
-	Body  string `json:"body"`
+	ID           string     `json:"id"`
+	Title        string     `json:"title"`
+	Body         string     `json:"body"`
+	Status       string     `json:"status" enums:"published,held"`
+	CanonicalURL string     `json:"canonical_url,omitempty"`
+	License      string     `json:"license,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Links        *postLinks `json:"links,omitempty"`
 }
 
 // @Summary Edits a post
 // @Tags post
 // @Accept json
 // @Produce json
-// @Param postId path int true "post id"
+// @Param postId path string true "post id"
 // @Param request body updatePostRequest true "Edit post body"
 // @Security ApiKeyAuth
 // @Success 200 {object} updatePostResponse
@@ -256,36 +379,33 @@ type updatePostResponse struct {
 func (s *Server) editPostHandler(c *gin.Context) {
 	user := s.getUserFromContext(c)
 
-	postId, err := strconv.Atoi(c.Param("postId"))
+	postId, err := s.publicIDParam(c, "postId")
 	if err != nil {
-		s.Logger.Debug("postId not an integer", zap.Error(err))
-		s.badRequestResponse(c, "postId must be an integer")
+		s.logger(c).Debug("postId is invalid", zap.Error(err))
+		s.badRequestResponse(c, err.Error())
 		return
 	}
 
-	post, err := s.PostRepository.FindPostByPostID(postId)
+	post, err := s.PostRepository.FindPostByPublicID(c.Request.Context(), postId)
 	if err != nil {
-		s.Logger.Debug("couldn't find post", zap.Int("postId", postId))
+		s.logger(c).Debug("couldn't find post", zap.String("postId", postId))
 		c.Error(err)
 		return
 	}
 
-	if post.UserID != user.ID {
-		ok := s.enforcePermissions(c, user.Role, "post", "write")
-		if !ok {
-			s.Logger.Debug("user has insufficient permissions", zap.String("username", user.Username))
-			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
-			return
-		}
+	if !s.enforcePostPermissions(c, user, post, actionWrite) {
+		return
 	}
 
 	var request updatePostRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		s.Logger.Debug("json is invalid", zap.Error(err))
-		c.Error(ErrInvalidJSON)
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
+	before := post
+
 	if request.Title != nil {
 		post.Title = *request.Title
 	}
@@ -294,30 +414,57 @@ func (s *Server) editPostHandler(c *gin.Context) {
 		post.Body = *request.Body
 	}
 
-	post.Title = strings.TrimSpace(post.Title)
+	if request.CanonicalURL != nil {
+		post.CanonicalURL = strings.TrimSpace(*request.CanonicalURL)
+	}
 
-	v := validator.New()
-	v.RequiredMax("title", post.Title, maxTitleLength)
+	if request.License != nil {
+		post.License = *request.License
+	}
 
-	ok, errors := v.IsValid()
+	post.Title = strings.TrimSpace(post.Title)
+
+	// the edited post has to pass the same rules as a new one
+	ok, errors := s.validator(c).ValidateStruct(createPostRequest{Title: post.Title, Body: post.Body, CanonicalURL: post.CanonicalURL, License: post.License})
 	if !ok {
-		s.Logger.Debug("input is invalid", zap.Strings("error", errors))
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors})
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
 		return
 	}
 
-	updatedPost, err := s.PostRepository.UpdatePost(post)
+	updatedPost, err := s.PostRepository.UpdatePost(c.Request.Context(), post)
 	if err != nil {
-		s.Logger.Error("couldn't update post", zap.Error(err))
-		s.internalServerErrorResponse(c)
+		s.logger(c).Debug("couldn't update post", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
+	s.recordHistory(&user.ID, objectPost, updatedPost.ID, newAuditPost(updatedPost))
+	s.enqueueMentions(c.Request.Context(), s.logger(c), updatedPost)
+
+	// edits by co-authors aren't privileged, only moderators editing someone else's post are audited
+	if post.UserID != user.ID {
+		coAuthor, err := s.isCoAuthor(c.Request.Context(), user, post)
+		if err != nil {
+			s.logger(c).Error("couldn't check co-authors", zap.Error(err), zap.Int("postId", post.ID))
+		}
+
+		if !coAuthor {
+			s.audit(c, auditActionPostEdit, objectPost, post.ID, newAuditPost(before), newAuditPost(updatedPost))
+		}
+	}
+
 	response := updatePostResponse{
-		ID:    updatedPost.ID,
-		Title: updatedPost.Title,
-		Body:  updatedPost.Body,
+		ID:           updatedPost.PublicID,
+		Title:        updatedPost.Title,
+		Body:         updatedPost.Body,
+		Status:       updatedPost.Status,
+		CanonicalURL: updatedPost.CanonicalURL,
+		License:      updatedPost.License,
+		CreatedAt:    updatedPost.CreatedAt,
+		UpdatedAt:    updatedPost.UpdatedAt,
+		Links:        s.postLinks(c, updatedPost.PublicID, ""),
 	}
 
-	c.JSON(http.StatusOK, response)
+	s.respond(c, http.StatusOK, response)
 }
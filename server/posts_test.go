@@ -0,0 +1,159 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/XiovV/blog-api/pkg/spam"
+	"github.com/XiovV/blog-api/server/mocks"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newPostRouter routes POST /posts to createPostHandler as the user, without going through userAuth.
+func newPostRouter(s *Server, user repository.User) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.Use(s.errorHandler())
+	router.POST("/posts", func(c *gin.Context) {
+		c.Set("user", user)
+	}, s.createPostHandler)
+
+	return router
+}
+
+func TestCreatePostHandler(t *testing.T) {
+	user := repository.User{ID: 1, PublicID: "user-public-id", Username: "author", Role: "user", Active: true}
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		body       string
+		setup      func(posts *mocks.MockPostStore, history *mocks.MockHistoryStore)
+		wantStatus int
+		wantCode   string
+		wantPost   createPostResponse
+	}{
+		{
+			name: "post flagged as spam is held",
+			body: `{"title": "  Buy now  ", "body": "cheap pills"}`,
+			setup: func(posts *mocks.MockPostStore, history *mocks.MockHistoryStore) {
+				posts.EXPECT().InsertPost(gomock.Any(), repository.Post{
+					UserID: user.ID,
+					Title:  "Buy now",
+					Body:   "cheap pills",
+					Status: repository.PostStatusHeld,
+				}).Return(repository.Post{
+					ID:        7,
+					PublicID:  "post-public-id",
+					UserID:    user.ID,
+					Title:     "Buy now",
+					Body:      "cheap pills",
+					Status:    repository.PostStatusHeld,
+					CreatedAt: createdAt,
+					UpdatedAt: createdAt,
+				}, nil)
+
+				history.EXPECT().InsertChange(gomock.Any(), gomock.Any()).DoAndReturn(func(_ any, change repository.RecordChange) error {
+					if change.RecordType != objectPost || change.RecordID != 7 || *change.ActorID != user.ID {
+						t.Errorf("unexpected history record %+v", change)
+					}
+
+					return nil
+				})
+			},
+			wantStatus: http.StatusCreated,
+			wantPost: createPostResponse{
+				ID:        "post-public-id",
+				Title:     "Buy now",
+				Body:      "cheap pills",
+				Status:    repository.PostStatusHeld,
+				CreatedAt: createdAt,
+				UpdatedAt: createdAt,
+			},
+		},
+		{
+			name:       "title too long",
+			body:       `{"title": "` + strings.Repeat("a", 257) + `", "body": "cheap pills"}`,
+			setup:      func(posts *mocks.MockPostStore, history *mocks.MockHistoryStore) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeValidationFailed,
+		},
+		{
+			name:       "unknown field",
+			body:       `{"title": "title", "body": "body", "author": "someone"}`,
+			setup:      func(posts *mocks.MockPostStore, history *mocks.MockHistoryStore) {},
+			wantStatus: http.StatusBadRequest,
+			wantCode:   codeInvalidInput,
+		},
+		{
+			name: "repository error",
+			body: `{"title": "title", "body": "pills"}`,
+			setup: func(posts *mocks.MockPostStore, history *mocks.MockHistoryStore) {
+				posts.EXPECT().InsertPost(gomock.Any(), gomock.Any()).Return(repository.Post{}, errors.New("connection refused"))
+			},
+			wantStatus: http.StatusInternalServerError,
+			wantCode:   codeInternalError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			posts := mocks.NewMockPostStore(ctrl)
+			history := mocks.NewMockHistoryStore(ctrl)
+			tt.setup(posts, history)
+
+			s := newTestServer(t)
+			s.PostRepository = posts
+			s.HistoryRepository = history
+			// every post mentioning pills is spam, held posts aren't published through the job queue
+			s.SpamChecker = spam.NewHeuristic(10, []string{"pills"})
+
+			request := httptest.NewRequest(http.MethodPost, "/posts", strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", "application/json")
+
+			recorder := httptest.NewRecorder()
+			newPostRouter(s, user).ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d, body %s", recorder.Code, tt.wantStatus, recorder.Body)
+			}
+
+			if tt.wantCode != "" {
+				var response struct {
+					Error apiError `json:"error"`
+				}
+
+				err := json.Unmarshal(recorder.Body.Bytes(), &response)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if response.Error.Code != tt.wantCode {
+					t.Errorf("code = %q, want %q", response.Error.Code, tt.wantCode)
+				}
+
+				return
+			}
+
+			var response createPostResponse
+			err := json.Unmarshal(recorder.Body.Bytes(), &response)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			response.Links = nil
+			if response != tt.wantPost {
+				t.Errorf("response = %+v, want %+v", response, tt.wantPost)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package server
+
+import (
+	"context"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+var queryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "blog_api",
+	Name:      "db_query_duration_seconds",
+	Help:      "Duration of database queries by the repository method which made them.",
+	Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"method", "status"})
+
+type requestQueriesKey struct{}
+
+// requestQueries collects the queries made while handling a request, so slow requests can be
+// explained by the queries they made.
+type requestQueries struct {
+	requestId string
+
+	mu      sync.Mutex
+	count   int
+	total   time.Duration
+	slowest repository.QueryStats
+}
+
+func (q *requestQueries) add(stats repository.QueryStats) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.count++
+	q.total += stats.Duration
+
+	if stats.Duration > q.slowest.Duration {
+		q.slowest = stats
+	}
+}
+
+func (q *requestQueries) fields() []zap.Field {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	fields := []zap.Field{zap.Int("queryCount", q.count), zap.Duration("queryTime", q.total)}
+	if q.count > 0 {
+		fields = append(fields, zap.String("slowestQueryMethod", q.slowest.Method), zap.Duration("slowestQueryDuration", q.slowest.Duration))
+	}
+
+	return fields
+}
+
+// trackQueries attaches a requestQueries to the request's context, it has to run after requestID.
+func (s *Server) trackQueries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		queries := &requestQueries{requestId: s.getRequestIDFromContext(c)}
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), requestQueriesKey{}, queries))
+
+		c.Next()
+	}
+}
+
+func queriesFromContext(ctx context.Context) *requestQueries {
+	queries, _ := ctx.Value(requestQueriesKey{}).(*requestQueries)
+	return queries
+}
+
+// ObserveQuery records the query's duration and logs it if it's slower than SlowQueryThreshold.
+// It's meant to be set as the repository.QueryObserver of the database.
+func (s *Server) ObserveQuery(ctx context.Context, stats repository.QueryStats) {
+	status := "ok"
+	if stats.Err != nil {
+		status = "error"
+	}
+
+	queryDuration.WithLabelValues(stats.Method, status).Observe(stats.Duration.Seconds())
+
+	queries := queriesFromContext(ctx)
+	if queries != nil {
+		queries.add(stats)
+	}
+
+	if s.Config.SlowQueryThreshold <= 0 || stats.Duration <= s.Config.SlowQueryThreshold {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("method", stats.Method),
+		zap.String("query", stats.Query),
+		zap.Duration("duration", stats.Duration),
+		zap.Duration("threshold", s.Config.SlowQueryThreshold),
+	}
+
+	if queries != nil {
+		fields = append(fields, zap.String("requestId", queries.requestId))
+	}
+
+	if stats.Err != nil {
+		fields = append(fields, zap.Error(stats.Err))
+	}
+
+	s.Logger.Warn("slow query", fields...)
+}
@@ -0,0 +1,282 @@
+package server
+
+import (
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	maxReportDetailsLength = 1000
+	maxReviewNoteLength    = 500
+)
+
+var reportStatuses = []string{repository.ReportStatusOpen, repository.ReportStatusResolved, repository.ReportStatusDismissed}
+
+type reportPostRequest struct {
+	Reason  string `json:"reason" enums:"spam,harassment,hate,violence,sexual,misinformation,other" validate:"required,oneof=spam harassment hate violence sexual misinformation other"`
+	Details string `json:"details" validate:"max=maxReportDetailsLength"`
+}
+
+type reviewReportRequest struct {
+	Note string `json:"note" validate:"max=maxReviewNoteLength"`
+}
+
+type reportResponse struct {
+	ID     int    `json:"id"`
+	Reason string `json:"reason"`
+	// Details are shown to moderators only.
+	Details string `json:"details,omitempty"`
+	Status  string `json:"status" enums:"open,resolved,dismissed"`
+	// PostID and PostTitle are null once the post has been deleted.
+	PostID           *string    `json:"post_id"`
+	PostTitle        *string    `json:"post_title,omitempty"`
+	ReporterUsername *string    `json:"reporter_username,omitempty"`
+	ReviewNote       string     `json:"review_note,omitempty"`
+	CreatedAt        time.Time  `json:"created_at"`
+	ReviewedAt       *time.Time `json:"reviewed_at"`
+}
+
+func newReportResponse(report repository.Report) reportResponse {
+	return reportResponse{
+		ID:               report.ID,
+		Reason:           report.Reason,
+		Details:          report.Details,
+		Status:           report.Status,
+		PostID:           report.PostPublicID,
+		PostTitle:        report.PostTitle,
+		ReporterUsername: report.ReporterUsername,
+		ReviewNote:       report.ReviewNote,
+		CreatedAt:        report.CreatedAt,
+		ReviewedAt:       report.ReviewedAt,
+	}
+}
+
+// @Summary Reports a post to the moderators.
+// @Tags post
+// @Accept json
+// @Produce json
+// @Param postId path string true "post id"
+// @Param request body reportPostRequest true "Report body"
+// @Security ApiKeyAuth
+// @Success 201 {object} reportResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the user can't see the post"
+// @Failure 404 {object} errorResponse "A post with the provided id doesn't exist"
+// @Failure 409 {object} errorResponse "The user has already reported the post"
+// @Failure 500 {object} errorResponse
+// @Router /posts/{postId}/report [post]
+func (s *Server) reportPostHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	postId, err := s.publicIDParam(c, "postId")
+	if err != nil {
+		s.logger(c).Debug("post id is invalid", zap.String("postId", c.Param("postId")))
+		s.badRequestResponse(c, err.Error())
+		return
+	}
+
+	var request reportPostRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	request.Details = strings.TrimSpace(request.Details)
+
+	ok, errors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	post, err := s.PostRepository.FindPostByPublicID(c.Request.Context(), postId)
+	if err != nil {
+		s.logger(c).Debug("post could not be found", zap.Error(err), zap.String("postId", postId))
+		c.Error(err)
+		return
+	}
+
+	hidden, err := s.isPostHidden(c.Request.Context(), post, user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't check the post's visibility", zap.Error(err), zap.String("postId", postId))
+		c.Error(err)
+		return
+	}
+
+	if hidden {
+		s.logger(c).Debug("post is hidden from the user", zap.String("postId", postId))
+		c.Error(repository.ErrPostNotFound)
+		return
+	}
+
+	if post.OrganizationID != nil && !s.enforcePostPermissions(c, user, post, actionRead) {
+		return
+	}
+
+	if post.UserID == user.ID {
+		c.Error(ErrInvalidInput{"you can't report your own post"})
+		return
+	}
+
+	report, err := s.ReportRepository.InsertReport(c.Request.Context(), repository.Report{
+		PostID:     &post.ID,
+		ReporterID: &user.ID,
+		Reason:     request.Reason,
+		Details:    request.Details,
+	})
+	if err != nil {
+		s.logger(c).Debug("couldn't insert report", zap.Error(err), zap.Int("postId", post.ID))
+		c.Error(err)
+		return
+	}
+
+	s.logger(c).Info("post reported", zap.Int("reportId", report.ID), zap.Int("postId", post.ID), zap.String("reason", report.Reason))
+
+	report.PostPublicID = &post.PublicID
+
+	response := newReportResponse(report)
+	response.Details = ""
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// @Summary Returns reported posts for review, oldest first, along with the amount of open reports.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int32 true "page"
+// @Param limit query int32 true "limit"
+// @Param status query string false "only return reports in this state: open, resolved or dismissed"
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[reportResponse]
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/reports [get]
+func (s *Server) getReportsHandler(c *gin.Context) {
+	page, limit, err := s.validatePageAndLimit(c)
+	if err != nil {
+		s.logger(c).Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" {
+		v := s.validator(c)
+		v.OneOf("status", status, reportStatuses...)
+
+		ok, errors := v.IsValid()
+		if !ok {
+			s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+			s.validationErrorResponse(c, errors)
+			return
+		}
+	}
+
+	open, err := s.ReportRepository.CountOpenReports(c.Request.Context())
+	if err != nil {
+		s.logger(c).Debug("couldn't count open reports", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	reports, err := s.ReportRepository.FindReports(c.Request.Context(), status, page, limit)
+	if err != nil {
+		s.logger(c).Debug("couldn't find reports", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	data := []reportResponse{}
+	for _, report := range reports {
+		data = append(data, newReportResponse(report))
+	}
+
+	response := newListResponse(s, c, data, page, limit)
+	response.Meta.Counts = map[string]int{"open": open}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary Marks a report as resolved after the reported post has been dealt with.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param reportId path int true "report id"
+// @Param request body reviewReportRequest false "Review body"
+// @Security ApiKeyAuth
+// @Success 200 {object} reportResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "There is no open report with the provided id"
+// @Failure 500 {object} errorResponse
+// @Router /admin/reports/{reportId}/resolve [post]
+func (s *Server) resolveReportHandler(c *gin.Context) {
+	s.reviewReport(c, repository.ReportStatusResolved, auditActionReportResolve)
+}
+
+// @Summary Dismisses a report which doesn't require any action.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param reportId path int true "report id"
+// @Param request body reviewReportRequest false "Review body"
+// @Security ApiKeyAuth
+// @Success 200 {object} reportResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "There is no open report with the provided id"
+// @Failure 500 {object} errorResponse
+// @Router /admin/reports/{reportId}/dismiss [post]
+func (s *Server) dismissReportHandler(c *gin.Context) {
+	s.reviewReport(c, repository.ReportStatusDismissed, auditActionReportDismiss)
+}
+
+func (s *Server) reviewReport(c *gin.Context, status, auditAction string) {
+	reportId, err := strconv.Atoi(c.Param("reportId"))
+	if err != nil {
+		s.logger(c).Debug("report id not an integer", zap.String("reportId", c.Param("reportId")))
+		s.badRequestResponse(c, "report id must be an integer")
+		return
+	}
+
+	// the note is optional, so an empty body is fine
+	var request reviewReportRequest
+	if c.Request.ContentLength != 0 {
+		if err := s.bindJSON(c, &request); err != nil {
+			s.logger(c).Debug("json is invalid", zap.Error(err))
+			c.Error(err)
+			return
+		}
+	}
+
+	request.Note = strings.TrimSpace(request.Note)
+
+	ok, errors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	reviewer := s.getUserFromContext(c)
+
+	report, err := s.ReportRepository.ReviewReport(c.Request.Context(), reportId, status, reviewer.ID, request.Note)
+	if err != nil {
+		s.logger(c).Debug("couldn't review report", zap.Error(err), zap.Int("reportId", reportId))
+		c.Error(err)
+		return
+	}
+
+	s.audit(c, auditAction, objectReport, report.ID, gin.H{"status": repository.ReportStatusOpen}, gin.H{"status": report.Status, "note": report.ReviewNote})
+
+	c.JSON(http.StatusOK, newReportResponse(report))
+}
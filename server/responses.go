@@ -2,21 +2,68 @@ package server
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"net/http"
 )
 
 func (s *Server) successResponse(c *gin.Context, msg string) {
-	c.JSON(http.StatusOK, gin.H{"message": msg})
+	c.JSON(http.StatusOK, gin.H{"message": s.translate(c, msg)})
+}
+
+// acceptedResponse is successResponse for requests which are completed in the background.
+func (s *Server) acceptedResponse(c *gin.Context, msg string) {
+	c.JSON(http.StatusAccepted, gin.H{"message": s.translate(c, msg)})
+}
+
+// errorResponse writes the error envelope, {"error": {"code": ..., "message": ...}}, with the message translated.
+func (s *Server) errorResponse(c *gin.Context, status int, code, msg string) {
+	s.writeError(c, status, apiError{Code: code, Message: s.translate(c, msg)})
+}
+
+// abortWithError is errorResponse for middleware, it stops the remaining handlers from running.
+func (s *Server) abortWithError(c *gin.Context, status int, code, msg string) {
+	c.Abort()
+	s.errorResponse(c, status, code, msg)
+}
+
+// validationErrorResponse responds with the errors of a validator, keyed by the invalid field.
+func (s *Server) validationErrorResponse(c *gin.Context, errors map[string][]string) {
+	s.writeError(c, http.StatusBadRequest, apiError{Code: codeValidationFailed, Message: s.translate(c, "input is invalid"), Fields: errors})
+}
+
+// writeError sends the error as RFC 7807 problem details to clients which ask for application/problem+json and in
+// the error envelope to everyone else.
+func (s *Server) writeError(c *gin.Context, status int, err apiError) {
+	if c.NegotiateFormat(binding.MIMEJSON, mimeProblemJSON) != mimeProblemJSON {
+		c.JSON(status, gin.H{"error": err})
+		return
+	}
+
+	// render.JSON keeps a content type which has already been set
+	c.Header("Content-Type", mimeProblemJSON)
+	c.JSON(status, problemDetails{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Message,
+		Instance: c.Request.URL.Path,
+		Code:     err.Code,
+		Fields:   err.Fields,
+	})
 }
 
 func (s *Server) badRequestResponse(c *gin.Context, msg string) {
-	c.JSON(http.StatusBadRequest, gin.H{"error": msg})
+	s.errorResponse(c, http.StatusBadRequest, codeInvalidInput, msg)
+}
+
+func (s *Server) forbiddenResponse(c *gin.Context, msg string) {
+	s.errorResponse(c, http.StatusForbidden, codeForbidden, msg)
 }
 
 func (s *Server) invalidJSONResponse(c *gin.Context) {
-	c.JSON(http.StatusBadRequest, gin.H{"error": "invalid json"})
+	s.errorResponse(c, http.StatusBadRequest, codeInvalidJSON, "invalid json")
 }
 
 func (s *Server) internalServerErrorResponse(c *gin.Context) {
-	c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+	s.errorResponse(c, http.StatusInternalServerError, codeInternalError, "internal server error")
 }
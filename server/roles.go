@@ -0,0 +1,100 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// promotionRule moves users from one role to another once they have written enough posts.
+type promotionRule struct {
+	From     string
+	To       string
+	MinPosts int
+}
+
+// parsePromotionRules parses rules in the from:to:minPosts format, e.g. user:trusted:10.
+func parsePromotionRules(rules []string) ([]promotionRule, error) {
+	var parsed []promotionRule
+
+	for _, rule := range rules {
+		parts := strings.Split(strings.TrimSpace(rule), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("promotion rule %q must be in the from:to:minPosts format", rule)
+		}
+
+		minPosts, err := strconv.Atoi(parts[2])
+		if err != nil || minPosts < 1 {
+			return nil, fmt.Errorf("promotion rule %q must have a positive post count", rule)
+		}
+
+		parsed = append(parsed, promotionRule{From: parts[0], To: parts[1], MinPosts: minPosts})
+	}
+
+	return parsed, nil
+}
+
+func (s *Server) setupRoles() error {
+	roles := []string{s.Config.DefaultRole}
+
+	rules, err := parsePromotionRules(s.Config.PromotionRules)
+	if err != nil {
+		return err
+	}
+
+	if len(rules) > 0 && s.Config.PromotionInterval <= 0 {
+		return fmt.Errorf("promotion interval must be positive")
+	}
+
+	for _, rule := range rules {
+		roles = append(roles, rule.From, rule.To)
+	}
+
+	for _, role := range roles {
+		exists, err := s.UserRepository.RoleExists(context.Background(), role)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return fmt.Errorf("role %q doesn't exist", role)
+		}
+	}
+
+	s.promotionRules = rules
+
+	return nil
+}
+
+func (s *Server) runPromotions() {
+	if len(s.promotionRules) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.Config.PromotionInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.applyPromotionRules()
+	}
+}
+
+func (s *Server) applyPromotionRules() {
+	for _, rule := range s.promotionRules {
+		users, err := s.UserRepository.PromoteUsers(context.Background(), rule.From, rule.To, rule.MinPosts)
+		if err != nil {
+			s.Logger.Error("couldn't apply promotion rule", zap.Error(err), zap.String("from", rule.From), zap.String("to", rule.To))
+			continue
+		}
+
+		for _, user := range users {
+			s.Logger.Info("user promoted", zap.Int("userId", user.ID), zap.String("username", user.Username), zap.String("from", rule.From), zap.String("to", rule.To), zap.Int("minPosts", rule.MinPosts))
+			s.recordUserHistory(nil, user.ID)
+			s.recordAudit(nil, "", auditActionUserPromote, objectUser, user.ID, gin.H{"role": rule.From}, gin.H{"role": rule.To, "min_posts": rule.MinPosts}, "")
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package server
+
+import (
+	"github.com/XiovV/blog-api/pkg/mailer"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"time"
+)
+
+// newSecurityAlert returns an alert about a change the user made with the current request.
+func newSecurityAlert(c *gin.Context, user repository.User, event string) mailer.SecurityAlert {
+	return mailer.SecurityAlert{
+		Username:  user.Username,
+		Event:     event,
+		IP:        c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+		Time:      time.Now().UTC(),
+	}
+}
+
+// sendSecurityAlert emails the alert to the user, unless their address isn't verified or they turned security emails
+// off. Failures are only logged, the change the alert is about has already been made.
+func (s *Server) sendSecurityAlert(c *gin.Context, user repository.User, alert mailer.SecurityAlert) {
+	if user.EmailVerifiedAt == nil {
+		return
+	}
+
+	preferences, err := s.NotificationRepository.FindNotificationPreferences(c.Request.Context(), user.ID)
+	if err != nil {
+		s.logger(c).Error("couldn't find notification preferences", zap.Error(err), zap.Int("userId", user.ID))
+		return
+	}
+
+	if !preferences.SecurityEmail {
+		return
+	}
+
+	err = s.enqueueEmail(c.Request.Context(), user, alert, nil)
+	if err != nil {
+		s.logger(c).Error("couldn't enqueue security alert", zap.Error(err), zap.Int("userId", user.ID), zap.String("event", alert.Event))
+	}
+}
+
+// alertNewDevice sends a security alert if the user has logged in before, but never with the request's user agent.
+// It has to be called before the login is recorded.
+func (s *Server) alertNewDevice(c *gin.Context, user repository.User) {
+	loggedIn, known, err := s.StatsRepository.FindLoginDevice(c.Request.Context(), user.ID, c.Request.UserAgent())
+	if err != nil {
+		s.logger(c).Error("couldn't check login device", zap.Error(err), zap.Int("userId", user.ID))
+		return
+	}
+
+	if loggedIn && !known {
+		s.sendSecurityAlert(c, user, newSecurityAlert(c, user, mailer.SecurityEventNewLogin))
+	}
+}
@@ -0,0 +1,128 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/alexedwards/argon2id"
+	"go.uber.org/zap"
+	"math/rand"
+	"strings"
+)
+
+// SeedOptions configures the demo data created by Seed, zero values only bootstrap roles and the admin.
+type SeedOptions struct {
+	Users        int
+	PostsPerUser int
+	// Password is shared by all demo users.
+	Password string
+	// RandomSeed makes the generated posts the same on every run with the same seed.
+	RandomSeed int64
+}
+
+var seedWords = strings.Fields("lorem ipsum dolor sit amet consectetur adipiscing elit sed do eiusmod tempor incididunt ut labore et dolore magna aliqua enim ad minim veniam quis nostrud exercitation ullamco laboris nisi aliquip ex ea commodo consequat")
+
+// Seed bootstraps the roles and the admin, then creates demo users named demo1, demo2, ... with posts.
+// Users that already exist are left alone, so running it again with the same options changes nothing.
+func (s *Server) Seed(options SeedOptions) error {
+	if options.Users < 0 || options.PostsPerUser < 0 {
+		return errors.New("the number of users and posts mustn't be negative")
+	}
+
+	if options.Users > 0 && len(options.Password) < 8 {
+		return errors.New("the demo password must be at least 8 characters long")
+	}
+
+	err := s.Bootstrap()
+	if err != nil {
+		return err
+	}
+
+	if options.Users == 0 {
+		return nil
+	}
+
+	// All demo users share the password, so it only has to be hashed once.
+	hash, err := argon2id.CreateHash(options.Password, s.argon2Params())
+	if err != nil {
+		return err
+	}
+
+	random := rand.New(rand.NewSource(options.RandomSeed))
+
+	created := 0
+	for i := 1; i <= options.Users; i++ {
+		// The posts are generated for existing users too, so the content of a user's posts doesn't depend on
+		// which users existed before.
+		posts := seedPosts(random, options.PostsPerUser)
+
+		username := fmt.Sprintf("demo%d", i)
+
+		_, err := s.UserRepository.FindUserByUsername(context.Background(), username)
+		if err == nil {
+			continue
+		}
+		if !errors.Is(err, repository.ErrUserNotFound) {
+			return err
+		}
+
+		err = s.seedUser(repository.User{Username: username, Email: username + "@example.com", Password: hash, Role: s.Config.DefaultRole}, posts)
+		if err != nil {
+			return fmt.Errorf("couldn't create demo user %q: %w", username, err)
+		}
+
+		created++
+	}
+
+	s.Logger.Info("seeded demo data", zap.Int("users", created), zap.Int("postsPerUser", options.PostsPerUser))
+
+	return nil
+}
+
+func (s *Server) seedUser(user repository.User, posts []repository.Post) error {
+	return s.TxManager.WithinTx(context.Background(), func(ctx context.Context) error {
+		id, err := s.UserRepository.InsertUser(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		_, err = s.UserRepository.MarkEmailVerified(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		for i := range posts {
+			posts[i].UserID = id
+		}
+
+		_, err = s.PostRepository.InsertPosts(ctx, posts)
+		return err
+	})
+}
+
+func seedPosts(random *rand.Rand, count int) []repository.Post {
+	posts := make([]repository.Post, 0, count)
+	for i := 0; i < count; i++ {
+		posts = append(posts, repository.Post{
+			Title: seedTitle(random, 3+random.Intn(5)),
+			Body:  seedSentence(random, 30+random.Intn(120)) + ".",
+		})
+	}
+
+	return posts
+}
+
+func seedSentence(random *rand.Rand, words int) string {
+	sentence := make([]string, 0, words)
+	for i := 0; i < words; i++ {
+		sentence = append(sentence, seedWords[random.Intn(len(seedWords))])
+	}
+
+	return strings.Join(sentence, " ")
+}
+
+func seedTitle(random *rand.Rand, words int) string {
+	title := seedSentence(random, words)
+	return strings.ToUpper(title[:1]) + title[1:]
+}
@@ -1,16 +1,20 @@
 package server
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
+	"fmt"
 	"github.com/XiovV/blog-api/config"
+	"github.com/XiovV/blog-api/pkg/i18n"
 	"github.com/XiovV/blog-api/pkg/mailer"
+	"github.com/XiovV/blog-api/pkg/queue"
 	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/XiovV/blog-api/pkg/spam"
 	"github.com/casbin/casbin/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"net/http"
 	"os"
+	"sync/atomic"
 )
 
 const (
@@ -20,14 +24,34 @@ const (
 )
 
 type Server struct {
-	Config         *config.Config
-	UserRepository *repository.UserRepository
-	PostRepository *repository.PostRepository
-	Logger         *zap.Logger
-	CasbinEnforcer *casbin.Enforcer
-	Mailer         *mailer.Mailer
-
-	gcm cipher.AEAD
+	Config                 *config.Config
+	UserRepository         UserStore
+	PostRepository         PostStore
+	OrganizationRepository OrganizationStore
+	ReportRepository       ReportStore
+	NotificationRepository NotificationStore
+	AuditLogRepository     AuditLogStore
+	JobRepository          JobStore
+	WebhookRepository      WebhookStore
+	SettingsRepository     SettingsStore
+	HistoryRepository      HistoryStore
+	StatsRepository        StatsStore
+	TxManager              Transactor
+	Notifications          NotificationSubscriber
+	Logger                 *zap.Logger
+	CasbinEnforcer         *casbin.Enforcer
+	// OrganizationEnforcer holds the permissions of the roles members have within an organization.
+	OrganizationEnforcer *casbin.Enforcer
+	Mailer               *mailer.Mailer
+	SpamChecker          spam.Checker
+	Queue                *queue.Queue
+
+	keyring        *keyring
+	promotionRules []promotionRule
+	latencyBudgets *latencyBudgets
+	translations   *i18n.Catalog
+
+	maintenanceState atomic.Pointer[repository.Maintenance]
 }
 
 // Run -.
@@ -40,7 +64,58 @@ type Server struct {
 // @in							header
 // @name						Authorization
 func (s *Server) Run() error {
-	err := s.setupGcm()
+	err := s.setupKeyring()
+	if err != nil {
+		return err
+	}
+
+	err = s.setupTranslations()
+	if err != nil {
+		return err
+	}
+
+	err = s.Bootstrap()
+	if err != nil {
+		return err
+	}
+
+	err = s.setupRoles()
+	if err != nil {
+		return err
+	}
+
+	go s.runPromotions()
+
+	if s.Config.TokenCleanupInterval <= 0 {
+		return fmt.Errorf("token cleanup interval must be positive")
+	}
+
+	go s.runTokenCleanup()
+
+	err = s.setupUnverifiedAccounts()
+	if err != nil {
+		return err
+	}
+
+	go s.runUnverifiedAccounts()
+
+	err = s.setupQueue()
+	if err != nil {
+		return err
+	}
+
+	if s.Config.OperatorAlertURL != "" && s.Config.AlertCheckInterval <= 0 {
+		return fmt.Errorf("alert check interval must be positive")
+	}
+
+	go s.runAlerts()
+
+	err = s.setupMaintenance()
+	if err != nil {
+		return err
+	}
+
+	err = s.setupLatencyBudgets()
 	if err != nil {
 		return err
 	}
@@ -49,12 +124,44 @@ func (s *Server) Run() error {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
+	if s.Config.BodyLogging && s.Config.Environment == PROD_ENV {
+		return fmt.Errorf("body logging can't be enabled in production")
+	}
+
+	router := s.Router()
+
+	s.Logger.Info("server listening...", zap.String("port", s.Config.Port), zap.String("env", s.Config.Environment))
+	err = http.ListenAndServe(":"+s.Config.Port, router)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Router returns the API's routes with their middleware. It only reads the config, so docs/gen can build it to check
+// the OpenAPI spec covers every route.
+func (s *Server) Router() *gin.Engine {
 	router := gin.New()
-	router.Use(gin.Logger(), gin.Recovery(), s.CORS(), s.errorHandler())
+	router.Use(s.requestID(), s.trackQueries(), s.requestLogger(), s.accessLogger(), gin.Recovery(), s.compression())
+
+	// after compression, so the bodies are logged uncompressed
+	if s.Config.BodyLogging {
+		router.Use(s.bodyLogger())
+	}
+
+	router.Use(s.CORS(), s.errorHandler(), s.maintenanceMode())
+
+	if s.Config.MetricsEnabled {
+		router.GET("/metrics", gin.WrapH(promhttp.Handler()))
+	}
 
 	v1 := router.Group("/v1")
 
 	v1.GET("/health", s.healthCheck)
+	v1.GET("/docs", s.docsHandler)
+	v1.GET("/docs/openapi.json", s.openAPIHandler)
+	v1.GET("/appearance", s.getAppearanceHandler)
+	v1.POST("/mail/events", s.mailEventsHandler)
 
 	usersPublic := v1.Group("/users")
 	{
@@ -65,57 +172,129 @@ func (s *Server) Run() error {
 		usersPublic.POST("/token/refresh", s.refreshTokenHandler)
 		usersPublic.POST("/password-reset", s.createPasswordResetToken)
 		usersPublic.PUT("/password-reset", s.resetUserPasswordHandler)
+		usersPublic.POST("/verify-email", s.verifyEmailHandler)
 	}
 
 	usersAuth := v1.Group("/users")
 	usersAuth.Use(s.userAuth)
 	{
+		usersAuth.GET("/me", s.getCurrentUserHandler)
+		usersAuth.GET("/me/notifications", s.getNotificationsHandler)
+		usersAuth.POST("/me/notifications/read", s.markNotificationsReadHandler)
+		usersAuth.GET("/me/preferences", s.getPreferencesHandler)
+		usersAuth.PUT("/me/preferences", s.updatePreferencesHandler)
+		usersAuth.PUT("/me/locale", s.setLocaleHandler)
+		usersAuth.POST("/verify-email/resend", s.resendVerificationEmailHandler)
 		usersAuth.POST("/mfa", s.setupMfaHandler)
 		usersAuth.POST("/mfa/confirm", s.confirmMfaHandler)
 		usersAuth.GET("/posts", s.getPersonalPostsHandler)
-		usersAuth.DELETE("/:userId", s.deleteUserHandler)
+		usersAuth.POST("/tokens", s.createAPITokenHandler)
+		usersAuth.GET("/tokens", s.getAPITokensHandler)
+		usersAuth.DELETE("/tokens/:tokenId", s.deleteAPITokenHandler)
+		usersAuth.DELETE("/:userId", s.requirePermission(objectUser, actionDelete), s.deleteUserHandler)
+	}
+
+	public := v1.Group("/public")
+	public.Use(s.publicTokenAuth)
+	{
+		public.GET("/posts", s.getPublicPostsHandler)
+		public.GET("/stats", s.getPublicStatsHandler)
+	}
+
+	widgets := v1.Group("/widgets")
+	widgets.Use(s.widgetCORS, s.publicTokenAuth)
+	{
+		widgets.GET("/latest-posts", s.latestPostsWidgetHandler)
+		widgets.GET("/posts/:postId", s.postCardWidgetHandler)
+	}
+
+	adminAuth := v1.Group("/admin")
+	adminAuth.Use(s.userAuth)
+	{
+		adminAuth.GET("/stats", s.requirePermission(objectStats, actionRead), s.getPlatformStatsHandler)
+		adminAuth.GET("/audit", s.requirePermission(objectAuditLog, actionRead), s.getAuditLogHandler)
+		adminAuth.GET("/history/:recordType/:recordId", s.requirePermission(objectHistory, actionRead), s.getRecordHistoryHandler)
+		adminAuth.GET("/history/:recordType/:recordId/as-of", s.requirePermission(objectHistory, actionRead), s.getRecordAsOfHandler)
+		adminAuth.POST("/mfa/reencrypt", s.requirePermission(objectUser, actionWrite), s.reencryptMfaSecretsHandler)
+		adminAuth.GET("/jobs", s.requirePermission(objectJob, actionRead), s.getJobsHandler)
+		adminAuth.POST("/jobs/:jobId/retry", s.requirePermission(objectJob, actionWrite), s.retryJobHandler)
+		adminAuth.POST("/users/bulk", s.requirePermission(objectUser, actionWrite), s.bulkModerateUsersHandler)
+		adminAuth.PUT("/users/:userId/verify", s.requirePermission(objectUser, actionWrite), s.adminVerifyEmailHandler)
+		adminAuth.PUT("/users/:userId/badge", s.requirePermission(objectUser, actionWrite), s.setVerifiedBadgeHandler)
+		adminAuth.PUT("/users/:userId/shadowban", s.requirePermission(objectShadowban, actionWrite), s.shadowbanUserHandler)
+		adminAuth.GET("/posts/held", s.requirePermission(objectPost, actionRead), s.getHeldPostsHandler)
+		adminAuth.POST("/posts/:postId/approve", s.requirePermission(objectPost, actionWrite), s.approvePostHandler)
+		adminAuth.GET("/reports", s.requirePermission(objectReport, actionRead), s.getReportsHandler)
+		adminAuth.POST("/reports/:reportId/resolve", s.requirePermission(objectReport, actionWrite), s.resolveReportHandler)
+		adminAuth.POST("/reports/:reportId/dismiss", s.requirePermission(objectReport, actionWrite), s.dismissReportHandler)
+		adminAuth.GET("/maintenance", s.requirePermission(objectMaintenance, actionRead), s.getMaintenanceHandler)
+		adminAuth.PUT("/maintenance", s.requirePermission(objectMaintenance, actionWrite), s.updateMaintenanceHandler)
+		adminAuth.PUT("/appearance", s.requirePermission(objectAppearance, actionWrite), s.updateAppearanceHandler)
+		adminAuth.POST("/webhooks", s.requirePermission(objectWebhook, actionCreate), s.createWebhookHandler)
+		adminAuth.GET("/webhooks", s.requirePermission(objectWebhook, actionRead), s.getWebhooksHandler)
+		adminAuth.DELETE("/webhooks/:webhookId", s.requirePermission(objectWebhook, actionDelete), s.deleteWebhookHandler)
+		adminAuth.GET("/webhooks/:webhookId/deliveries", s.requirePermission(objectWebhook, actionRead), s.getWebhookDeliveriesHandler)
 	}
 
 	postsAuth := v1.Group("/posts")
 	postsAuth.Use(s.userAuth)
 	{
-		postsAuth.POST("/", s.createPostHandler)
+		postsAuth.POST("/", s.requireVerifiedEmail, s.createPostHandler)
 		postsAuth.GET("/:postId", s.getPostHandler)
 		postsAuth.DELETE("/:postId", s.deletePostHandler)
 		postsAuth.GET("/user/:username", s.getUserPostsHandler)
 		postsAuth.PUT("/:postId", s.editPostHandler)
+		postsAuth.POST("/:postId/report", s.reportPostHandler)
+		postsAuth.POST("/:postId/authors", s.addPostAuthorHandler)
+		postsAuth.DELETE("/:postId/authors/:username", s.deletePostAuthorHandler)
 	}
 
-	s.Logger.Info("server listening...", zap.String("port", s.Config.Port), zap.String("env", s.Config.Environment))
-	err = http.ListenAndServe(":"+s.Config.Port, router)
-	if err != nil {
-		return err
+	organizationsAuth := v1.Group("/organizations")
+	organizationsAuth.Use(s.userAuth)
+	{
+		organizationsAuth.POST("", s.requireVerifiedEmail, s.createOrganizationHandler)
+		organizationsAuth.GET("", s.getOrganizationsHandler)
+		organizationsAuth.GET("/:organizationId", s.getOrganizationHandler)
+		organizationsAuth.DELETE("/:organizationId", s.deleteOrganizationHandler)
+		organizationsAuth.GET("/:organizationId/members", s.getOrganizationMembersHandler)
+		organizationsAuth.PUT("/:organizationId/members/:username", s.setOrganizationMemberHandler)
+		organizationsAuth.DELETE("/:organizationId/members/:username", s.deleteOrganizationMemberHandler)
+		organizationsAuth.GET("/:organizationId/posts", s.getOrganizationPostsHandler)
+		organizationsAuth.POST("/:organizationId/posts", s.requireVerifiedEmail, s.createOrganizationPostHandler)
 	}
-	return nil
-}
 
-func (s *Server) setupGcm() error {
-	block, err := aes.NewCipher([]byte(s.Config.AESKey))
-	if err != nil {
-		return err
-	}
+	return router
+}
 
-	gcm, err := cipher.NewGCM(block)
+func (s *Server) setupKeyring() error {
+	k, err := newKeyring(s.Config.AESKeyID, s.Config.AESKey, s.Config.AESPreviousKeys)
 	if err != nil {
 		return err
 	}
 
-	s.gcm = gcm
+	s.keyring = k
 
 	return nil
 }
 
+type healthResponse struct {
+	Version string `json:"version"`
+	Host    string `json:"host"`
+}
+
+// @Summary Reports that the server is up, along with its version and hostname.
+// @Tags health
+// @Produce json
+// @Success 200 {object} healthResponse
+// @Failure 500 {object} errorResponse
+// @Router /health [get]
 func (s *Server) healthCheck(c *gin.Context) {
 	hostname, err := os.Hostname()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err})
+		s.logger(c).Error("couldn't get hostname", zap.Error(err))
+		s.internalServerErrorResponse(c)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"version": "1.0.1", "host": hostname})
+	c.JSON(http.StatusOK, healthResponse{Version: "1.0.1", Host: hostname})
 }
@@ -0,0 +1,134 @@
+package server
+
+import (
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/XiovV/blog-api/pkg/spam"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// isSpam checks the post with the spam checker. Posts are published if the check fails, a broken
+// checker shouldn't stop anyone from posting.
+func (s *Server) isSpam(c *gin.Context, user repository.User, post repository.Post) bool {
+	isSpam, err := s.SpamChecker.IsSpam(c.Request.Context(), spam.Content{
+		Author:      user.Username,
+		AuthorEmail: user.Email,
+		UserIP:      c.ClientIP(),
+		UserAgent:   c.Request.UserAgent(),
+		Title:       post.Title,
+		Body:        post.Body,
+	})
+	if err != nil {
+		s.logger(c).Error("couldn't check post for spam", zap.Error(err))
+		return false
+	}
+
+	return isSpam
+}
+
+// @Summary Returns the posts held for moderation, oldest first.
+// @Description Held posts are published with POST /admin/posts/{postId}/approve and rejected by deleting them.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param page query int32 true "page"
+// @Param limit query int32 true "limit"
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[getPostResponse]
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/posts/held [get]
+func (s *Server) getHeldPostsHandler(c *gin.Context) {
+	page, limit, err := s.validatePageAndLimit(c)
+	if err != nil {
+		s.logger(c).Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
+		return
+	}
+
+	heldPosts, err := s.PostRepository.FindHeldPosts(c.Request.Context(), page, limit)
+	if err != nil {
+		s.logger(c).Debug("couldn't find held posts", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	data := []getPostResponse{}
+	for _, post := range heldPosts {
+		data = append(data, getPostResponse{
+			ID:           post.PublicID,
+			Title:        post.Title,
+			Body:         post.Body,
+			Status:       post.Status,
+			CanonicalURL: post.CanonicalURL,
+			License:      post.License,
+			CreatedAt:    post.CreatedAt,
+			UpdatedAt:    post.UpdatedAt,
+			Links:        s.postLinks(c, post.PublicID, ""),
+		})
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, c, data, page, limit))
+}
+
+// @Summary Publishes a post held for moderation.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param postId path string true "post id"
+// @Security ApiKeyAuth
+// @Success 200 {object} getPostResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "There is no held post with the provided id"
+// @Failure 500 {object} errorResponse
+// @Router /admin/posts/{postId}/approve [post]
+func (s *Server) approvePostHandler(c *gin.Context) {
+	postId, err := s.publicIDParam(c, "postId")
+	if err != nil {
+		s.logger(c).Debug("post id is invalid", zap.String("postId", c.Param("postId")))
+		s.badRequestResponse(c, err.Error())
+		return
+	}
+
+	post, err := s.PostRepository.FindPostByPublicID(c.Request.Context(), postId)
+	if err != nil {
+		s.logger(c).Debug("post could not be found", zap.Error(err), zap.String("postId", postId))
+		c.Error(err)
+		return
+	}
+
+	publishedPost, err := s.PostRepository.PublishHeldPost(c.Request.Context(), post.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't publish held post", zap.Error(err), zap.Int("postId", post.ID))
+		c.Error(err)
+		return
+	}
+
+	moderator := s.getUserFromContext(c)
+
+	s.recordHistory(&moderator.ID, objectPost, publishedPost.ID, newAuditPost(publishedPost))
+	s.audit(c, auditActionPostApprove, objectPost, publishedPost.ID, newAuditPost(post), newAuditPost(publishedPost))
+	s.enqueueMentions(c.Request.Context(), s.logger(c), publishedPost)
+
+	author, err := s.UserRepository.FindUserByID(c.Request.Context(), publishedPost.UserID)
+	if err != nil {
+		s.logger(c).Error("couldn't find the author of the approved post", zap.Error(err), zap.Int("postId", publishedPost.ID))
+	} else {
+		s.publishWebhookEvent(c.Request.Context(), s.logger(c), webhookEventPostPublished, webhookPost{ID: publishedPost.PublicID, UserID: author.PublicID, Title: publishedPost.Title, Body: publishedPost.Body})
+	}
+
+	c.JSON(http.StatusOK, getPostResponse{
+		ID:           publishedPost.PublicID,
+		Title:        publishedPost.Title,
+		Body:         publishedPost.Body,
+		Status:       publishedPost.Status,
+		CanonicalURL: publishedPost.CanonicalURL,
+		License:      publishedPost.License,
+		CreatedAt:    publishedPost.CreatedAt,
+		UpdatedAt:    publishedPost.UpdatedAt,
+		Links:        s.postLinks(c, publishedPost.PublicID, author.Username),
+	})
+}
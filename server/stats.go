@@ -0,0 +1,124 @@
+package server
+
+import (
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultStatsDays = 30
+	maxStatsDays     = 365
+)
+
+var statsIntervals = []string{repository.StatsIntervalDay, repository.StatsIntervalWeek, repository.StatsIntervalMonth}
+
+// recordLoginAttempt saves the outcome of a login for the platform stats. Failures are only logged, they must
+// not fail the login.
+func (s *Server) recordLoginAttempt(c *gin.Context, userId *int, succeeded bool) {
+	err := s.StatsRepository.InsertLoginAttempt(c.Request.Context(), userId, succeeded, c.Request.UserAgent())
+	if err != nil {
+		s.logger(c).Error("couldn't record login attempt", zap.Error(err))
+	}
+}
+
+type statsTotalsResponse struct {
+	Users          int `json:"users"`
+	Posts          int `json:"posts"`
+	Registrations  int `json:"registrations"`
+	ActiveUsers    int `json:"active_users"`
+	PostsPublished int `json:"posts_published"`
+	LoginFailures  int `json:"login_failures"`
+}
+
+type statsBucketResponse struct {
+	Start          time.Time `json:"start"`
+	Registrations  int       `json:"registrations"`
+	ActiveUsers    int       `json:"active_users"`
+	PostsPublished int       `json:"posts_published"`
+	LoginFailures  int       `json:"login_failures"`
+}
+
+type getPlatformStatsResponse struct {
+	Since    time.Time `json:"since"`
+	Interval string    `json:"interval"`
+	// Totals.Users and Totals.Posts are all-time totals, the other counts only cover the time since Since.
+	Totals statsTotalsResponse   `json:"totals"`
+	Series []statsBucketResponse `json:"series"`
+}
+
+// @Summary Returns the platform's totals and a time series of registrations, active users, published posts and failed logins.
+// @Description Active users are the users who logged in during a bucket. Login attempts are kept for LOGIN_ATTEMPT_RETENTION, so older buckets have no active users or failed logins.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param days query int32 false "how many days back the stats go, 30 by default and at most 365"
+// @Param interval query string false "bucket size of the series, day by default" Enums(day, week, month)
+// @Security ApiKeyAuth
+// @Success 200 {object} getPlatformStatsResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/stats [get]
+func (s *Server) getPlatformStatsHandler(c *gin.Context) {
+	days := defaultStatsDays
+	if c.Query("days") != "" {
+		var err error
+		days, err = strconv.Atoi(c.Query("days"))
+		if err != nil {
+			c.Error(ErrInvalidInput{"days must be an integer"})
+			return
+		}
+	}
+
+	interval := c.DefaultQuery("interval", repository.StatsIntervalDay)
+
+	v := s.validator(c)
+	v.IntRange("days", days, 1, maxStatsDays)
+	v.OneOf("interval", interval, statsIntervals...)
+
+	ok, errors := v.IsValid()
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+
+	stats, err := s.StatsRepository.FindPlatformStats(c.Request.Context(), since, interval)
+	if err != nil {
+		s.logger(c).Debug("couldn't find platform stats", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	response := getPlatformStatsResponse{
+		Since:    since,
+		Interval: interval,
+		Totals: statsTotalsResponse{
+			Users:          stats.Users,
+			Posts:          stats.Posts,
+			Registrations:  stats.Registrations,
+			ActiveUsers:    stats.ActiveUsers,
+			PostsPublished: stats.PostsPublished,
+			LoginFailures:  stats.LoginFailures,
+		},
+		Series: []statsBucketResponse{},
+	}
+
+	for _, bucket := range stats.Series {
+		response.Series = append(response.Series, statsBucketResponse{
+			Start:          bucket.Start,
+			Registrations:  bucket.Registrations,
+			ActiveUsers:    bucket.ActiveUsers,
+			PostsPublished: bucket.PostsPublished,
+			LoginFailures:  bucket.LoginFailures,
+		})
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"time"
+)
+
+//go:generate mockgen -source=stores.go -destination=mocks/stores.go -package=mocks
+
+// The stores are the persistence the Server depends on, they are implemented by the repositories in pkg/repository
+// and can be replaced with the mocks in server/mocks to test handlers without a database.
+
+type UserStore interface {
+	InsertUser(ctx context.Context, user repository.User) (int, error)
+	DeleteUserByID(ctx context.Context, userId int) error
+	InsertMfaSecret(ctx context.Context, userId int, secret []byte, recoveryCodes []string) error
+	SetPassword(ctx context.Context, userId int, password string) error
+	SetRecoveryCodes(ctx context.Context, userId int, recoveryCodes []string) error
+	SetActiveState(ctx context.Context, userId int, active bool) error
+	SetVerified(ctx context.Context, userId int, verified bool) error
+	SetLocale(ctx context.Context, userId int, locale string) error
+	FindUsersToRemind(ctx context.Context, createdBefore time.Time, limit int) ([]repository.User, error)
+	MarkVerificationReminderSent(ctx context.Context, userId int) (bool, error)
+	DeleteUnverifiedUsers(ctx context.Context, createdBefore time.Time, remindedBefore *time.Time) ([]repository.User, error)
+	SetShadowbanned(ctx context.Context, userId int, shadowbanned bool) error
+	SetRole(ctx context.Context, userId int, role string) error
+	FindUserByID(ctx context.Context, id int) (repository.User, error)
+	FindUsersByUsernames(ctx context.Context, usernames []string) ([]repository.User, error)
+	FindUserByPublicID(ctx context.Context, publicId string) (repository.User, error)
+	FindUserByUsername(ctx context.Context, username string) (repository.User, error)
+	FindUserByEmail(ctx context.Context, email string) (repository.User, error)
+	GetUserRecoveryCodes(ctx context.Context, username string) ([]string, error)
+	FindUsersWithMfaSecret(ctx context.Context) ([]repository.User, error)
+	ReplaceMfaSecret(ctx context.Context, userId int, oldSecret, secret []byte) (bool, error)
+	RoleExists(ctx context.Context, name string) (bool, error)
+	EnsureRole(ctx context.Context, name string) (bool, error)
+	CountUsersWithRole(ctx context.Context, name string) (int, error)
+	PromoteUsers(ctx context.Context, fromRole, toRole string, minPosts int) ([]repository.User, error)
+	InsertRefreshToken(ctx context.Context, token repository.RefreshToken) error
+	IsRefreshTokenBlacklisted(ctx context.Context, userId int, token string) (bool, error)
+	DeleteExpiredRefreshTokens(ctx context.Context) (int, error)
+	InsertPasswordResetToken(ctx context.Context, token repository.PasswordResetToken) error
+	GetPasswordResetToken(ctx context.Context, token string) (repository.PasswordResetToken, error)
+	DeleteAllPasswordResetTokensForUser(ctx context.Context, userId int) error
+	DeletePasswordResetToken(ctx context.Context, token string) error
+	DeleteExpiredPasswordResetTokens(ctx context.Context) (int, error)
+	InsertAPIToken(ctx context.Context, token repository.APIToken) (repository.APIToken, error)
+	FindAPITokensByUserID(ctx context.Context, userId int) ([]repository.APIToken, error)
+	UseAPIToken(ctx context.Context, tokenHash string) (repository.APIToken, error)
+	DeleteAPIToken(ctx context.Context, userId, tokenId int) error
+	InsertVerificationToken(ctx context.Context, token repository.EmailVerificationToken) error
+	GetVerificationToken(ctx context.Context, tokenHash string) (repository.EmailVerificationToken, error)
+	MarkEmailVerified(ctx context.Context, userId int) (time.Time, error)
+	MarkEmailUndeliverable(ctx context.Context, email, reason string) (int, error)
+	IsEmailUndeliverable(ctx context.Context, email string) (bool, error)
+}
+
+type PostStore interface {
+	InsertPost(ctx context.Context, post repository.Post) (repository.Post, error)
+	InsertPosts(ctx context.Context, posts []repository.Post) ([]repository.Post, error)
+	FindPostByPostID(ctx context.Context, postId int) (repository.Post, error)
+	FindPostByPublicID(ctx context.Context, publicId string) (repository.Post, error)
+	DeletePostByPostID(ctx context.Context, postId int) error
+	UpdatePost(ctx context.Context, post repository.Post) (repository.Post, error)
+	FindByUserID(ctx context.Context, userId int, options repository.PostListOptions, page, limit int) ([]repository.Post, error)
+	FindByOrganizationID(ctx context.Context, organizationId int, options repository.PostListOptions, page, limit int) ([]repository.Post, error)
+	FindLatestByUserID(ctx context.Context, userId, page, limit int) ([]repository.Post, error)
+	CountByUserID(ctx context.Context, userId int) (int, error)
+	FindHeldPosts(ctx context.Context, page, limit int) ([]repository.Post, error)
+	PublishHeldPost(ctx context.Context, postId int) (repository.Post, error)
+	SetMentions(ctx context.Context, postId int, userIds []int) ([]int, error)
+	FindPostAuthors(ctx context.Context, postId int) ([]repository.PostAuthor, error)
+	IsCoAuthor(ctx context.Context, postId, userId int) (bool, error)
+	CountCoAuthors(ctx context.Context, postId int) (int, error)
+	InsertCoAuthor(ctx context.Context, postId, userId int) error
+	DeleteCoAuthor(ctx context.Context, postId, userId int) error
+}
+
+type OrganizationStore interface {
+	InsertOrganization(ctx context.Context, organization repository.Organization, creatorId int) (repository.Organization, error)
+	FindOrganizationByPublicID(ctx context.Context, publicId string) (repository.Organization, error)
+	FindOrganizationsByUserID(ctx context.Context, userId int) ([]repository.UserOrganization, error)
+	DeleteOrganization(ctx context.Context, organizationId int) error
+	FindMemberRole(ctx context.Context, organizationId, userId int) (string, error)
+	FindMembers(ctx context.Context, organizationId int) ([]repository.OrganizationMember, error)
+	SetMember(ctx context.Context, organizationId, userId int, role string) error
+	DeleteMember(ctx context.Context, organizationId, userId int) error
+}
+
+type NotificationStore interface {
+	InsertNotification(ctx context.Context, notification repository.Notification) (repository.Notification, error)
+	FindNotifications(ctx context.Context, userId int, unreadOnly bool, page, limit int) ([]repository.Notification, error)
+	CountUnreadNotifications(ctx context.Context, userId int) (int, error)
+	MarkNotificationsRead(ctx context.Context, userId int) (int, error)
+	FindNotificationPreferences(ctx context.Context, userId int) (repository.NotificationPreferences, error)
+	UpdateNotificationPreferences(ctx context.Context, preferences repository.NotificationPreferences) (repository.NotificationPreferences, error)
+}
+
+type ReportStore interface {
+	InsertReport(ctx context.Context, report repository.Report) (repository.Report, error)
+	FindReports(ctx context.Context, status string, page, limit int) ([]repository.Report, error)
+	CountOpenReports(ctx context.Context) (int, error)
+	ReviewReport(ctx context.Context, reportId int, status string, reviewerId int, note string) (repository.Report, error)
+}
+
+type AuditLogStore interface {
+	InsertEntry(ctx context.Context, entry repository.AuditLogEntry) error
+	FindEntries(ctx context.Context, filter repository.AuditLogFilter, page, limit int) ([]repository.AuditLogEntry, error)
+}
+
+type JobStore interface {
+	ResurrectJob(ctx context.Context, jobId int) (repository.Job, error)
+	FindJobs(ctx context.Context, status string, page, limit int) ([]repository.Job, error)
+	CountJobsByStatus(ctx context.Context) (map[string]int, error)
+	CountJobsSince(ctx context.Context, jobType, status string, since time.Time) (int, error)
+	CountEmailsSince(ctx context.Context, jobType, recipient, template string, since time.Time) (int, error)
+}
+
+type WebhookStore interface {
+	InsertWebhook(ctx context.Context, webhook repository.Webhook) (repository.Webhook, error)
+	FindWebhooks(ctx context.Context) ([]repository.Webhook, error)
+	FindWebhookByID(ctx context.Context, webhookId int) (repository.Webhook, error)
+	FindWebhooksForEvent(ctx context.Context, event string) ([]repository.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookId int) error
+	InsertDelivery(ctx context.Context, delivery repository.WebhookDelivery) (repository.WebhookDelivery, error)
+	FindDeliveryByID(ctx context.Context, deliveryId int) (repository.WebhookDelivery, error)
+	RecordDeliveryAttempt(ctx context.Context, deliveryId int, status string, responseStatus *int, lastError string) error
+	FindDeliveries(ctx context.Context, webhookId int, status string, page, limit int) ([]repository.WebhookDelivery, error)
+}
+
+type SettingsStore interface {
+	GetAppearance(ctx context.Context) (repository.Appearance, error)
+	UpdateAppearance(ctx context.Context, appearance repository.Appearance) (repository.Appearance, error)
+	GetMaintenance(ctx context.Context) (repository.Maintenance, error)
+	UpdateMaintenance(ctx context.Context, maintenance repository.Maintenance) (repository.Maintenance, error)
+}
+
+type HistoryStore interface {
+	InsertChange(ctx context.Context, change repository.RecordChange) error
+	FindChanges(ctx context.Context, recordType string, recordId, page, limit int) ([]repository.RecordChange, error)
+	FindChangeAsOf(ctx context.Context, recordType string, recordId int, at time.Time) (repository.RecordChange, error)
+}
+
+type StatsStore interface {
+	InsertLoginAttempt(ctx context.Context, userId *int, succeeded bool, userAgent string) error
+	FindLoginDevice(ctx context.Context, userId int, userAgent string) (bool, bool, error)
+	DeleteLoginAttemptsBefore(ctx context.Context, before time.Time) (int, error)
+	FindPlatformStats(ctx context.Context, since time.Time, interval string) (repository.PlatformStats, error)
+}
+
+// Transactor runs a function atomically, stores called with the context passed to fn take part in the transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NotificationSubscriber delivers the notifications instances send each other when data they may hold in memory changes.
+type NotificationSubscriber interface {
+	Subscribe(channel string, handler func(payload string)) error
+}
@@ -0,0 +1,269 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	apiTokenPrefix     = "pub_"
+	apiTokenBytes      = 24
+	apiTokenOwnerIdKey = "apiTokenOwnerId"
+	maxAPITokenName    = 100
+)
+
+// hashToken hashes tokens which are stored in the database, only the hash is stored so a
+// leaked database doesn't leak usable tokens.
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+type createAPITokenRequest struct {
+	Name string `json:"name" validate:"required,max=maxAPITokenName"`
+}
+
+type apiTokenResponse struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+type createAPITokenResponse struct {
+	apiTokenResponse
+	// the token is only returned once, when it's created
+	Token string `json:"token"`
+}
+
+func newAPITokenResponse(token repository.APIToken) apiTokenResponse {
+	return apiTokenResponse{ID: token.ID, Name: token.Name, CreatedAt: token.CreatedAt, LastUsedAt: token.LastUsedAt}
+}
+
+// @Summary Creates a read-only public token which can be used to read the user's posts, e.g. from widgets embedded on other sites.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body createAPITokenRequest true "Create api token body"
+// @Security ApiKeyAuth
+// @Success 201 {object} createAPITokenResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /users/tokens [post]
+func (s *Server) createAPITokenHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	var request createAPITokenRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	request.Name = strings.TrimSpace(request.Name)
+
+	ok, errors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	secret, err := generateSecureToken(apiTokenBytes)
+	if err != nil {
+		s.logger(c).Error("couldn't generate api token", zap.Error(err))
+		s.internalServerErrorResponse(c)
+		return
+	}
+
+	token := apiTokenPrefix + secret
+
+	apiToken, err := s.UserRepository.InsertAPIToken(c.Request.Context(), repository.APIToken{
+		UserID:    user.ID,
+		Name:      request.Name,
+		TokenHash: hashToken(token),
+	})
+	if err != nil {
+		s.logger(c).Debug("couldn't insert api token", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, createAPITokenResponse{newAPITokenResponse(apiToken), token})
+}
+
+// @Summary Returns the user's public tokens.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[apiTokenResponse]
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /users/tokens [get]
+func (s *Server) getAPITokensHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	tokens, err := s.UserRepository.FindAPITokensByUserID(c.Request.Context(), user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't find api tokens", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	data := []apiTokenResponse{}
+	for _, token := range tokens {
+		data = append(data, newAPITokenResponse(token))
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, c, data, 0, 0))
+}
+
+// @Summary Revokes a public token.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param tokenId path int true "token id"
+// @Security ApiKeyAuth
+// @Success 200 "Token revoked successfully"
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 404 {object} errorResponse "The user doesn't have a token with the provided id"
+// @Failure 500 {object} errorResponse
+// @Router /users/tokens/{tokenId} [delete]
+func (s *Server) deleteAPITokenHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	tokenId, err := strconv.Atoi(c.Param("tokenId"))
+	if err != nil {
+		s.logger(c).Debug("tokenId not an integer", zap.String("tokenId", c.Param("tokenId")))
+		s.badRequestResponse(c, "tokenId must be an integer")
+		return
+	}
+
+	err = s.UserRepository.DeleteAPIToken(c.Request.Context(), user.ID, tokenId)
+	if err != nil {
+		s.logger(c).Debug("couldn't delete api token", zap.Error(err), zap.Int("tokenId", tokenId))
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// publicTokenAuth authenticates requests made with a public token, passed either
+// in the Authorization header or in the token query parameter.
+func (s *Server) publicTokenAuth(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token, _ = s.validateAuthorizationHeader(c)
+	}
+
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		s.logger(c).Debug("public token missing")
+		s.abortWithError(c, http.StatusForbidden, codeInvalidToken, "invalid token")
+		return
+	}
+
+	apiToken, err := s.UserRepository.UseAPIToken(c.Request.Context(), hashToken(token))
+	if err != nil {
+		s.logger(c).Debug("couldn't find public token", zap.Error(err))
+		s.abortWithError(c, http.StatusForbidden, codeInvalidToken, "invalid token")
+		return
+	}
+
+	c.Set(apiTokenOwnerIdKey, apiToken.UserID)
+	s.addLoggerFields(c, zap.Int("apiTokenId", apiToken.ID), zap.Int("apiTokenOwnerId", apiToken.UserID))
+
+	c.Next()
+}
+
+// @Summary Returns the posts of the public token's owner, newest first.
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param token query string true "public token"
+// @Param page query int32 true "page"
+// @Param limit query int32 true "limit"
+// @Param fields query string false "comma separated fields to return for each post, e.g. id,title,created_at"
+// @Success 200 {object} listResponse[personalPosts]
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The public token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /public/posts [get]
+func (s *Server) getPublicPostsHandler(c *gin.Context) {
+	page, limit, err := s.validatePageAndLimit(c)
+	if err != nil {
+		s.logger(c).Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
+		return
+	}
+
+	fields, err := s.parseFields(c, personalPosts{})
+	if err != nil {
+		s.logger(c).Debug("invalid fields", zap.Error(err), zap.String("fields", c.Query("fields")))
+		c.Error(ErrInvalidInput{err.Error()})
+		return
+	}
+
+	userPosts, err := s.PostRepository.FindLatestByUserID(c.Request.Context(), c.GetInt(apiTokenOwnerIdKey), page, limit)
+	if err != nil {
+		s.logger(c).Debug("couldn't find posts", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	posts := []personalPosts{}
+	for _, post := range userPosts {
+		posts = append(posts, personalPosts{
+			ID:           post.PublicID,
+			Title:        post.Title,
+			Body:         post.Body,
+			Status:       post.Status,
+			CanonicalURL: post.CanonicalURL,
+			License:      post.License,
+			CreatedAt:    post.CreatedAt,
+			UpdatedAt:    post.UpdatedAt,
+			Links:        s.postLinks(c, post.PublicID, ""),
+		})
+	}
+
+	if fields != nil {
+		s.respond(c, http.StatusOK, newListResponse(s, c, filterList(fields, posts), page, limit))
+		return
+	}
+
+	s.respond(c, http.StatusOK, newListResponse(s, c, posts, page, limit))
+}
+
+type getPublicStatsResponse struct {
+	Posts int `json:"posts"`
+}
+
+// @Summary Returns statistics of the public token's owner.
+// @Tags public
+// @Accept json
+// @Produce json
+// @Param token query string true "public token"
+// @Success 200 {object} getPublicStatsResponse
+// @Failure 403 {object} errorResponse "The public token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /public/stats [get]
+func (s *Server) getPublicStatsHandler(c *gin.Context) {
+	count, err := s.PostRepository.CountByUserID(c.Request.Context(), c.GetInt(apiTokenOwnerIdKey))
+	if err != nil {
+		s.logger(c).Debug("couldn't count posts", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, getPublicStatsResponse{Posts: count})
+}
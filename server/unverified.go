@@ -0,0 +1,140 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/mailer"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"go.uber.org/zap"
+	"time"
+)
+
+const (
+	// verificationRemindersPerRun caps the reminders queued at once, the rest are sent on the next runs.
+	verificationRemindersPerRun = 500
+)
+
+// setupUnverifiedAccounts checks the settings of the verification reminders and the deletion of unverified accounts,
+// either of which is turned off by setting it to 0.
+func (s *Server) setupUnverifiedAccounts() error {
+	if s.Config.VerificationReminderAfter < 0 || s.Config.UnverifiedAccountRetention < 0 {
+		return fmt.Errorf("verification reminder and unverified account retention can't be negative")
+	}
+
+	if s.Config.VerificationReminderAfter == 0 && s.Config.UnverifiedAccountRetention == 0 {
+		return nil
+	}
+
+	if s.Config.UnverifiedAccountInterval <= 0 {
+		return fmt.Errorf("unverified account interval must be positive")
+	}
+
+	if s.Config.UnverifiedAccountRetention > 0 && s.Config.VerificationReminderAfter >= s.Config.UnverifiedAccountRetention {
+		return fmt.Errorf("verification reminder must be sent before unverified accounts are deleted")
+	}
+
+	return nil
+}
+
+// runUnverifiedAccounts periodically reminds users who haven't verified their address after VERIFICATION_REMINDER_AFTER
+// and deletes the accounts which are still unverified after UNVERIFIED_ACCOUNT_RETENTION. Users are claimed before
+// they're reminded or deleted, so every instance can run it.
+func (s *Server) runUnverifiedAccounts() {
+	if s.Config.VerificationReminderAfter == 0 && s.Config.UnverifiedAccountRetention == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.Config.UnverifiedAccountInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if s.Config.VerificationReminderAfter > 0 {
+			s.remindUnverifiedUsers()
+		}
+
+		if s.Config.UnverifiedAccountRetention > 0 {
+			s.deleteUnverifiedUsers()
+		}
+	}
+}
+
+func (s *Server) remindUnverifiedUsers() {
+	users, err := s.UserRepository.FindUsersToRemind(context.Background(), time.Now().Add(-s.Config.VerificationReminderAfter), verificationRemindersPerRun)
+	if err != nil {
+		s.Logger.Error("couldn't find users to remind", zap.Error(err))
+		return
+	}
+
+	for _, user := range users {
+		reminded, err := s.sendVerificationReminder(context.Background(), user)
+		if err != nil {
+			s.Logger.Error("couldn't send verification reminder", zap.Error(err), zap.Int("userId", user.ID))
+			continue
+		}
+
+		if !reminded {
+			continue
+		}
+
+		s.Logger.Info("verification reminder sent", zap.Int("userId", user.ID), zap.String("username", user.Username))
+		s.recordAudit(nil, "", auditActionUserRemind, objectUser, user.ID, nil, nil, "")
+	}
+}
+
+// sendVerificationReminder emails the user a new verification token, together with the date their account is deleted
+// if UNVERIFIED_ACCOUNT_RETENTION is set. It returns false if the user has been reminded already.
+func (s *Server) sendVerificationReminder(ctx context.Context, user repository.User) (bool, error) {
+	var reminded bool
+
+	err := s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		reminded, err = s.UserRepository.MarkVerificationReminderSent(ctx, user.ID)
+		if err != nil || !reminded {
+			return err
+		}
+
+		token, expiry, err := s.createVerificationToken(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		reminder := mailer.VerificationReminder{Username: user.Username, Token: token}
+		if s.Config.UnverifiedAccountRetention > 0 {
+			// see deleteUnverifiedUsers, users reminded late get the same notice as everybody else
+			deleteAt := user.CreatedAt.Add(s.Config.UnverifiedAccountRetention)
+			if notice := time.Now().Add(s.Config.UnverifiedAccountRetention - s.Config.VerificationReminderAfter); notice.After(deleteAt) {
+				deleteAt = notice
+			}
+
+			deleteAt = deleteAt.UTC()
+			reminder.DeleteAt = &deleteAt
+		}
+
+		return s.enqueueEmail(ctx, user, reminder, &expiry)
+	})
+
+	return reminded, err
+}
+
+func (s *Server) deleteUnverifiedUsers() {
+	// with reminders turned on, users are only deleted once UNVERIFIED_ACCOUNT_RETENTION - VERIFICATION_REMINDER_AFTER
+	// has passed since they were reminded, so users who registered before reminders were turned on aren't deleted
+	// without notice
+	var remindedBefore *time.Time
+	if s.Config.VerificationReminderAfter > 0 {
+		before := time.Now().Add(s.Config.VerificationReminderAfter - s.Config.UnverifiedAccountRetention)
+		remindedBefore = &before
+	}
+
+	users, err := s.UserRepository.DeleteUnverifiedUsers(context.Background(), time.Now().Add(-s.Config.UnverifiedAccountRetention), remindedBefore)
+	if err != nil {
+		s.Logger.Error("couldn't delete unverified users", zap.Error(err))
+		return
+	}
+
+	for _, user := range users {
+		s.Logger.Info("unverified user deleted", zap.Int("userId", user.ID), zap.String("username", user.Username))
+		s.recordHistory(nil, objectUser, user.ID, nil)
+		s.recordAudit(nil, "", auditActionUserPurge, objectUser, user.ID, newAuditUser(user), nil, "email address not verified within UNVERIFIED_ACCOUNT_RETENTION")
+	}
+}
@@ -1,36 +1,32 @@
 package server
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/mailer"
 	"github.com/XiovV/blog-api/pkg/repository"
-	"github.com/XiovV/blog-api/pkg/validator"
 	"github.com/alexedwards/argon2id"
 	"github.com/gin-gonic/gin"
 	"github.com/pquerna/otp/totp"
 	"go.uber.org/zap"
 	"net/http"
-	"net/mail"
-	"strconv"
 	"strings"
 	"time"
 )
 
 const (
-	totpSecretLength = 32
-	totpCodeLength   = 6
+	minUsernameLength = 3
+	maxUsernameLength = 50
+	minPasswordLength = 8
+	totpSecretLength  = 32
+	totpCodeLength    = 6
 )
 
-var argon2Params = argon2id.Params{
-	Memory:      128 * 1024,
-	Iterations:  10,
-	Parallelism: 4,
-	SaltLength:  16,
-	KeyLength:   32,
-}
-
 type registerRequest struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"min=minUsernameLength,max=maxUsernameLength"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"min=minPasswordLength"`
 }
 
 // @Summary Registers a user into the platform if the username and email haven't already been taken.
@@ -45,69 +41,82 @@ type registerRequest struct {
 // @Router /users/register [post]
 func (s *Server) registerUserHandler(c *gin.Context) {
 	var request registerRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		s.Logger.Debug("json is invalid", zap.Error(err))
-		c.Error(ErrInvalidJSON)
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
 	request.Username = strings.TrimSpace(request.Username)
 	request.Email = strings.TrimSpace(request.Email)
 
-	v := validator.New()
-
-	v.RequiredRange("username", request.Username, 3, 50)
-	v.RequiredMin("password", request.Password, 8)
-
-	ok, errors := v.IsValid()
+	ok, errors := s.validator(c).ValidateStruct(request)
 	if !ok {
-		s.Logger.Debug("input invalid", zap.Strings("err", errors))
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors})
-		return
-	}
-
-	_, err := mail.ParseAddress(request.Email)
-	if err != nil {
-		s.Logger.Debug("email is invalid", zap.String("email", request.Email))
-		s.badRequestResponse(c, "email is invalid")
+		s.logger(c).Debug("input invalid", zap.Any("err", errors))
+		s.validationErrorResponse(c, errors)
 		return
 	}
 
-	hash, err := argon2id.CreateHash(request.Password, &argon2Params)
+	hash, err := argon2id.CreateHash(request.Password, s.argon2Params())
 	if err != nil {
-		s.Logger.Error("couldn't hash password", zap.Error(err))
+		s.logger(c).Error("couldn't hash password", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
-	newUser := repository.User{Username: request.Username, Email: request.Email, Password: hash}
-	id, err := s.UserRepository.InsertUser(newUser)
+	newUser := repository.User{Username: request.Username, Email: request.Email, Password: hash, Role: s.Config.DefaultRole, Locale: s.translator(c).Language}
+
+	// the user is only created if their verification token and emails could be queued as well,
+	// otherwise they'd be left with an account they can never verify
+	var id int
+	err = s.TxManager.WithinTx(c.Request.Context(), func(ctx context.Context) error {
+		id, err = s.UserRepository.InsertUser(ctx, newUser)
+		if err != nil {
+			return err
+		}
+
+		newUser, err = s.UserRepository.FindUserByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = s.sendVerificationEmail(ctx, newUser)
+		if err != nil {
+			return fmt.Errorf("couldn't send verification email: %w", err)
+		}
+
+		if s.Config.WelcomeEmails {
+			err = s.enqueueEmail(ctx, newUser, mailer.Welcome{Username: newUser.Username}, nil)
+			if err != nil {
+				return fmt.Errorf("couldn't enqueue welcome email: %w", err)
+			}
+		}
+
+		s.publishWebhookEvent(ctx, s.logger(c), webhookEventUserRegistered, webhookUser{ID: newUser.PublicID, Username: newUser.Username})
+
+		return nil
+	})
 	if err != nil {
-		s.Logger.Debug("couldn't insert user", zap.Error(err), zap.String("username", request.Username))
+		s.logger(c).Debug("couldn't register user", zap.Error(err), zap.String("username", request.Username))
 		c.Error(err)
 		return
 	}
 
-	accessToken, err := generateAccessToken(id)
+	accessToken, err := s.generateAccessToken(id)
 	if err != nil {
-		s.Logger.Error("couldn't generate accessToken", zap.Error(err))
+		s.logger(c).Error("couldn't generate accessToken", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
-	refreshToken, err := generateRefreshToken(id)
+	refreshToken, err := s.generateRefreshToken(id)
 	if err != nil {
-		s.Logger.Error("couldn't generate refreshToken", zap.Error(err))
+		s.logger(c).Error("couldn't generate refreshToken", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
-	go func() {
-		err = s.Mailer.Send(request.Email, "welcome_user.tmpl", newUser)
-		if err != nil {
-			s.Logger.Error("couldn't send welcome email", zap.Error(err), zap.String("username", request.Username))
-		}
-	}()
+	s.recordUserHistory(&id, id)
 
 	type registerResponse struct {
 		AccessToken  string `json:"access_token"`
@@ -118,8 +127,8 @@ func (s *Server) registerUserHandler(c *gin.Context) {
 }
 
 type loginRequest struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username string `json:"username" validate:"min=minUsernameLength,max=maxUsernameLength"`
+	Password string `json:"password" validate:"min=minPasswordLength"`
 }
 
 // @Summary Checks if the login credentials are correct and returns the access and refresh tokens.
@@ -135,62 +144,59 @@ type loginRequest struct {
 // @Router /users/login [post]
 func (s *Server) loginUserHandler(c *gin.Context) {
 	var request loginRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		s.Logger.Debug("json is invalid", zap.Error(err))
-		c.Error(ErrInvalidJSON)
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
 	request.Username = strings.TrimSpace(request.Username)
 
-	v := validator.New()
-
-	v.RequiredRange("username", request.Username, 3, 50)
-	v.RequiredMin("password", request.Password, 8)
-
-	ok, errors := v.IsValid()
+	ok, errors := s.validator(c).ValidateStruct(request)
 	if !ok {
-		s.Logger.Debug("input invalid", zap.Strings("err", errors))
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors})
+		s.logger(c).Debug("input invalid", zap.Any("err", errors))
+		s.validationErrorResponse(c, errors)
 		return
 	}
 
-	user, err := s.UserRepository.FindUserByUsername(request.Username)
+	user, err := s.UserRepository.FindUserByUsername(c.Request.Context(), request.Username)
 	if err != nil {
-		s.Logger.Debug("couldn't find user", zap.Error(err), zap.String("username", request.Username))
-		s.badRequestResponse(c, "incorrect username or password")
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("username", request.Username))
+		s.recordLoginAttempt(c, nil, false)
+		s.errorResponse(c, http.StatusBadRequest, codeInvalidCredentials, "incorrect username or password")
 		return
 	}
 
-	ok, err = argon2id.ComparePasswordAndHash(request.Password, user.Password)
+	ok, err = s.comparePassword(c.Request.Context(), user, request.Password)
 	if err != nil {
-		s.Logger.Error("couldn't check hash", zap.Error(err), zap.String("username", request.Username))
+		s.logger(c).Error("couldn't check hash", zap.Error(err), zap.String("username", request.Username))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
 	if !ok {
-		s.Logger.Debug("incorrect password", zap.String("username", request.Username))
-		s.badRequestResponse(c, "incorrect username or password")
+		s.logger(c).Debug("incorrect password", zap.String("username", request.Username))
+		s.recordLoginAttempt(c, &user.ID, false)
+		s.errorResponse(c, http.StatusBadRequest, codeInvalidCredentials, "incorrect username or password")
 		return
 	}
 
 	if len(user.MFASecret) != 0 {
-		s.Logger.Debug("user has 2fa enabled", zap.String("username", request.Username))
+		s.logger(c).Debug("user has 2fa enabled", zap.String("username", request.Username))
 		c.Status(http.StatusFound)
 		return
 	}
 
-	accessToken, err := generateAccessToken(user.ID)
+	accessToken, err := s.generateAccessToken(user.ID)
 	if err != nil {
-		s.Logger.Error("couldn't generate accessToken", zap.Error(err))
+		s.logger(c).Error("couldn't generate accessToken", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
-	refreshToken, err := generateRefreshToken(user.ID)
+	refreshToken, err := s.generateRefreshToken(user.ID)
 	if err != nil {
-		s.Logger.Error("couldn't generate refreshToken", zap.Error(err))
+		s.logger(c).Error("couldn't generate refreshToken", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
@@ -200,11 +206,14 @@ func (s *Server) loginUserHandler(c *gin.Context) {
 		RefreshToken string `json:"refresh_token"`
 	}
 
+	s.alertNewDevice(c, user)
+	s.recordLoginAttempt(c, &user.ID, true)
+
 	c.JSON(http.StatusOK, loginResponse{accessToken, refreshToken})
 }
 
 type mfaLoginRequest struct {
-	Username string `json:"username"`
+	Username string `json:"username" validate:"max=maxUsernameLength"`
 	Password string `json:"password"`
 	TOTP     string `json:"totp"`
 }
@@ -221,75 +230,74 @@ type mfaLoginRequest struct {
 // @Router /users/login/mfa [post]
 func (s *Server) loginUserMfaHandler(c *gin.Context) {
 	var request mfaLoginRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		s.Logger.Debug("json is invalid", zap.Error(err))
-		c.Error(ErrInvalidJSON)
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
 	request.Username = strings.TrimSpace(request.Username)
 
-	v := validator.New()
-
-	v.RequiredMax("username", request.Username, 50)
-
-	ok, errors := v.IsValid()
+	ok, errors := s.validator(c).ValidateStruct(request)
 	if !ok {
-		s.Logger.Debug("input invalid", zap.Strings("err", errors))
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors})
+		s.logger(c).Debug("input invalid", zap.Any("err", errors))
+		s.validationErrorResponse(c, errors)
 		return
 	}
 
-	user, err := s.UserRepository.FindUserByUsername(request.Username)
+	user, err := s.UserRepository.FindUserByUsername(c.Request.Context(), request.Username)
 	if err != nil {
-		s.Logger.Debug("couldn't find user", zap.Error(err))
-		s.badRequestResponse(c, "incorrect username or password")
+		s.logger(c).Debug("couldn't find user", zap.Error(err))
+		s.recordLoginAttempt(c, nil, false)
+		s.errorResponse(c, http.StatusBadRequest, codeInvalidCredentials, "incorrect username or password")
 		return
 	}
 
-	ok, err = argon2id.ComparePasswordAndHash(request.Password, user.Password)
+	ok, err = s.comparePassword(c.Request.Context(), user, request.Password)
 	if err != nil {
-		s.Logger.Error("couldn't check hash", zap.Error(err))
+		s.logger(c).Error("couldn't check hash", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
 	if !ok {
-		s.Logger.Debug("password is incorrect", zap.String("username", user.Username))
-		s.badRequestResponse(c, "incorrect username or password")
+		s.logger(c).Debug("password is incorrect", zap.String("username", user.Username))
+		s.recordLoginAttempt(c, &user.ID, false)
+		s.errorResponse(c, http.StatusBadRequest, codeInvalidCredentials, "incorrect username or password")
 		return
 	}
 
 	if len(user.MFASecret) == 0 {
-		s.Logger.Debug("user doesn't have 2fa enabled", zap.String("username", request.Username))
+		s.logger(c).Debug("user doesn't have 2fa enabled", zap.String("username", request.Username))
 		s.badRequestResponse(c, "this user doesn't have 2fa enabled")
 		return
 	}
 
 	secret, err := s.decryptMfaSecret(user.MFASecret)
 	if err != nil {
-		s.Logger.Error("couldn't decrypt secret", zap.Error(err))
+		s.logger(c).Error("couldn't decrypt secret", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
 	ok = totp.Validate(request.TOTP, string(secret))
 	if !ok {
-		s.Logger.Debug("invalid totp code", zap.String("totp", request.TOTP))
+		s.logger(c).Debug("invalid totp code", zap.String("totp", request.TOTP))
+		s.recordLoginAttempt(c, &user.ID, false)
 		c.Error(ErrInvalidInput{"invalid totp code"})
 		return
 	}
 
-	accessToken, err := generateAccessToken(user.ID)
+	accessToken, err := s.generateAccessToken(user.ID)
 	if err != nil {
-		s.Logger.Error("couldn't generate token", zap.Error(err))
+		s.logger(c).Error("couldn't generate token", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
-	refreshToken, err := generateRefreshToken(user.ID)
+	refreshToken, err := s.generateRefreshToken(user.ID)
 	if err != nil {
-		s.Logger.Error("couldn't generate refreshToken", zap.Error(err))
+		s.logger(c).Error("couldn't generate refreshToken", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
@@ -299,11 +307,14 @@ func (s *Server) loginUserMfaHandler(c *gin.Context) {
 		RefreshToken string `json:"refresh_token"`
 	}
 
+	s.alertNewDevice(c, user)
+	s.recordLoginAttempt(c, &user.ID, true)
+
 	c.JSON(http.StatusOK, mfaLoginResponse{accessToken, refreshToken})
 }
 
 type recoveryLoginRequest struct {
-	Username     string `json:"username"`
+	Username     string `json:"username" validate:"max=maxUsernameLength"`
 	Password     string `json:"password"`
 	RecoveryCode string `json:"recovery_code"`
 }
@@ -319,84 +330,83 @@ type recoveryLoginRequest struct {
 // @Router /users/login/recovery [post]
 func (s *Server) loginUserRecoveryHandler(c *gin.Context) {
 	var request recoveryLoginRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		s.Logger.Debug("json is invalid", zap.Error(err))
-		c.Error(ErrInvalidJSON)
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
 	request.Username = strings.TrimSpace(request.Username)
 
-	v := validator.New()
-
-	v.RequiredMax("username", request.Username, 50)
-
-	ok, errors := v.IsValid()
+	ok, errors := s.validator(c).ValidateStruct(request)
 	if !ok {
-		s.Logger.Debug("input invalid", zap.Strings("err", errors))
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors})
+		s.logger(c).Debug("input invalid", zap.Any("err", errors))
+		s.validationErrorResponse(c, errors)
 		return
 	}
 
-	user, err := s.UserRepository.FindUserByUsername(request.Username)
+	user, err := s.UserRepository.FindUserByUsername(c.Request.Context(), request.Username)
 	if err != nil {
-		s.Logger.Debug("couldn't find user", zap.Error(err))
-		s.badRequestResponse(c, "incorrect username or password")
+		s.logger(c).Debug("couldn't find user", zap.Error(err))
+		s.recordLoginAttempt(c, nil, false)
+		s.errorResponse(c, http.StatusBadRequest, codeInvalidCredentials, "incorrect username or password")
 		return
 	}
 
-	ok, err = argon2id.ComparePasswordAndHash(request.Password, user.Password)
+	ok, err = s.comparePassword(c.Request.Context(), user, request.Password)
 	if err != nil {
-		s.Logger.Error("couldn't check hash", zap.Error(err))
+		s.logger(c).Error("couldn't check hash", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
 	if !ok {
-		s.Logger.Debug("password is incorrect", zap.String("username", user.Username))
-		s.badRequestResponse(c, "incorrect username or password")
+		s.logger(c).Debug("password is incorrect", zap.String("username", user.Username))
+		s.recordLoginAttempt(c, &user.ID, false)
+		s.errorResponse(c, http.StatusBadRequest, codeInvalidCredentials, "incorrect username or password")
 		return
 	}
 
-	recoveryCodes, err := s.UserRepository.GetUserRecoveryCodes(user.Username)
+	recoveryCodes, err := s.UserRepository.GetUserRecoveryCodes(c.Request.Context(), user.Username)
 	if err != nil {
-		s.Logger.Error("couldn't get recovery codes", zap.Error(err), zap.String("username", user.Username))
-		s.internalServerErrorResponse(c)
+		s.logger(c).Debug("couldn't get recovery codes", zap.Error(err), zap.String("username", user.Username))
+		c.Error(err)
 		return
 	}
 
 	if len(recoveryCodes) == 0 {
-		s.Logger.Debug("user doesn't have any recovery codes", zap.String("username", user.Username))
-		s.badRequestResponse(c, "incorrect recovery code")
+		s.logger(c).Debug("user doesn't have any recovery codes", zap.String("username", user.Username))
+		s.errorResponse(c, http.StatusBadRequest, codeInvalidCredentials, "incorrect recovery code")
 		return
 	}
 
 	ok = s.isRecoveryCodeValid(request.RecoveryCode, recoveryCodes)
 	if !ok {
-		s.Logger.Debug("incorrect recovery code", zap.String("code", request.RecoveryCode), zap.String("username", request.Username))
-		s.badRequestResponse(c, "incorrect recovery code")
+		s.logger(c).Debug("incorrect recovery code", zap.String("code", request.RecoveryCode), zap.String("username", request.Username))
+		s.recordLoginAttempt(c, &user.ID, false)
+		s.errorResponse(c, http.StatusBadRequest, codeInvalidCredentials, "incorrect recovery code")
 		return
 	}
 
 	recoveryCodesUpdated := removeRecoveryCode(recoveryCodes, request.RecoveryCode)
 
-	err = s.UserRepository.SetRecoveryCodes(user.ID, recoveryCodesUpdated)
+	err = s.UserRepository.SetRecoveryCodes(c.Request.Context(), user.ID, recoveryCodesUpdated)
 	if err != nil {
-		s.Logger.Error("couldn't update recovery codes", zap.Error(err))
-		s.internalServerErrorResponse(c)
+		s.logger(c).Debug("couldn't update recovery codes", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
-	accessToken, err := generateAccessToken(user.ID)
+	accessToken, err := s.generateAccessToken(user.ID)
 	if err != nil {
-		s.Logger.Error("couldn't generate token", zap.Error(err))
+		s.logger(c).Error("couldn't generate token", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
-	refreshToken, err := generateRefreshToken(user.ID)
+	refreshToken, err := s.generateRefreshToken(user.ID)
 	if err != nil {
-		s.Logger.Error("couldn't generate refreshToken", zap.Error(err))
+		s.logger(c).Error("couldn't generate refreshToken", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
@@ -406,6 +416,9 @@ func (s *Server) loginUserRecoveryHandler(c *gin.Context) {
 		RefreshToken string `json:"refresh_token"`
 	}
 
+	s.alertNewDevice(c, user)
+	s.recordLoginAttempt(c, &user.ID, true)
+
 	c.JSON(http.StatusOK, recoveryLoginResponse{accessToken, refreshToken})
 }
 
@@ -427,7 +440,7 @@ func (s *Server) setupMfaHandler(c *gin.Context) {
 
 	key, err := totp.Generate(totp.GenerateOpts{Issuer: "blog-api", AccountName: user.Username})
 	if err != nil {
-		s.Logger.Error("couldn't generate secret", zap.Error(err))
+		s.logger(c).Error("couldn't generate secret", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
@@ -436,8 +449,8 @@ func (s *Server) setupMfaHandler(c *gin.Context) {
 }
 
 type confirmMfaRequest struct {
-	Secret string `json:"secret"`
-	TOTP   string `json:"totp"`
+	Secret string `json:"secret" validate:"len=totpSecretLength"`
+	TOTP   string `json:"totp" validate:"len=totpCodeLength"`
 }
 
 type confirmMfaResponse struct {
@@ -459,52 +472,57 @@ func (s *Server) confirmMfaHandler(c *gin.Context) {
 	user := s.getUserFromContext(c)
 
 	var request confirmMfaRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		s.Logger.Debug("json is invalid", zap.Error(err))
-		c.Error(ErrInvalidJSON)
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
-	v := validator.New()
-	v.RequiredExact("secret", request.Secret, totpSecretLength)
-	v.RequiredExact("totp", request.TOTP, totpCodeLength)
-
-	ok, errors := v.IsValid()
+	ok, errors := s.validator(c).ValidateStruct(request)
 	if !ok {
-		s.Logger.Debug("input invalid", zap.Strings("err", errors))
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors})
+		s.logger(c).Debug("input invalid", zap.Any("err", errors))
+		s.validationErrorResponse(c, errors)
 		return
 	}
 
 	ok = totp.Validate(request.TOTP, request.Secret)
 	if !ok {
-		s.Logger.Debug("invalid totp code", zap.String("totp", request.TOTP))
+		s.logger(c).Debug("invalid totp code", zap.String("totp", request.TOTP))
 		c.Error(ErrInvalidInput{"invalid totp code"})
 		return
 	}
 
-	encryptedSecret := s.encryptMfaSecret([]byte(request.Secret))
+	encryptedSecret, err := s.encryptMfaSecret([]byte(request.Secret))
+	if err != nil {
+		s.logger(c).Error("couldn't encrypt secret", zap.Error(err))
+		s.internalServerErrorResponse(c)
+		return
+	}
 
 	recoveryCodes := generateRecoveryCodes()
 
-	err := s.UserRepository.InsertMfaSecret(user.ID, encryptedSecret, recoveryCodes)
+	err = s.UserRepository.InsertMfaSecret(c.Request.Context(), user.ID, encryptedSecret, recoveryCodes)
 	if err != nil {
-		s.Logger.Error("couldn't insert secret", zap.Error(err))
-		s.internalServerErrorResponse(c)
+		s.logger(c).Debug("couldn't insert secret", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
+	s.sendSecurityAlert(c, user, newSecurityAlert(c, user, mailer.SecurityEventMfaEnabled))
+
 	c.JSON(http.StatusOK, confirmMfaResponse{recoveryCodes})
 }
 
 type personalPosts struct {
-	ID    int    `json:"id"`
-	Title string `json:"title"`
-	Body  string `json:"body"`
-}
-
-type getPersonalPostsResponse struct {
-	Posts []personalPosts `json:"posts"`
+	ID           string     `json:"id"`
+	Title        string     `json:"title"`
+	Body         string     `json:"body"`
+	Status       string     `json:"status" enums:"published,held"`
+	CanonicalURL string     `json:"canonical_url,omitempty"`
+	License      string     `json:"license,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Links        *postLinks `json:"links,omitempty"`
 }
 
 // @Summary Returns user's posts.
@@ -513,8 +531,13 @@ type getPersonalPostsResponse struct {
 // @Produce json
 // @Param page query int32 true "page"
 // @Param limit query int32 true "limit"
+// @Param fields query string false "comma separated fields to return for each post, e.g. id,title,created_at"
+// @Param sort query string false "created_at or updated_at, prefixed with - for descending order" Enums(created_at, -created_at, updated_at, -updated_at)
+// @Param created_after query string false "only return posts created after this RFC 3339 timestamp"
+// @Param created_before query string false "only return posts created before this RFC 3339 timestamp"
+// @Param updated_after query string false "only return posts updated after this RFC 3339 timestamp"
 // @Security ApiKeyAuth
-// @Success 200 {object} getPersonalPostsResponse
+// @Success 200 {object} listResponse[personalPosts]
 // @Failure 400 {object} errorResponse "Input is invalid"
 // @Failure 403 {object} errorResponse "The access token is invalid"
 // @Failure 404 {object} errorResponse "User has no posts"
@@ -523,15 +546,32 @@ type getPersonalPostsResponse struct {
 func (s *Server) getPersonalPostsHandler(c *gin.Context) {
 	page, limit, err := s.validatePageAndLimit(c)
 	if err != nil {
-		s.Logger.Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		s.logger(c).Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
+		return
+	}
+
+	fields, err := s.parseFields(c, personalPosts{})
+	if err != nil {
+		s.logger(c).Debug("invalid fields", zap.Error(err), zap.String("fields", c.Query("fields")))
+		c.Error(ErrInvalidInput{err.Error()})
+		return
+	}
+
+	options, err := s.parsePostListOptions(c)
+	if err != nil {
+		s.logger(c).Debug("invalid sort or filter", zap.Error(err))
 		c.Error(ErrInvalidInput{err.Error()})
 		return
 	}
 
 	user := s.getUserFromContext(c)
-	userPosts, err := s.PostRepository.FindByUserID(user.ID, page, limit)
+
+	options.IncludeHeld = true
+	options.ViewerID = user.ID
+	userPosts, err := s.PostRepository.FindByUserID(c.Request.Context(), user.ID, options, page, limit)
 	if err != nil {
-		s.Logger.Debug("couldn't find user's posts", zap.String("username", user.Username))
+		s.logger(c).Debug("couldn't find user's posts", zap.String("username", user.Username))
 		c.Error(err)
 		return
 	}
@@ -539,50 +579,66 @@ func (s *Server) getPersonalPostsHandler(c *gin.Context) {
 	var posts []personalPosts
 	for _, post := range userPosts {
 		posts = append(posts, personalPosts{
-			ID:    post.ID,
-			Title: post.Title,
-			Body:  post.Body,
+			ID:           post.PublicID,
+			Title:        post.Title,
+			Body:         post.Body,
+			Status:       post.Status,
+			CanonicalURL: post.CanonicalURL,
+			License:      post.License,
+			CreatedAt:    post.CreatedAt,
+			UpdatedAt:    post.UpdatedAt,
+			Links:        s.postLinks(c, post.PublicID, user.Username),
 		})
 	}
 
-	c.JSON(http.StatusOK, getPersonalPostsResponse{posts})
+	if fields != nil {
+		s.respond(c, http.StatusOK, newListResponse(s, c, filterList(fields, posts), page, limit))
+		return
+	}
+
+	s.respond(c, http.StatusOK, newListResponse(s, c, posts, page, limit))
 }
 
 // @Summary Returns user's posts.
 // @Tags user
 // @Accept json
 // @Produce json
-// @Param userId path int true "user id"
+// @Param userId path string true "user id"
 // @Security ApiKeyAuth
 // @Success 200 "User deleted successfully"
 // @Failure 400 {object} errorResponse "Input is invalid"
 // @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 404 {object} errorResponse "A user with the provided id doesn't exist"
 // @Failure 500 {object} errorResponse
 // @Router /users/{userId} [delete]
 func (s *Server) deleteUserHandler(c *gin.Context) {
-	user := s.getUserFromContext(c)
-
-	ok := s.enforcePermissions(c, user.Role, "user", "delete")
-	if !ok {
-		s.Logger.Debug("user has insufficient permissions", zap.String("username", user.Username), zap.String("role", user.Role))
-		c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+	publicId, err := s.publicIDParam(c, "userId")
+	if err != nil {
+		s.logger(c).Debug("userId param is invalid", zap.Error(err), zap.String("userId", c.Param("userId")))
+		s.badRequestResponse(c, err.Error())
 		return
 	}
 
-	userId, err := strconv.Atoi(c.Param("userId"))
+	user, err := s.UserRepository.FindUserByPublicID(c.Request.Context(), publicId)
 	if err != nil {
-		s.Logger.Debug("userId param not an integer", zap.Error(err), zap.String("userId", c.Param("userId")))
-		s.badRequestResponse(c, "userId must be an integer")
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("userId", publicId))
+		c.Error(err)
 		return
 	}
 
-	err = s.UserRepository.DeleteUserByID(userId)
+	userId := user.ID
+
+	err = s.UserRepository.DeleteUserByID(c.Request.Context(), userId)
 	if err != nil {
-		s.Logger.Error("couldn't delete user", zap.Error(err))
-		s.internalServerErrorResponse(c)
+		s.logger(c).Debug("couldn't delete user", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
+	actor := s.getUserFromContext(c)
+	s.recordHistory(&actor.ID, objectUser, userId, nil)
+	s.audit(c, auditActionUserDelete, objectUser, userId, newAuditUser(user), nil)
+
 	c.Status(http.StatusOK)
 }
 
@@ -604,82 +660,91 @@ type refreshTokenRequest struct {
 func (s *Server) refreshTokenHandler(c *gin.Context) {
 	authToken, err := s.validateAuthorizationHeader(c)
 	if err != nil {
-		s.Logger.Debug("authorization header validation error", zap.Error(err))
-		c.JSON(http.StatusForbidden, gin.H{"error": err})
+		s.logger(c).Debug("authorization header validation error", zap.Error(err))
+		s.errorResponse(c, http.StatusForbidden, codeInvalidToken, err.Error())
 		return
 	}
 
-	accessToken, err := parseToken(authToken)
+	accessToken, err := s.parseToken(authToken)
 	if err != nil {
-		s.Logger.Debug("invalid accessToken", zap.Error(err))
-		c.JSON(http.StatusForbidden, gin.H{"error": "invalid accessToken"})
+		s.logger(c).Debug("invalid accessToken", zap.Error(err))
+		s.errorResponse(c, http.StatusForbidden, codeInvalidToken, "invalid accessToken")
 		return
 	}
 
 	var request refreshTokenRequest
-	if err = c.ShouldBindJSON(&request); err != nil {
-		s.Logger.Debug("json is invalid", zap.Error(err))
-		c.Error(ErrInvalidJSON)
+	if err = s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
-	refreshToken, err := validateRefreshToken(request.RefreshToken)
+	refreshToken, err := s.validateRefreshToken(request.RefreshToken)
 	if err != nil {
-		s.Logger.Debug("invalid refresh token", zap.Error(err))
-		c.JSON(http.StatusForbidden, gin.H{"error": "invalid refresh token"})
+		s.logger(c).Debug("invalid refresh token", zap.Error(err))
+		s.errorResponse(c, http.StatusForbidden, codeInvalidToken, "invalid refresh token")
 		return
 	}
 
 	userId := accessToken.ID
 
 	if userId != refreshToken.ID {
-		s.Logger.Warn("refresh token used for the wrong user", zap.Int("expected", userId), zap.Int("got", refreshToken.ID))
-		c.JSON(http.StatusForbidden, gin.H{"error": "refresh token used for the wrong user"})
+		s.logger(c).Warn("refresh token used for the wrong user", zap.Int("expected", userId), zap.Int("got", refreshToken.ID))
+		s.errorResponse(c, http.StatusForbidden, codeInvalidToken, "refresh token used for the wrong user")
 		return
 	}
 
-	isTokenBlacklisted, err := s.UserRepository.IsRefreshTokenBlacklisted(userId, request.RefreshToken)
+	isTokenBlacklisted, err := s.UserRepository.IsRefreshTokenBlacklisted(c.Request.Context(), userId, request.RefreshToken)
 	if err != nil {
-		s.Logger.Error("isTokenBlacklisted error", zap.Error(err))
-		s.internalServerErrorResponse(c)
+		s.logger(c).Debug("isTokenBlacklisted error", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
 	if isTokenBlacklisted {
-		s.Logger.Warn("accessToken is blacklisted", zap.Int("userId", userId))
-		err = s.UserRepository.SetActiveState(userId, false)
+		s.logger(c).Warn("accessToken is blacklisted", zap.Int("userId", userId))
+		err = s.UserRepository.SetActiveState(c.Request.Context(), userId, false)
 		if err != nil {
-			s.Logger.Error("couldn't disable user's account", zap.Error(err))
-			s.internalServerErrorResponse(c)
+			s.logger(c).Debug("couldn't disable user's account", zap.Error(err))
+			c.Error(err)
 			return
 		}
 
+		s.recordUserHistory(nil, userId)
+
 		c.Status(http.StatusForbidden)
 		return
 	}
 
-	newAccessToken, err := generateAccessToken(userId)
+	newAccessToken, err := s.generateAccessToken(userId)
 	if err != nil {
-		s.Logger.Error("couldn't generate newAccessToken", zap.Error(err))
+		s.logger(c).Error("couldn't generate newAccessToken", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
-	newRefreshToken, err := generateRefreshToken(userId)
+	newRefreshToken, err := s.generateRefreshToken(userId)
 	if err != nil {
-		s.Logger.Error("couldn't generate newRefreshToken", zap.Error(err))
+		s.logger(c).Error("couldn't generate newRefreshToken", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
-	err = s.UserRepository.InsertRefreshToken(repository.RefreshToken{
-		UserID: userId,
-		Token:  request.RefreshToken,
+	// The blacklist entry is kept until the token expires, tokens without an expiry are kept as long as new ones are valid.
+	expiresAt := time.Now().Add(RefreshTokenExpiry * time.Hour)
+	if refreshToken.ExpiresAt != nil {
+		expiresAt = refreshToken.ExpiresAt.Time
+	}
+
+	err = s.UserRepository.InsertRefreshToken(c.Request.Context(), repository.RefreshToken{
+		UserID:    userId,
+		Token:     request.RefreshToken,
+		ExpiresAt: expiresAt,
 	})
 
 	if err != nil {
-		s.Logger.Error("couldn't insert refresh accessToken", zap.Error(err))
-		s.internalServerErrorResponse(c)
+		s.logger(c).Debug("couldn't insert refresh accessToken", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
@@ -692,7 +757,7 @@ func (s *Server) refreshTokenHandler(c *gin.Context) {
 }
 
 type createPasswordResetTokenRequest struct {
-	Email string `json:"email"`
+	Email string `json:"email" validate:"required,email"`
 }
 
 // @Summary Creates a password reset token and sends an email with password reset instructions.
@@ -702,64 +767,71 @@ type createPasswordResetTokenRequest struct {
 // @Param request body createPasswordResetTokenRequest true "Create password reset token body"
 // @Success 200 {object} messageResponse
 // @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 429 {object} errorResponse "Too many password reset emails have been sent to the address within EMAIL_RATE_LIMIT_WINDOW"
 // @Failure 500 {object} errorResponse
 // @Router /users/password-reset [post]
 func (s *Server) createPasswordResetToken(c *gin.Context) {
 	var request createPasswordResetTokenRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		s.Logger.Debug("json is invalid", zap.Error(err))
-		c.Error(ErrInvalidJSON)
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
 	request.Email = strings.TrimSpace(request.Email)
 
-	_, err := mail.ParseAddress(request.Email)
-	if err != nil {
-		s.Logger.Debug("email is invalid", zap.String("email", request.Email))
-		s.badRequestResponse(c, "email is invalid")
+	ok, validationErrors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input invalid", zap.Any("err", validationErrors))
+		s.validationErrorResponse(c, validationErrors)
 		return
 	}
 
-	user, err := s.UserRepository.FindUserByEmail(request.Email)
+	user, err := s.UserRepository.FindUserByEmail(c.Request.Context(), request.Email)
 	if err != nil {
-		s.Logger.Debug("couldn't find user", zap.Error(err), zap.String("email", request.Email))
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("email", request.Email))
 		c.Error(err)
 		return
 	}
 
-	token := randomString(PasswordResetTokenLength)
-
-	passwordResetToken := repository.PasswordResetToken{
-		UserID: user.ID,
-		Token:  token,
-		Expiry: time.Now().Add(15 * time.Minute).Unix(),
+	err = s.sendPasswordResetEmail(c.Request.Context(), user)
+	if errors.Is(err, ErrEmailRateLimited) {
+		c.Error(err)
+		return
 	}
 
-	err = s.UserRepository.InsertPasswordResetToken(passwordResetToken)
 	if err != nil {
-		s.Logger.Error("couldn't insert password reset token", zap.Error(err), zap.String("email", request.Email))
-		c.Error(err)
+		s.logger(c).Error("couldn't send password reset email", zap.Error(err), zap.String("email", request.Email))
+		s.internalServerErrorResponse(c)
 		return
 	}
 
-	data := map[string]any{
-		"passwordResetToken": token,
-		"username":           user.Username,
-	}
+	s.successResponse(c, "password reset email has been sent")
+}
 
-	go func() {
-		err = s.Mailer.Send(request.Email, "password_reset.tmpl", data)
+// sendPasswordResetEmail creates a password reset token for the user and emails it. The token is only kept if
+// the email could be queued.
+func (s *Server) sendPasswordResetEmail(ctx context.Context, user repository.User) error {
+	token := randomString(PasswordResetTokenLength)
+
+	expiry := time.Now().Add(15 * time.Minute)
+
+	return s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.UserRepository.InsertPasswordResetToken(ctx, repository.PasswordResetToken{
+			UserID: user.ID,
+			Token:  token,
+			Expiry: expiry.Unix(),
+		})
 		if err != nil {
-			s.Logger.Error("couldn't send email", zap.Error(err), zap.String("email", request.Email))
+			return fmt.Errorf("couldn't insert password reset token: %w", err)
 		}
-	}()
 
-	s.successResponse(c, "password reset email has been sent")
+		return s.enqueueEmail(ctx, user, mailer.PasswordReset{Username: user.Username, Token: token}, &expiry)
+	})
 }
 
 type resetUserPasswordRequest struct {
-	Password string `json:"password"`
+	Password string `json:"password" validate:"min=minPasswordLength"`
 }
 
 // @Summary Resets the user's password.
@@ -775,60 +847,73 @@ type resetUserPasswordRequest struct {
 // @Router /users/password-reset [put]
 func (s *Server) resetUserPasswordHandler(c *gin.Context) {
 	var request resetUserPasswordRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
-		s.Logger.Debug("json is invalid", zap.Error(err))
-		c.Error(ErrInvalidJSON)
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
 		return
 	}
 
 	token := c.Query("token")
 
-	v := validator.New()
+	v := s.validator(c)
 
 	v.RequiredExact("token", token, PasswordResetTokenLength)
-	v.RequiredMin("password", request.Password, 8)
+	v.Struct(request)
 	ok, errors := v.IsValid()
 	if !ok {
-		s.Logger.Debug("input invalid", zap.Strings("err", errors))
-		c.JSON(http.StatusBadRequest, gin.H{"error": errors})
+		s.logger(c).Debug("input invalid", zap.Any("err", errors))
+		s.validationErrorResponse(c, errors)
 		return
 	}
 
-	passwordResetToken, err := s.UserRepository.GetPasswordResetToken(token)
+	passwordResetToken, err := s.UserRepository.GetPasswordResetToken(c.Request.Context(), token)
 	if err != nil {
-		s.Logger.Debug("couldn't get password reset token", zap.Error(err), zap.String("token", token))
-		c.JSON(http.StatusForbidden, gin.H{"error": "wrong reset password token"})
+		s.logger(c).Debug("couldn't get password reset token", zap.Error(err), zap.String("token", token))
+		s.errorResponse(c, http.StatusForbidden, codeInvalidToken, "wrong reset password token")
 		return
 	}
 
 	if passwordResetToken.Expiry < time.Now().Unix() {
-		c.JSON(http.StatusForbidden, gin.H{"error": "this token has expired"})
-		err = s.UserRepository.DeletePasswordResetToken(token)
+		s.errorResponse(c, http.StatusForbidden, codeTokenExpired, "this token has expired")
+		err = s.UserRepository.DeletePasswordResetToken(c.Request.Context(), token)
 		if err != nil {
-			s.Logger.Error("couldn't delete password reset token", zap.Error(err), zap.String("token", token))
+			s.logger(c).Error("couldn't delete password reset token", zap.Error(err), zap.String("token", token))
 		}
 		return
 	}
 
-	hash, err := argon2id.CreateHash(request.Password, &argon2Params)
+	hash, err := argon2id.CreateHash(request.Password, s.argon2Params())
 	if err != nil {
-		s.Logger.Debug("couldn't hash password", zap.Error(err))
+		s.logger(c).Debug("couldn't hash password", zap.Error(err))
 		s.internalServerErrorResponse(c)
 		return
 	}
 
-	err = s.UserRepository.SetPassword(passwordResetToken.UserID, hash)
+	// the tokens are deleted together with the password change, so a used token can never work twice
+	err = s.TxManager.WithinTx(c.Request.Context(), func(ctx context.Context) error {
+		err := s.UserRepository.SetPassword(ctx, passwordResetToken.UserID, hash)
+		if err != nil {
+			return fmt.Errorf("couldn't change user's password: %w", err)
+		}
+
+		err = s.UserRepository.DeleteAllPasswordResetTokensForUser(ctx, passwordResetToken.UserID)
+		if err != nil {
+			return fmt.Errorf("couldn't delete all password reset tokens for user: %w", err)
+		}
+
+		return nil
+	})
 	if err != nil {
-		s.Logger.Error("couldn't change user's password", zap.Error(err), zap.Int("userId", passwordResetToken.UserID))
-		s.internalServerErrorResponse(c)
+		s.logger(c).Debug("couldn't reset password", zap.Error(err), zap.Int("userId", passwordResetToken.UserID))
+		c.Error(err)
 		return
 	}
 
-	err = s.UserRepository.DeleteAllPasswordResetTokensForUser(passwordResetToken.UserID)
+	user, err := s.UserRepository.FindUserByID(c.Request.Context(), passwordResetToken.UserID)
 	if err != nil {
-		s.Logger.Error("couldn't delete all password reset tokens for user", zap.Error(err), zap.Int("userId", passwordResetToken.UserID))
-		s.internalServerErrorResponse(c)
-		return
+		s.logger(c).Error("couldn't find user to alert about the password reset", zap.Error(err), zap.Int("userId", passwordResetToken.UserID))
+	} else {
+		s.sendSecurityAlert(c, user, newSecurityAlert(c, user, mailer.SecurityEventPasswordReset))
 	}
 
 	s.successResponse(c, "password has been changed successfully")
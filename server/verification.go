@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"github.com/XiovV/blog-api/pkg/mailer"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+const (
+	verificationTokenBytes     = 32
+	verificationTokenLength    = 2 * verificationTokenBytes
+	verificationTokenExpiry    = 24 * time.Hour
+	verificationResendInterval = time.Minute
+)
+
+// sendVerificationEmail creates a new verification token for the user, invalidating the previous one, and emails it.
+// The previous token stays valid if the email couldn't be queued.
+func (s *Server) sendVerificationEmail(ctx context.Context, user repository.User) error {
+	return s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		token, expiry, err := s.createVerificationToken(ctx, user)
+		if err != nil {
+			return err
+		}
+
+		return s.enqueueEmail(ctx, user, mailer.Verification{Username: user.Username, Token: token}, &expiry)
+	})
+}
+
+// createVerificationToken replaces the user's verification token with a new one and returns it with its expiry.
+func (s *Server) createVerificationToken(ctx context.Context, user repository.User) (string, time.Time, error) {
+	token, err := generateSecureToken(verificationTokenBytes)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	expiry := time.Now().Add(verificationTokenExpiry)
+
+	err = s.UserRepository.InsertVerificationToken(ctx, repository.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		Expiry:    expiry.Unix(),
+	})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return token, expiry, nil
+}
+
+// requireVerifiedEmail rejects users who haven't verified their email address when REQUIRE_VERIFIED_EMAIL
+// is enabled, it has to be used after userAuth.
+func (s *Server) requireVerifiedEmail(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	if s.Config.RequireVerifiedEmail && user.EmailVerifiedAt == nil {
+		s.logger(c).Debug("user's email isn't verified")
+		s.abortWithError(c, http.StatusForbidden, codeEmailNotVerified, "email address must be verified")
+		return
+	}
+
+	c.Next()
+}
+
+type userResponse struct {
+	ID                 string     `json:"id"`
+	Username           string     `json:"username"`
+	Email              string     `json:"email"`
+	Role               string     `json:"role"`
+	Verified           bool       `json:"verified"`
+	MfaEnabled         bool       `json:"mfa_enabled"`
+	EmailVerified      bool       `json:"email_verified"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at"`
+	VerificationSentAt *time.Time `json:"verification_sent_at"`
+	// Locale is the language the user is emailed in.
+	Locale    string    `json:"locale"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// @Summary Returns the authenticated user's account.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param fields query string false "comma separated fields to return, e.g. id,username"
+// @Security ApiKeyAuth
+// @Success 200 {object} userResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /users/me [get]
+func (s *Server) getCurrentUserHandler(c *gin.Context) {
+	fields, err := s.parseFields(c, userResponse{})
+	if err != nil {
+		s.logger(c).Debug("invalid fields", zap.Error(err), zap.String("fields", c.Query("fields")))
+		c.Error(ErrInvalidInput{err.Error()})
+		return
+	}
+
+	user := s.getUserFromContext(c)
+
+	c.JSON(http.StatusOK, fields.filter(userResponse{
+		ID:                 user.PublicID,
+		Username:           user.Username,
+		Email:              user.Email,
+		Role:               user.Role,
+		Verified:           user.Verified,
+		MfaEnabled:         user.MFASecret != nil,
+		EmailVerified:      user.EmailVerifiedAt != nil,
+		EmailVerifiedAt:    user.EmailVerifiedAt,
+		VerificationSentAt: user.VerificationSentAt,
+		Locale:             user.Locale,
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
+	}))
+}
+
+type verifyEmailRequest struct {
+	Token string `json:"token" validate:"len=verificationTokenLength"`
+}
+
+// @Summary Verifies the user's email address with the token from the verification email.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Param request body verifyEmailRequest true "Verify email body"
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The verification token is invalid or has expired"
+// @Failure 500 {object} errorResponse
+// @Router /users/verify-email [post]
+func (s *Server) verifyEmailHandler(c *gin.Context) {
+	var request verifyEmailRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	ok, errors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	token, err := s.UserRepository.GetVerificationToken(c.Request.Context(), hashToken(request.Token))
+	if err != nil {
+		s.logger(c).Debug("couldn't get verification token", zap.Error(err))
+		s.errorResponse(c, http.StatusForbidden, codeInvalidToken, "invalid verification token")
+		return
+	}
+
+	if token.Expiry < time.Now().Unix() {
+		s.logger(c).Debug("verification token has expired", zap.Int("userId", token.UserID))
+		s.errorResponse(c, http.StatusForbidden, codeTokenExpired, "this token has expired")
+		return
+	}
+
+	_, err = s.UserRepository.MarkEmailVerified(c.Request.Context(), token.UserID)
+	if err != nil {
+		s.logger(c).Debug("couldn't mark email as verified", zap.Error(err), zap.Int("userId", token.UserID))
+		c.Error(err)
+		return
+	}
+
+	s.recordUserHistory(&token.UserID, token.UserID)
+
+	s.successResponse(c, "email address has been verified")
+}
+
+// @Summary Sends a new verification email to the authenticated user.
+// @Tags user
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} errorResponse "The email address is already verified"
+// @Failure 403 {object} errorResponse "The access token is invalid"
+// @Failure 429 {object} errorResponse "A verification email has been sent too recently, or too many within EMAIL_RATE_LIMIT_WINDOW"
+// @Failure 500 {object} errorResponse
+// @Router /users/verify-email/resend [post]
+func (s *Server) resendVerificationEmailHandler(c *gin.Context) {
+	user := s.getUserFromContext(c)
+
+	if user.EmailVerifiedAt != nil {
+		s.badRequestResponse(c, "email address is already verified")
+		return
+	}
+
+	if user.VerificationSentAt != nil && time.Since(*user.VerificationSentAt) < verificationResendInterval {
+		s.errorResponse(c, http.StatusTooManyRequests, codeRateLimited, "a verification email has been sent recently, please wait before requesting another one")
+		return
+	}
+
+	err := s.sendVerificationEmail(c.Request.Context(), user)
+	if errors.Is(err, ErrEmailRateLimited) {
+		c.Error(err)
+		return
+	}
+
+	if err != nil {
+		s.logger(c).Error("couldn't send verification email", zap.Error(err))
+		s.internalServerErrorResponse(c)
+		return
+	}
+
+	s.successResponse(c, "verification email has been sent")
+}
+
+// @Summary Marks a user's email address as verified without them following the verification link.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param userId path string true "user id"
+// @Security ApiKeyAuth
+// @Success 200 {object} messageResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "A user with the provided id doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /admin/users/{userId}/verify [put]
+func (s *Server) adminVerifyEmailHandler(c *gin.Context) {
+	publicId, err := s.publicIDParam(c, "userId")
+	if err != nil {
+		s.logger(c).Debug("userId param is invalid", zap.Error(err), zap.String("userId", c.Param("userId")))
+		s.badRequestResponse(c, err.Error())
+		return
+	}
+
+	user, err := s.UserRepository.FindUserByPublicID(c.Request.Context(), publicId)
+	if err != nil {
+		s.logger(c).Debug("couldn't find user", zap.Error(err), zap.String("userId", publicId))
+		c.Error(err)
+		return
+	}
+
+	verifiedAt, err := s.UserRepository.MarkEmailVerified(c.Request.Context(), user.ID)
+	if err != nil {
+		s.logger(c).Debug("couldn't mark email as verified", zap.Error(err), zap.Int("userId", user.ID))
+		c.Error(err)
+		return
+	}
+
+	actor := s.getUserFromContext(c)
+	s.recordUserHistory(&actor.ID, user.ID)
+	s.audit(c, auditActionUserVerify, objectUser, user.ID, gin.H{"email_verified_at": user.EmailVerifiedAt}, gin.H{"email_verified_at": verifiedAt})
+
+	s.successResponse(c, "email address has been verified")
+}
@@ -0,0 +1,437 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	webhookEventPostPublished  = "post.published"
+	webhookEventUserRegistered = "user.registered"
+
+	jobTypeWebhookDispatch = "webhook.dispatch"
+	jobTypeWebhookDeliver  = "webhook.deliver"
+
+	webhookSecretBytes = 32
+	maxWebhookURL      = 2048
+)
+
+var webhookEvents = map[string]bool{
+	webhookEventPostPublished:  true,
+	webhookEventUserRegistered: true,
+}
+
+var webhookDeliveryStatuses = []string{repository.WebhookDeliveryPending, repository.WebhookDeliverySucceeded, repository.WebhookDeliveryFailed}
+
+// webhookClient has no timeout of its own, every request gets one through its context.
+var webhookClient = &http.Client{}
+
+// webhookEvent is the body sent to webhook endpoints. The id is the same for every delivery
+// of the event, so receivers can use it to discard duplicates.
+type webhookEvent struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+type webhookDeliveryJob struct {
+	DeliveryID int `json:"delivery_id"`
+}
+
+type webhookUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+}
+
+type webhookPost struct {
+	ID     string `json:"id"`
+	UserID string `json:"user_id"`
+	Title  string `json:"title"`
+	Body   string `json:"body"`
+}
+
+// publishWebhookEvent enqueues the event, it's fanned out to the subscribed webhooks by the queue
+// so the request doesn't wait on it. Failures are only logged, they must not fail the request.
+func (s *Server) publishWebhookEvent(ctx context.Context, logger *zap.Logger, event string, data any) {
+	id, err := generateSecureToken(16)
+	if err != nil {
+		logger.Error("couldn't generate webhook event id", zap.Error(err), zap.String("event", event))
+		return
+	}
+
+	_, err = s.Queue.Enqueue(ctx, jobTypeWebhookDispatch, webhookEvent{ID: id, Event: event, CreatedAt: time.Now().UTC(), Data: data})
+	if err != nil {
+		logger.Error("couldn't enqueue webhook event", zap.Error(err), zap.String("event", event))
+	}
+}
+
+// dispatchWebhookJob creates a delivery for every webhook subscribed to the event.
+func (s *Server) dispatchWebhookJob(ctx context.Context, payload json.RawMessage) error {
+	var event webhookEvent
+	err := json.Unmarshal(payload, &event)
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := s.WebhookRepository.FindWebhooksForEvent(ctx, event.Event)
+	if err != nil {
+		return err
+	}
+
+	// the deliveries are created together, so a retry of a partially failed dispatch doesn't deliver twice
+	return s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		for _, webhook := range webhooks {
+			delivery, err := s.WebhookRepository.InsertDelivery(ctx, repository.WebhookDelivery{
+				WebhookID: webhook.ID,
+				Event:     event.Event,
+				Payload:   payload,
+			})
+			if err != nil {
+				return err
+			}
+
+			_, err = s.Queue.Enqueue(ctx, jobTypeWebhookDeliver, webhookDeliveryJob{DeliveryID: delivery.ID})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// deliverWebhookJob sends a single delivery, returning an error makes the queue retry it with backoff.
+func (s *Server) deliverWebhookJob(ctx context.Context, payload json.RawMessage) error {
+	var job webhookDeliveryJob
+	err := json.Unmarshal(payload, &job)
+	if err != nil {
+		return err
+	}
+
+	delivery, err := s.WebhookRepository.FindDeliveryByID(ctx, job.DeliveryID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookDeliveryNotFound) {
+			// the webhook has been deleted along with its deliveries
+			return nil
+		}
+
+		return err
+	}
+
+	webhook, err := s.WebhookRepository.FindWebhookByID(ctx, delivery.WebhookID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWebhookNotFound) {
+			return nil
+		}
+
+		return err
+	}
+
+	responseStatus, err := s.sendWebhook(ctx, webhook, delivery)
+	if err != nil {
+		recordErr := s.WebhookRepository.RecordDeliveryAttempt(ctx, delivery.ID, repository.WebhookDeliveryFailed, responseStatus, err.Error())
+		if recordErr != nil {
+			s.Logger.Error("couldn't record webhook delivery attempt", zap.Error(recordErr), zap.Int("deliveryId", delivery.ID))
+		}
+
+		return err
+	}
+
+	return s.WebhookRepository.RecordDeliveryAttempt(ctx, delivery.ID, repository.WebhookDeliverySucceeded, responseStatus, "")
+}
+
+func (s *Server) sendWebhook(ctx context.Context, webhook repository.Webhook, delivery repository.WebhookDelivery) (*int, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.Config.WebhookTimeout)
+	defer cancel()
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "blog-api-webhooks")
+	req.Header.Set("X-Webhook-Event", delivery.Event)
+	req.Header.Set("X-Webhook-Delivery", strconv.Itoa(delivery.ID))
+	req.Header.Set("X-Webhook-Timestamp", timestamp)
+	req.Header.Set("X-Webhook-Signature", "sha256="+signWebhook(webhook.Secret, timestamp, delivery.Payload))
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+
+	return &resp.StatusCode, nil
+}
+
+// signWebhook signs the timestamp and the body, joined with a dot, so a captured request
+// can't be replayed later with a different timestamp.
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type createWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,max=maxWebhookURL,url"`
+	Events []string `json:"events"`
+}
+
+type webhookResponse struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type createWebhookResponse struct {
+	webhookResponse
+	// the secret is only returned once, when the webhook is created
+	Secret string `json:"secret"`
+}
+
+func newWebhookResponse(webhook repository.Webhook) webhookResponse {
+	return webhookResponse{ID: webhook.ID, URL: webhook.URL, Events: webhook.Events, CreatedAt: webhook.CreatedAt}
+}
+
+// @Summary Registers a webhook endpoint which gets notified about the events it subscribes to.
+// @Description Supported events are post.published and user.registered. Every request carries an X-Webhook-Signature
+// @Description header with the hex encoded HMAC-SHA256 of X-Webhook-Timestamp, a dot and the body, keyed with the webhook's secret.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body createWebhookRequest true "Create webhook body"
+// @Security ApiKeyAuth
+// @Success 201 {object} createWebhookResponse
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/webhooks [post]
+func (s *Server) createWebhookHandler(c *gin.Context) {
+	var request createWebhookRequest
+	if err := s.bindJSON(c, &request); err != nil {
+		s.logger(c).Debug("json is invalid", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	request.URL = strings.TrimSpace(request.URL)
+
+	ok, errors := s.validator(c).ValidateStruct(request)
+	if !ok {
+		s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+		s.validationErrorResponse(c, errors)
+		return
+	}
+
+	if len(request.Events) == 0 {
+		c.Error(ErrInvalidInput{"at least one event is required"})
+		return
+	}
+
+	for _, event := range request.Events {
+		if !webhookEvents[event] {
+			c.Error(ErrInvalidInput{fmt.Sprintf("unknown event %q", event)})
+			return
+		}
+	}
+
+	secret, err := generateSecureToken(webhookSecretBytes)
+	if err != nil {
+		s.logger(c).Error("couldn't generate webhook secret", zap.Error(err))
+		s.internalServerErrorResponse(c)
+		return
+	}
+
+	webhook, err := s.WebhookRepository.InsertWebhook(c.Request.Context(), repository.Webhook{
+		URL:    request.URL,
+		Secret: secret,
+		Events: request.Events,
+	})
+	if err != nil {
+		s.logger(c).Debug("couldn't insert webhook", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	s.audit(c, auditActionWebhookCreate, objectWebhook, webhook.ID, nil, newWebhookResponse(webhook))
+
+	c.JSON(http.StatusCreated, createWebhookResponse{newWebhookResponse(webhook), secret})
+}
+
+// @Summary Returns the registered webhooks.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[webhookResponse]
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 500 {object} errorResponse
+// @Router /admin/webhooks [get]
+func (s *Server) getWebhooksHandler(c *gin.Context) {
+	webhooks, err := s.WebhookRepository.FindWebhooks(c.Request.Context())
+	if err != nil {
+		s.logger(c).Debug("couldn't find webhooks", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	data := []webhookResponse{}
+	for _, webhook := range webhooks {
+		data = append(data, newWebhookResponse(webhook))
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, c, data, 0, 0))
+}
+
+// @Summary Deletes a webhook along with its delivery log.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param webhookId path int true "webhook id"
+// @Security ApiKeyAuth
+// @Success 200 "Webhook deleted successfully"
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "A webhook with the provided id doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /admin/webhooks/{webhookId} [delete]
+func (s *Server) deleteWebhookHandler(c *gin.Context) {
+	webhookId, err := strconv.Atoi(c.Param("webhookId"))
+	if err != nil {
+		s.logger(c).Debug("webhook id not an integer", zap.String("webhookId", c.Param("webhookId")))
+		s.badRequestResponse(c, "webhook id must be an integer")
+		return
+	}
+
+	webhook, err := s.WebhookRepository.FindWebhookByID(c.Request.Context(), webhookId)
+	if err != nil {
+		s.logger(c).Debug("couldn't find webhook", zap.Error(err), zap.Int("webhookId", webhookId))
+		c.Error(err)
+		return
+	}
+
+	err = s.WebhookRepository.DeleteWebhook(c.Request.Context(), webhookId)
+	if err != nil {
+		s.logger(c).Debug("couldn't delete webhook", zap.Error(err), zap.Int("webhookId", webhookId))
+		c.Error(err)
+		return
+	}
+
+	s.audit(c, auditActionWebhookDelete, objectWebhook, webhook.ID, newWebhookResponse(webhook), nil)
+
+	c.Status(http.StatusOK)
+}
+
+type webhookDeliveryResponse struct {
+	ID             int             `json:"id"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus *int            `json:"response_status"`
+	LastError      string          `json:"last_error"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
+
+// @Summary Returns the delivery log of a webhook, newest first.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param webhookId path int true "webhook id"
+// @Param page query int32 true "page"
+// @Param limit query int32 true "limit"
+// @Param status query string false "only return deliveries in this state: pending, succeeded or failed"
+// @Security ApiKeyAuth
+// @Success 200 {object} listResponse[webhookDeliveryResponse]
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The access token is invalid or the permissions for performing this action are insufficient"
+// @Failure 404 {object} errorResponse "A webhook with the provided id doesn't exist"
+// @Failure 500 {object} errorResponse
+// @Router /admin/webhooks/{webhookId}/deliveries [get]
+func (s *Server) getWebhookDeliveriesHandler(c *gin.Context) {
+	webhookId, err := strconv.Atoi(c.Param("webhookId"))
+	if err != nil {
+		s.logger(c).Debug("webhook id not an integer", zap.String("webhookId", c.Param("webhookId")))
+		s.badRequestResponse(c, "webhook id must be an integer")
+		return
+	}
+
+	page, limit, err := s.validatePageAndLimit(c)
+	if err != nil {
+		s.logger(c).Debug("invalid page and limit", zap.Error(err), zap.String("page", c.Query("page")), zap.String("limit", c.Query("limit")))
+		c.Error(err)
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" {
+		v := s.validator(c)
+		v.OneOf("status", status, webhookDeliveryStatuses...)
+
+		ok, errors := v.IsValid()
+		if !ok {
+			s.logger(c).Debug("input is invalid", zap.Any("error", errors))
+			s.validationErrorResponse(c, errors)
+			return
+		}
+	}
+
+	_, err = s.WebhookRepository.FindWebhookByID(c.Request.Context(), webhookId)
+	if err != nil {
+		s.logger(c).Debug("couldn't find webhook", zap.Error(err), zap.Int("webhookId", webhookId))
+		c.Error(err)
+		return
+	}
+
+	deliveries, err := s.WebhookRepository.FindDeliveries(c.Request.Context(), webhookId, status, page, limit)
+	if err != nil {
+		s.logger(c).Debug("couldn't find webhook deliveries", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	data := []webhookDeliveryResponse{}
+	for _, delivery := range deliveries {
+		data = append(data, webhookDeliveryResponse{
+			ID:             delivery.ID,
+			Event:          delivery.Event,
+			Payload:        delivery.Payload,
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			ResponseStatus: delivery.ResponseStatus,
+			LastError:      delivery.LastError,
+			CreatedAt:      delivery.CreatedAt,
+			DeliveredAt:    delivery.DeliveredAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, newListResponse(s, c, data, page, limit))
+}
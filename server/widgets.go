@@ -0,0 +1,202 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"github.com/XiovV/blog-api/pkg/repository"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"go.uber.org/zap"
+	"html/template"
+	"net/http"
+	"strconv"
+)
+
+const (
+	defaultWidgetLimit = 5
+	maxWidgetLimit     = 20
+	widgetExcerptRunes = 280
+)
+
+var widgetTemplates = template.Must(template.New("widgets").Parse(`
+{{define "post"}}<article class="blogapi-post">
+  <h3 class="blogapi-post-title">{{.Title}}</h3>
+  <p class="blogapi-post-excerpt">{{.Excerpt}}</p>
+</article>{{end}}
+{{define "latestPosts"}}<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>Latest posts</title></head>
+<body><section class="blogapi-latest-posts">{{range .Posts}}{{template "post" .}}{{else}}<p class="blogapi-empty">No posts yet.</p>{{end}}</section></body></html>{{end}}
+{{define "postCard"}}<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>{{template "post" .}}</body></html>{{end}}
+`))
+
+type widgetPost struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Excerpt string `json:"excerpt"`
+}
+
+type latestPostsWidgetResponse struct {
+	Posts []widgetPost `json:"posts"`
+}
+
+func newWidgetPost(post repository.Post) widgetPost {
+	excerpt := []rune(post.Body)
+	if len(excerpt) > widgetExcerptRunes {
+		excerpt = append(excerpt[:widgetExcerptRunes], '…')
+	}
+
+	return widgetPost{ID: post.PublicID, Title: post.Title, Excerpt: string(excerpt)}
+}
+
+// @Summary Returns the newest posts of the public token's owner as an embeddable widget.
+// @Description The widget is rendered as HTML if the client accepts text/html or format=html is passed, and as JSON otherwise.
+// @Tags widgets
+// @Produce json,html
+// @Param token query string true "public token"
+// @Param limit query int32 false "amount of posts, 5 by default"
+// @Param format query string false "json or html"
+// @Success 200 {object} latestPostsWidgetResponse
+// @Success 304 "The widget hasn't changed since it was last fetched"
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The public token is invalid"
+// @Failure 500 {object} errorResponse
+// @Router /widgets/latest-posts [get]
+func (s *Server) latestPostsWidgetHandler(c *gin.Context) {
+	limit := defaultWidgetLimit
+	if c.Query("limit") != "" {
+		var err error
+		limit, err = strconv.Atoi(c.Query("limit"))
+		if err != nil || limit < MinLimitValue || limit > maxWidgetLimit {
+			s.logger(c).Debug("invalid widget limit", zap.String("limit", c.Query("limit")))
+			c.Error(ErrInvalidInput{fmt.Sprintf("limit must be an integer between %d and %d", MinLimitValue, maxWidgetLimit)})
+			return
+		}
+	}
+
+	posts, err := s.PostRepository.FindLatestByUserID(c.Request.Context(), c.GetInt(apiTokenOwnerIdKey), MinPageValue, limit)
+	if err != nil {
+		s.logger(c).Debug("couldn't find posts", zap.Error(err))
+		c.Error(err)
+		return
+	}
+
+	response := latestPostsWidgetResponse{Posts: []widgetPost{}}
+	for _, post := range posts {
+		response.Posts = append(response.Posts, newWidgetPost(post))
+	}
+
+	s.renderWidget(c, "latestPosts", response)
+}
+
+// @Summary Returns a single post of the public token's owner as an embeddable card.
+// @Description The widget is rendered as HTML if the client accepts text/html or format=html is passed, and as JSON otherwise.
+// @Tags widgets
+// @Produce json,html
+// @Param postId path string true "post id"
+// @Param token query string true "public token"
+// @Param format query string false "json or html"
+// @Success 200 {object} widgetPost
+// @Success 304 "The widget hasn't changed since it was last fetched"
+// @Failure 400 {object} errorResponse "Input is invalid"
+// @Failure 403 {object} errorResponse "The public token is invalid"
+// @Failure 404 {object} errorResponse "The token's owner doesn't have a post with the provided id"
+// @Failure 500 {object} errorResponse
+// @Router /widgets/posts/{postId} [get]
+func (s *Server) postCardWidgetHandler(c *gin.Context) {
+	postId, err := s.publicIDParam(c, "postId")
+	if err != nil {
+		s.logger(c).Debug("post id is invalid", zap.String("postId", c.Param("postId")))
+		s.badRequestResponse(c, err.Error())
+		return
+	}
+
+	post, err := s.PostRepository.FindPostByPublicID(c.Request.Context(), postId)
+	if err != nil {
+		s.logger(c).Debug("post could not be found", zap.Error(err), zap.String("postId", postId))
+		c.Error(err)
+		return
+	}
+
+	// posts published in organizations are only visible to their members
+	if post.UserID != c.GetInt(apiTokenOwnerIdKey) || post.OrganizationID != nil || post.Status != repository.PostStatusPublished {
+		s.logger(c).Debug("post doesn't belong to the token's owner", zap.String("postId", postId))
+		c.Error(repository.ErrPostNotFound)
+		return
+	}
+
+	hidden, err := s.isPostHidden(c.Request.Context(), post, 0)
+	if err != nil {
+		s.logger(c).Debug("couldn't check the post's visibility", zap.Error(err), zap.String("postId", postId))
+		c.Error(err)
+		return
+	}
+
+	if hidden {
+		s.logger(c).Debug("the token's owner is shadowbanned", zap.String("postId", postId))
+		c.Error(repository.ErrPostNotFound)
+		return
+	}
+
+	s.renderWidget(c, "postCard", newWidgetPost(post))
+}
+
+// renderWidget writes the widget as HTML or JSON with caching headers, answering
+// with 304 Not Modified if the client already has the current version.
+func (s *Server) renderWidget(c *gin.Context, templateName string, data any) {
+	format := c.Query("format")
+	if format == "" {
+		format = c.NegotiateFormat(binding.MIMEJSON, binding.MIMEHTML)
+	}
+
+	var body bytes.Buffer
+	var contentType string
+
+	switch format {
+	case "html", binding.MIMEHTML:
+		contentType = "text/html; charset=utf-8"
+		err := widgetTemplates.ExecuteTemplate(&body, templateName, data)
+		if err != nil {
+			s.logger(c).Error("couldn't render widget", zap.Error(err))
+			s.internalServerErrorResponse(c)
+			return
+		}
+	default:
+		contentType = "application/json; charset=utf-8"
+		err := json.NewEncoder(&body).Encode(data)
+		if err != nil {
+			s.logger(c).Error("couldn't encode widget", zap.Error(err))
+			s.internalServerErrorResponse(c)
+			return
+		}
+	}
+
+	hash := sha256.Sum256(body.Bytes())
+	etag := `"` + hex.EncodeToString(hash[:16]) + `"`
+
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(s.Config.WidgetCacheMaxAge.Seconds())))
+	c.Header("Vary", "Accept, Accept-Encoding")
+	c.Header("Cross-Origin-Resource-Policy", "cross-origin")
+
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, contentType, body.Bytes())
+}
+
+// widgetCORS allows widgets to be fetched from any site. Widgets are authenticated with
+// public tokens only, so credentials are never allowed.
+func (s *Server) widgetCORS(c *gin.Context) {
+	c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+	c.Writer.Header().Del("Access-Control-Allow-Credentials")
+	c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+
+	c.Next()
+}